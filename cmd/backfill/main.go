@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/user/fish-music/internal/config"
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// batchSize 每批处理的记录数
+const batchSize = 200
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	task := flag.String("task", "", "回填任务: search_keys, artists")
+	flag.Parse()
+
+	// 加载配置
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	// 初始化数据库
+	if err := database.Init(&cfg.Database, logger.Warn); err != nil {
+		log.Fatalf("初始化数据库失败: %v", err)
+	}
+	defer database.Close()
+
+	switch *task {
+	case "search_keys":
+		err = backfillSearchKeys()
+	case "artists":
+		err = backfillArtists()
+	default:
+		log.Fatalf("未知任务: %q（可选: search_keys, artists）", *task)
+	}
+
+	if err != nil {
+		log.Fatalf("回填失败: %v", err)
+	}
+}
+
+// backfillSearchKeys 为现有歌曲生成拼音 / 罗马字搜索键
+func backfillSearchKeys() error {
+	var songs []*model.Song
+	total := 0
+
+	result := database.DB.Model(&model.Song{}).FindInBatches(&songs, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, song := range songs {
+			song.UpdateSearchKeys()
+			if err := database.DB.Model(&model.Song{}).
+				Where("id = ?", song.ID).
+				UpdateColumns(map[string]interface{}{
+					"search_romanized": song.SearchRomanized,
+					"search_initials":  song.SearchInitials,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		total += len(songs)
+		log.Printf("已处理 %d 首歌曲", total)
+		return nil
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	log.Printf("搜索键回填完成，共 %d 首歌曲", total)
+	return nil
+}
+
+// backfillArtists 解析现有歌曲的歌手和专辑字段，生成歌手、专辑和署名记录
+func backfillArtists() error {
+	songRepo := database.NewSongRepository()
+	var songs []*model.Song
+	total := 0
+
+	result := database.DB.Model(&model.Song{}).Order("id").FindInBatches(&songs, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, song := range songs {
+			if err := songRepo.SyncCredits(song); err != nil {
+				return err
+			}
+		}
+		total += len(songs)
+		log.Printf("已处理 %d 首歌曲", total)
+		return nil
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	log.Printf("歌手回填完成，共 %d 首歌曲", total)
+	return nil
+}
@@ -3,14 +3,19 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/user/fish-music/internal/config"
 	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/dispatcher"
 	"github.com/user/fish-music/internal/handler"
+	"github.com/user/fish-music/internal/locales"
 	"github.com/user/fish-music/internal/service"
+	"github.com/user/fish-music/internal/webhook"
 	"github.com/user/fish-music/pkg/api"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"gorm.io/gorm/logger"
@@ -68,6 +73,21 @@ func main() {
 	userRepo := database.NewUserRepository()
 	favoriteRepo := database.NewFavoriteRepository()
 	historyRepo := database.NewHistoryRepository()
+	groupRepo := database.NewGroupSettingRepository()
+	playlistRepo := database.NewPlaylistRepository()
+	shareRepo := database.NewShareRepository()
+	queueRepo := database.NewQueueRepository()
+	artistRepo := database.NewArtistRepository()
+	settingsRepo := database.NewUserSettingRepository()
+
+	// 加载多语言消息目录
+	bundle, err := locales.Load()
+	if err != nil {
+		log.Fatalf("加载语言文件失败: %v", err)
+	}
+
+	// 初始化通知服务
+	notifyService := service.NewNotifyService(bot, settingsRepo, bundle)
 
 	// 初始化音乐 API 客户端
 	musicAPI := api.NewNeteaseAPI(cfg.Search.APIURL)
@@ -76,28 +96,67 @@ func main() {
 	ytdlpService := service.NewYTDLPService(
 		bot,
 		songRepo,
+		notifyService,
+		settingsRepo,
+		bundle,
 		cfg.Download.TempDir,
 		cfg.Download.MaxFileSize,
 		cfg.Download.CookiesFile,
 	)
 
+	// 初始化推荐服务
+	recommendService := service.NewRecommendService(songRepo, database.NewRecommendRepository())
+
+	// 初始化歌词服务
+	lyricsService := service.NewLyricsService(database.NewLyricRepository(), musicAPI)
+
+	// 初始化排行榜服务，定时刷新排行榜物化视图
+	chartService := service.NewChartService(
+		database.NewChartRepository(),
+		time.Duration(cfg.Charts.RefreshInterval)*time.Minute,
+	)
+
+	// 初始化公告服务，后台逐个发送已确认的公告
+	broadcastService := service.NewBroadcastService(bot, database.NewBroadcastRepository(), userRepo, bundle)
+
+	// 结构化日志，记录每条更新的处理结果（debug 级别记录所有更新，否则只记录失败）
+	var slogLevel slog.Level
+	if err := slogLevel.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+		slogLevel = slog.LevelInfo
+	}
+	updateLogger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slogLevel}))
+
 	botHandler := handler.NewBotHandler(
 		bot,
-		cfg.Bot.AdminID,
+		&cfg.Bot,
 		songRepo,
 		userRepo,
 		favoriteRepo,
 		historyRepo,
+		groupRepo,
+		playlistRepo,
+		shareRepo,
+		queueRepo,
+		artistRepo,
+		settingsRepo,
+		database.NewInviteRepository(),
 		musicAPI,
 		ytdlpService,
+		recommendService,
+		lyricsService,
+		service.NewStatsService(database.NewStatsRepository()),
+		chartService,
+		broadcastService,
+		bundle,
 		&cfg.Download,
+		&cfg.Group,
+		updateLogger,
 	)
 
-	// 设置更新配置
-	updateCfg := tgbotapi.NewUpdate(0)
-	updateCfg.Timeout = 60
-
-	updates := bot.GetUpdatesChan(updateCfg)
+	// 注册 Telegram 命令菜单，失败不影响 Bot 运行
+	if err := botHandler.RegisterCommands(); err != nil {
+		log.Printf("注册命令菜单失败: %v", err)
+	}
 
 	// 处理信号
 	ctx, cancel := context.WithCancel(context.Background())
@@ -106,38 +165,111 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	go func() {
+	go chartService.Run(ctx)
+	go broadcastService.Run(ctx)
+
+	// 并发处理更新，同一聊天内保持顺序
+	updateDispatcher := dispatcher.New(botHandler.HandleUpdate, cfg.Bot.Workers, cfg.Bot.QueueSize)
+	go logDispatcherStats(ctx, updateDispatcher, updateLogger)
+
+	if cfg.Bot.Mode == config.BotModeWebhook {
+		webhookServer := webhook.NewServer(bot, &cfg.Bot.Webhook, updateDispatcher.Submit)
+		if err := webhookServer.Start(); err != nil {
+			log.Fatalf("启动 webhook 失败: %v", err)
+		}
+
 		<-sigChan
 		log.Println("收到停止信号，正在关闭...")
-		bot.StopReceivingUpdates()
 		cancel()
-	}()
 
-	// 主循环
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case update, ok := <-updates:
-			if !ok {
-				return
-			}
+		// 先停止接收，再等待已收到的更新处理完成
+		stopCtx, cancelStop := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelStop()
+		if err := webhookServer.Stop(stopCtx); err != nil {
+			log.Printf("关闭 webhook 服务失败: %v", err)
+		}
+	} else {
+		// 之前以 webhook 模式运行过时需要先删除 webhook，否则 getUpdates 会被拒绝
+		if _, err := bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			log.Printf("删除 webhook 失败: %v", err)
+		}
+
+		// 设置更新配置
+		updateCfg := tgbotapi.NewUpdate(0)
+		updateCfg.Timeout = 60
 
-			// 处理私聊消息
-			if update.Message != nil {
-				if update.Message.Chat.Type == "private" {
-					if err := botHandler.HandlePrivateMessage(update); err != nil {
-						log.Printf("处理消息失败: %v", err)
-					}
+		updates := bot.GetUpdatesChan(updateCfg)
+
+		go func() {
+			<-sigChan
+			log.Println("收到停止信号，正在关闭...")
+			bot.StopReceivingUpdates()
+			cancel()
+		}()
+
+		// 主循环
+	receive:
+		for {
+			select {
+			case <-ctx.Done():
+				break receive
+			case update, ok := <-updates:
+				if !ok {
+					break receive
 				}
+
+				updateDispatcher.Submit(update)
 			}
+		}
+	}
 
-			// 处理回调查询
-			if update.CallbackQuery != nil {
-				if err := botHandler.HandleCallback(update.CallbackQuery); err != nil {
-					log.Printf("处理回调失败: %v", err)
-				}
+	// 等待已收到的更新处理完成
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	if err := updateDispatcher.Shutdown(shutdownCtx); err != nil {
+		log.Printf("等待更新处理完成超时: %v", err)
+	}
+	logRouteMetrics(botHandler)
+}
+
+// shutdownTimeout 关闭时等待正在处理的更新的最长时间
+const shutdownTimeout = 30 * time.Second
+
+// dispatcherStatsInterval 输出更新队列状态的间隔
+const dispatcherStatsInterval = time.Minute
+
+// logDispatcherStats 定时输出更新队列状态，排队超过一半容量时以警告级别输出
+func logDispatcherStats(ctx context.Context, d *dispatcher.Dispatcher, logger *slog.Logger) {
+	ticker := time.NewTicker(dispatcherStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := d.Stats()
+			level := slog.LevelDebug
+			if stats.Pending*2 >= stats.Capacity {
+				level = slog.LevelWarn
 			}
+			logger.Log(ctx, level, "更新队列",
+				slog.Int("pending", stats.Pending),
+				slog.Int("active", stats.Active),
+				slog.Int("chats", stats.Chats),
+				slog.Int("max_chat_depth", stats.MaxChatDepth),
+				slog.Int64("processed", stats.Processed),
+				slog.Int("workers", stats.Workers),
+				slog.Int("capacity", stats.Capacity),
+			)
 		}
 	}
 }
+
+// logRouteMetrics 退出前输出各路由的处理统计
+func logRouteMetrics(botHandler *handler.BotHandler) {
+	for _, stats := range botHandler.Metrics().Snapshot() {
+		log.Printf("路由 %s: %d 次，失败 %d 次，平均 %v，最长 %v",
+			stats.Route, stats.Count, stats.Errors, stats.Average(), stats.Max)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/user/fish-music/internal/config"
 	"github.com/user/fish-music/internal/database"
 	"github.com/user/fish-music/internal/handler"
+	"github.com/user/fish-music/internal/locales"
 	"gorm.io/gorm/logger"
 )
 
@@ -52,12 +53,22 @@ func main() {
 	}
 	defer database.Close()
 
+	// 加载多语言消息目录
+	bundle, err := locales.Load()
+	if err != nil {
+		log.Fatalf("加载语言文件失败: %v", err)
+	}
+
 	// 初始化处理器
 	songRepo := database.NewSongRepository()
 	webHandler := handler.NewWebHandler(
 		cfg.Web.Username,
 		cfg.Web.Password,
+		database.NewUserRepository(),
 		songRepo,
+		database.NewArtistRepository(),
+		database.NewLyricRepository(),
+		bundle,
 	)
 
 	// 创建 Gin 路由
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 
 	"github.com/spf13/viper"
 )
@@ -14,13 +16,48 @@ type Config struct {
 	Web      WebConfig      `mapstructure:"web"`
 	Download DownloadConfig `mapstructure:"download"`
 	Search   SearchConfig   `mapstructure:"search"`
+	Group    GroupConfig    `mapstructure:"group"`
+	Charts   ChartsConfig   `mapstructure:"charts"`
 	Log      LogConfig      `mapstructure:"log"`
 }
 
 // BotConfig Telegram Bot 配置
 type BotConfig struct {
-	Token   string `mapstructure:"token"`
-	AdminID int64  `mapstructure:"admin_id"`
+	Token     string `mapstructure:"token"`
+	AdminID   int64  `mapstructure:"admin_id"`
+	RateLimit int    `mapstructure:"rate_limit"` // 每个用户每分钟最多处理的消息和按钮点击数，0 表示不限制
+	Workers   int    `mapstructure:"workers"`    // 同时处理更新的最大数量，同一聊天的更新始终按顺序处理
+	QueueSize int    `mapstructure:"queue_size"` // 最多排队等待处理的更新数，排满后暂停拉取
+
+	DefaultRole string `mapstructure:"default_role"` // 新用户的角色: uploader（可发送链接下载，默认）, listener（只能播放已有歌曲）
+	Access      string `mapstructure:"access"`       // 谁可以使用 Bot: open（所有人，默认）, whitelist（管理员添加）, invite（凭邀请码加入）
+
+	Mode    string        `mapstructure:"mode"` // 接收更新的方式: polling（长轮询，默认）, webhook
+	Webhook WebhookConfig `mapstructure:"webhook"`
+}
+
+// 访问模式
+const (
+	AccessOpen      = "open"
+	AccessWhitelist = "whitelist"
+	AccessInvite    = "invite"
+)
+
+// 接收更新的方式
+const (
+	BotModePolling = "polling"
+	BotModeWebhook = "webhook"
+)
+
+// WebhookConfig webhook 模式配置
+type WebhookConfig struct {
+	URL            string `mapstructure:"url"`             // Telegram 推送的公网 HTTPS 地址，路径部分即本地监听的路径
+	Listen         string `mapstructure:"listen"`          // 本地监听地址，如 :8443
+	SecretToken    string `mapstructure:"secret_token"`    // 校验 Telegram 请求的密钥，留空时每次启动随机生成
+	CertFile       string `mapstructure:"cert_file"`       // TLS 证书，留空表示由反向代理处理 HTTPS，本地只监听 HTTP
+	KeyFile        string `mapstructure:"key_file"`        // TLS 私钥
+	SelfSigned     bool   `mapstructure:"self_signed"`     // 证书为自签名时需要上传给 Telegram
+	MaxConnections int    `mapstructure:"max_connections"` // Telegram 同时推送的最大连接数（1-100），0 使用默认值 40
 }
 
 // DatabaseConfig 数据库配置
@@ -54,6 +91,19 @@ type SearchConfig struct {
 	Timeout int    `mapstructure:"timeout"`
 }
 
+// GroupConfig 群组配置
+type GroupConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`         // 是否响应群组消息
+	CleanupAfter   int    `mapstructure:"cleanup_after"`   // Bot 消息自动清理时间（秒），0 表示不清理
+	DownloadPolicy string `mapstructure:"download_policy"` // 新群组默认下载权限: all, admins, off
+	ResultLimit    int    `mapstructure:"result_limit"`    // 群内搜索结果数量
+}
+
+// ChartsConfig 排行榜配置
+type ChartsConfig struct {
+	RefreshInterval int `mapstructure:"refresh_interval"` // 排行榜刷新间隔（分钟），0 表示不自动刷新
+}
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level string `mapstructure:"level"`
@@ -92,6 +142,19 @@ func Load(configPath string) (*Config, error) {
 func setDefaults() {
 	viper.SetDefault("bot.token", "")
 	viper.SetDefault("bot.admin_id", 0)
+	viper.SetDefault("bot.rate_limit", 30)
+	viper.SetDefault("bot.workers", 8)
+	viper.SetDefault("bot.queue_size", 1000)
+	viper.SetDefault("bot.default_role", "uploader")
+	viper.SetDefault("bot.access", AccessOpen)
+	viper.SetDefault("bot.mode", BotModePolling)
+	viper.SetDefault("bot.webhook.url", "")
+	viper.SetDefault("bot.webhook.listen", ":8443")
+	viper.SetDefault("bot.webhook.secret_token", "")
+	viper.SetDefault("bot.webhook.cert_file", "")
+	viper.SetDefault("bot.webhook.key_file", "")
+	viper.SetDefault("bot.webhook.self_signed", false)
+	viper.SetDefault("bot.webhook.max_connections", 0)
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.user", "fish_music")
@@ -107,6 +170,11 @@ func setDefaults() {
 	viper.SetDefault("download.cookies_file", "")
 	viper.SetDefault("search.api_url", "")
 	viper.SetDefault("search.timeout", 30)
+	viper.SetDefault("group.enabled", true)
+	viper.SetDefault("group.cleanup_after", 300)
+	viper.SetDefault("group.download_policy", "admins")
+	viper.SetDefault("group.result_limit", 5)
+	viper.SetDefault("charts.refresh_interval", 10)
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.file", "")
 }
@@ -119,6 +187,23 @@ func (c *Config) Validate() error {
 	if c.Bot.AdminID == 0 {
 		return fmt.Errorf("bot.admin_id 不能为空")
 	}
+	if c.Bot.DefaultRole != "uploader" && c.Bot.DefaultRole != "listener" {
+		return fmt.Errorf("bot.default_role 只能是 uploader 或 listener")
+	}
+	switch c.Bot.Access {
+	case AccessOpen, AccessWhitelist, AccessInvite:
+	default:
+		return fmt.Errorf("bot.access 只能是 %s、%s 或 %s", AccessOpen, AccessWhitelist, AccessInvite)
+	}
+	switch c.Bot.Mode {
+	case BotModePolling:
+	case BotModeWebhook:
+		if err := c.Bot.Webhook.Validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("bot.mode 只能是 %s 或 %s", BotModePolling, BotModeWebhook)
+	}
 	if c.Database.Host == "" {
 		return fmt.Errorf("database.host 不能为空")
 	}
@@ -128,6 +213,42 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// Validate 验证 webhook 配置
+func (w *WebhookConfig) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("bot.webhook.url 必须是 https 地址")
+	}
+	if w.Listen == "" {
+		return fmt.Errorf("bot.webhook.listen 不能为空")
+	}
+	if !webhookSecretPattern.MatchString(w.SecretToken) {
+		return fmt.Errorf("bot.webhook.secret_token 只能包含字母、数字、_ 和 -，最长 256 个字符")
+	}
+	if (w.CertFile == "") != (w.KeyFile == "") {
+		return fmt.Errorf("bot.webhook.cert_file 和 key_file 需要同时设置")
+	}
+	if w.SelfSigned && w.CertFile == "" {
+		return fmt.Errorf("bot.webhook.self_signed 需要设置 cert_file")
+	}
+	if w.MaxConnections < 0 || w.MaxConnections > 100 {
+		return fmt.Errorf("bot.webhook.max_connections 范围为 1-100")
+	}
+	return nil
+}
+
+// Path 本地监听的路径，取自 webhook 地址
+func (w *WebhookConfig) Path() string {
+	u, err := url.Parse(w.URL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+// webhookSecretPattern Telegram 对 secret_token 的字符要求，允许为空
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{0,256}$`)
+
 // GetDSN 获取数据库连接字符串
 func (d *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf(
@@ -1,8 +1,18 @@
 package database
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/credits"
+	"github.com/user/fish-music/pkg/lrc"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SongRepository 歌曲数据访问层
@@ -35,28 +45,50 @@ func (r *SongRepository) FindByUniqueHash(hash string) (*model.Song, error) {
 	return &song, nil
 }
 
-// Search 搜索歌曲（标题或歌手）
-func (r *SongRepository) Search(keyword string, limit int) ([]*model.Song, error) {
+// Search 搜索歌曲，按相关度排序，返回当前页结果和匹配总数
+// keyword 支持字段语法，见 ParseSearchQuery
+func (r *SongRepository) Search(keyword string, offset, limit int) ([]*model.Song, int64, error) {
 	var songs []*model.Song
-	query := r.db.Where("status = ?", "active")
+	q := ParseSearchQuery(keyword)
+	query := applySearchFilters(r.db.Model(&model.Song{}).Where("status = ?", "active"), q)
 
-	if keyword != "" {
-		query = query.Where("title ILIKE ? OR artist ILIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%")
+	// 新会话，使 Count 与 Find 互不影响
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	err := query.Order("created_at DESC").Limit(limit).Find(&songs).Error
-	return songs, err
+	err := query.Order(searchOrder(q)).Offset(offset).Limit(limit).Find(&songs).Error
+	return songs, total, err
 }
 
-// Create 创建歌曲记录
+// Create 创建歌曲记录，并根据歌手和专辑字段生成署名
 func (r *SongRepository) Create(song *model.Song) error {
-	return r.db.Create(song).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(song).Error; err != nil {
+			return err
+		}
+		return syncSongCredits(tx, song)
+	})
 }
 
-// Update 更新歌曲记录
+// Update 更新歌曲记录，并重新生成署名
 func (r *SongRepository) Update(song *model.Song) error {
-	return r.db.Save(song).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Credits").Save(song).Error; err != nil {
+			return err
+		}
+		return syncSongCredits(tx, song)
+	})
+}
+
+// SyncCredits 根据歌曲的歌手和专辑字段重新生成署名（歌手、合作歌手、专辑）
+func (r *SongRepository) SyncCredits(song *model.Song) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return syncSongCredits(tx, song)
+	})
 }
 
 // UpdateFileID 更新 FileID（用于补档）
@@ -94,6 +126,106 @@ func (r *SongRepository) GetRandom() (*model.Song, error) {
 	return &song, nil
 }
 
+// GetRandomFiltered 按类型、语言、地区、年代等条件随机获取一首歌
+func (r *SongRepository) GetRandomFiltered(q SearchQuery) (*model.Song, error) {
+	var song model.Song
+	err := applySearchFilters(r.db.Where("status = ?", "active"), q).
+		Order("RANDOM()").
+		First(&song).Error
+	if err != nil {
+		return nil, err
+	}
+	return &song, nil
+}
+
+// CategoryCount 分类取值及歌曲数
+type CategoryCount struct {
+	Value string
+	Count int64
+}
+
+// 可用于电台和筛选的分类
+const (
+	CategoryGenre    = "genre"
+	CategoryLanguage = "language"
+	CategoryCountry  = "country_code"
+	CategoryDecade   = "decade"
+)
+
+// GetCategoryCounts 获取音乐库中某一分类的所有取值及歌曲数，按歌曲数从多到少排列
+func (r *SongRepository) GetCategoryCounts(category string) ([]CategoryCount, error) {
+	var counts []CategoryCount
+	query := r.db.Model(&model.Song{}).Where("status = ?", "active")
+
+	switch category {
+	case CategoryGenre, CategoryLanguage, CategoryCountry:
+		query = query.Select(category + " AS value, COUNT(*) AS count").
+			Where(category + " <> ''").
+			Group(category)
+	case CategoryDecade:
+		query = query.Select("CAST(year / 10 * 10 AS TEXT) AS value, COUNT(*) AS count").
+			Where("year > 0").
+			Group("year / 10 * 10")
+	default:
+		return nil, fmt.Errorf("未知分类: %s", category)
+	}
+
+	err := query.Order("count DESC").Scan(&counts).Error
+	return counts, err
+}
+
+// ArtistCount 歌手及其（作为主唱的）歌曲数
+type ArtistCount struct {
+	ArtistID uint
+	Name     string
+	SortKey  string
+	Count    int64
+}
+
+// GetArtists 获取音乐库中所有歌手及歌曲数，按拼音 / 罗马字排序
+func (r *SongRepository) GetArtists() ([]ArtistCount, error) {
+	var artists []ArtistCount
+	err := r.db.Table("artists").
+		Select("artists.id AS artist_id, artists.name, artists.sort_key, COUNT(DISTINCT songs.id) AS count").
+		Joins("JOIN song_artists ON song_artists.artist_id = artists.id AND song_artists.role = ?", model.ArtistRoleMain).
+		Joins("JOIN songs ON songs.id = song_artists.song_id AND songs.status = ?", "active").
+		Group("artists.id").
+		Order("artists.sort_key, artists.name").
+		Scan(&artists).Error
+	return artists, err
+}
+
+// GetByArtist 获取歌手署名（主唱或合作）的所有歌曲，按专辑、年份排列
+func (r *SongRepository) GetByArtist(artistID uint) ([]*model.Song, error) {
+	var songs []*model.Song
+	err := r.db.Where("status = ?", "active").
+		Where("id IN (?)", r.db.Model(&model.SongArtist{}).
+			Select("song_id").
+			Where("artist_id = ? AND role IN ?", artistID, []string{model.ArtistRoleMain, model.ArtistRoleFeat})).
+		Order("album, year, id").
+		Find(&songs).Error
+	return songs, err
+}
+
+// GetByAlbum 获取专辑的所有歌曲
+func (r *SongRepository) GetByAlbum(albumID uint) ([]*model.Song, error) {
+	var songs []*model.Song
+	err := r.db.Where("status = ? AND album_id = ?", "active", albumID).
+		Order("id").
+		Find(&songs).Error
+	return songs, err
+}
+
+// FindByIDs 根据 ID 批量获取歌曲（顺序不保证与 ids 一致）
+func (r *SongRepository) FindByIDs(ids []uint) ([]*model.Song, error) {
+	var songs []*model.Song
+	if len(ids) == 0 {
+		return songs, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&songs).Error
+	return songs, err
+}
+
 // GetRandomSongs 随机获取多首歌曲
 func (r *SongRepository) GetRandomSongs(limit int) ([]*model.Song, error) {
 	var songs []*model.Song
@@ -117,10 +249,20 @@ func (r *SongRepository) GetStats() (map[string]interface{}, error) {
 	}
 	stats["missing_songs"] = missingCount
 
+	// 按规范化后的歌手统计，别名和合作署名不会重复计数
 	var artistCount int64
-	if err := r.db.Model(&model.Song{}).Select("COUNT(DISTINCT artist)").Scan(&artistCount).Error; err != nil {
+	if err := r.db.Model(&model.SongArtist{}).
+		Where("role = ?", model.ArtistRoleMain).
+		Select("COUNT(DISTINCT artist_id)").
+		Scan(&artistCount).Error; err != nil {
 		return nil, err
 	}
+	// 尚未执行 backfill -task artists 时退回按歌手字段统计
+	if artistCount == 0 && totalCount > 0 {
+		if err := r.db.Model(&model.Song{}).Select("COUNT(DISTINCT artist)").Scan(&artistCount).Error; err != nil {
+			return nil, err
+		}
+	}
 	stats["total_artists"] = artistCount
 
 	var todayCount int64
@@ -158,8 +300,18 @@ func (r *UserRepository) FindByTelegramID(telegramID int64) (*model.User, error)
 	return &user, nil
 }
 
-// FindOrCreate 查找或创建用户
-func (r *UserRepository) FindOrCreate(telegramID int64, username, firstName, lastName string) (*model.User, error) {
+// FindByID 根据 ID 查找用户
+func (r *UserRepository) FindByID(id uint) (*model.User, error) {
+	var user model.User
+	err := r.db.Where("id = ?", id).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindOrCreate 查找或创建用户，language、role 和 member 为新用户的初始界面语言、角色和是否获准使用
+func (r *UserRepository) FindOrCreate(telegramID int64, username, firstName, lastName, language, role string, member bool) (*model.User, error) {
 	var user model.User
 	err := r.db.Where("telegram_id = ?", telegramID).First(&user).Error
 
@@ -169,6 +321,9 @@ func (r *UserRepository) FindOrCreate(telegramID int64, username, firstName, las
 			Username:   username,
 			FirstName:  firstName,
 			LastName:   lastName,
+			Language:   language,
+			Role:       role,
+			Member:     member,
 		}
 		if err := r.db.Create(&user).Error; err != nil {
 			return nil, err
@@ -179,11 +334,65 @@ func (r *UserRepository) FindOrCreate(telegramID int64, username, firstName, las
 	return &user, err
 }
 
+// UpdateLanguage 更新用户界面语言
+func (r *UserRepository) UpdateLanguage(userID uint, language string) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("language", language).Error
+}
+
+// FindByUsername 根据 Telegram 用户名查找用户，不区分大小写，不含 @
+func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
+	var user model.User
+	err := r.db.Where("LOWER(username) = LOWER(?)", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByRoles 查找指定角色的用户，按角色和 ID 排序
+func (r *UserRepository) FindByRoles(roles ...string) ([]model.User, error) {
+	var users []model.User
+	err := r.db.Where("role IN ?", roles).
+		Order("role, id").
+		Find(&users).Error
+	return users, err
+}
+
+// UpdateRole 更新用户角色
+func (r *UserRepository) UpdateRole(userID uint, role string) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("role", role).Error
+}
+
+// UpdateWebToken 更新 Web 后台登录令牌（保存 SHA-256）
+func (r *UserRepository) UpdateWebToken(userID uint, tokenHash string) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("web_token", tokenHash).Error
+}
+
+// UpdateActive 更新用户是否可以接收消息（屏蔽 Bot 后为 false，重新使用时恢复）
+func (r *UserRepository) UpdateActive(userID uint, active bool) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("is_active", active).Error
+}
+
+// UpdateMember 更新用户是否获准使用（白名单、邀请制）
+func (r *UserRepository) UpdateMember(userID uint, member bool) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("member", member).Error
+}
+
 // UpdateLastSeen 更新最后活跃时间
 func (r *UserRepository) UpdateLastSeen(userID uint) error {
 	return r.db.Model(&model.User{}).
 		Where("id = ?", userID).
-		Update("last_seen", "NOW()").Error
+		Update("last_seen", time.Now()).Error
 }
 
 // ============================================
@@ -272,6 +481,12 @@ func (r *HistoryRepository) GetByUser(userID uint, limit int) ([]*model.Song, er
 	return songs, err
 }
 
+// ClearByUser 清空用户的播放历史
+func (r *HistoryRepository) ClearByUser(userID uint) (int64, error) {
+	result := r.db.Where("user_id = ?", userID).Delete(&model.History{})
+	return result.RowsAffected, result.Error
+}
+
 // GetRecentHistory 获取最近的播放记录（返回完整的 History 记录）
 func (r *HistoryRepository) GetRecentHistory(userID uint, limit int) ([]*model.History, error) {
 	var histories []*model.History
@@ -282,3 +497,1607 @@ func (r *HistoryRepository) GetRecentHistory(userID uint, limit int) ([]*model.H
 		Find(&histories).Error
 	return histories, err
 }
+
+// ============================================
+// GroupSettingRepository 群组设置数据访问层
+// ============================================
+
+// GroupSettingRepository 群组设置仓库
+type GroupSettingRepository struct {
+	db *gorm.DB
+}
+
+// NewGroupSettingRepository 创建群组设置仓库
+func NewGroupSettingRepository() *GroupSettingRepository {
+	return &GroupSettingRepository{db: DB}
+}
+
+// FindOrCreate 查找或创建群组设置，新群组使用传入的默认值
+func (r *GroupSettingRepository) FindOrCreate(chatID int64, title string, defaults model.GroupSetting) (*model.GroupSetting, error) {
+	var setting model.GroupSetting
+	err := r.db.Where("chat_id = ?", chatID).First(&setting).Error
+
+	if err == gorm.ErrRecordNotFound {
+		setting = defaults
+		setting.ChatID = chatID
+		setting.Title = title
+		if err := r.db.Create(&setting).Error; err != nil {
+			return nil, err
+		}
+		return &setting, nil
+	}
+
+	return &setting, err
+}
+
+// Update 更新群组设置
+func (r *GroupSettingRepository) Update(setting *model.GroupSetting) error {
+	return r.db.Save(setting).Error
+}
+
+// ============================================
+// PlaylistRepository 歌单数据访问层
+// ============================================
+
+// PlaylistRepository 歌单仓库
+type PlaylistRepository struct {
+	db *gorm.DB
+}
+
+// NewPlaylistRepository 创建歌单仓库
+func NewPlaylistRepository() *PlaylistRepository {
+	return &PlaylistRepository{db: DB}
+}
+
+// Create 创建歌单
+func (r *PlaylistRepository) Create(userID uint, name string) (*model.Playlist, error) {
+	playlist := &model.Playlist{
+		UserID: userID,
+		Name:   name,
+	}
+	if err := r.db.Create(playlist).Error; err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// FindByID 根据 ID 查找歌单（含歌曲数量）
+func (r *PlaylistRepository) FindByID(id uint) (*model.Playlist, error) {
+	var playlist model.Playlist
+	err := r.withSongCount().Where("playlists.id = ?", id).First(&playlist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+// GetByUser 获取用户的所有歌单（含歌曲数量），包括已加入的协作歌单
+func (r *PlaylistRepository) GetByUser(userID uint) ([]*model.Playlist, error) {
+	var playlists []*model.Playlist
+	err := r.withSongCount().
+		Where("playlists.user_id = ? OR (playlists.is_collaborative AND EXISTS "+
+			"(SELECT 1 FROM playlist_collaborators WHERE playlist_collaborators.playlist_id = playlists.id AND playlist_collaborators.user_id = ?))",
+			userID, userID).
+		Order("playlists.created_at ASC").
+		Find(&playlists).Error
+	return playlists, err
+}
+
+// FindByShareToken 根据分享令牌查找歌单
+func (r *PlaylistRepository) FindByShareToken(token string) (*model.Playlist, error) {
+	var playlist model.Playlist
+	err := r.withSongCount().Where("playlists.share_token = ? AND playlists.share_token <> ''", token).First(&playlist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+// SetShareToken 设置分享令牌，传入空字符串表示撤销分享
+func (r *PlaylistRepository) SetShareToken(id uint, token string) error {
+	return r.db.Model(&model.Playlist{}).Where("id = ?", id).Update("share_token", token).Error
+}
+
+// SetCollaborative 设置是否允许协作
+func (r *PlaylistRepository) SetCollaborative(id uint, collaborative bool) error {
+	return r.db.Model(&model.Playlist{}).Where("id = ?", id).Update("is_collaborative", collaborative).Error
+}
+
+// AddCollaborator 添加协作者（已存在时忽略）
+func (r *PlaylistRepository) AddCollaborator(playlistID, userID uint) error {
+	var count int64
+	if err := r.db.Model(&model.PlaylistCollaborator{}).
+		Where("playlist_id = ? AND user_id = ?", playlistID, userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return r.db.Create(&model.PlaylistCollaborator{PlaylistID: playlistID, UserID: userID}).Error
+}
+
+// IsCollaborator 检查用户是否为歌单协作者
+func (r *PlaylistRepository) IsCollaborator(playlistID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.PlaylistCollaborator{}).
+		Where("playlist_id = ? AND user_id = ?", playlistID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Copy 将歌单复制为指定用户的新歌单，保留歌曲顺序
+func (r *PlaylistRepository) Copy(playlistID, userID uint, name string) (*model.Playlist, error) {
+	copied := &model.Playlist{
+		UserID: userID,
+		Name:   name,
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(copied).Error; err != nil {
+			return err
+		}
+		return tx.Exec(
+			"INSERT INTO playlist_items (playlist_id, song_id, position, created_at) "+
+				"SELECT ?, song_id, position, NOW() FROM playlist_items WHERE playlist_id = ?",
+			copied.ID, playlistID,
+		).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// withSongCount 查询歌单时附带歌曲数量
+func (r *PlaylistRepository) withSongCount() *gorm.DB {
+	return r.db.Model(&model.Playlist{}).
+		Select("playlists.*, (SELECT COUNT(*) FROM playlist_items WHERE playlist_items.playlist_id = playlists.id) AS song_count")
+}
+
+// Rename 重命名歌单
+func (r *PlaylistRepository) Rename(id uint, name string) error {
+	return r.db.Model(&model.Playlist{}).Where("id = ?", id).Update("name", name).Error
+}
+
+// Delete 删除歌单及其所有条目和协作者
+func (r *PlaylistRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("playlist_id = ?", id).Delete(&model.PlaylistItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("playlist_id = ?", id).Delete(&model.PlaylistCollaborator{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Playlist{}, id).Error
+	})
+}
+
+// AddSong 添加歌曲到歌单末尾，已存在时返回 false
+// 锁定歌单所在行，协作者同时添加时按顺序分配位置
+func (r *PlaylistRepository) AddSong(playlistID, songID uint) (bool, error) {
+	added := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&model.Playlist{}, playlistID).Error; err != nil {
+			return err
+		}
+
+		var maxPosition int
+		if err := tx.Model(&model.PlaylistItem{}).
+			Where("playlist_id = ?", playlistID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+
+		item := &model.PlaylistItem{
+			PlaylistID: playlistID,
+			SongID:     songID,
+			Position:   maxPosition + 1,
+		}
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "playlist_id"}, {Name: "song_id"}},
+			DoNothing: true,
+		}).Create(item)
+		if result.Error != nil {
+			return result.Error
+		}
+		added = result.RowsAffected > 0
+		return nil
+	})
+	return added, err
+}
+
+// RemoveSong 从歌单移除歌曲，并使后续条目的位置前移
+func (r *PlaylistRepository) RemoveSong(playlistID, songID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var item model.PlaylistItem
+		if err := tx.Where("playlist_id = ? AND song_id = ?", playlistID, songID).First(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.PlaylistItem{}).
+			Where("playlist_id = ? AND position > ?", playlistID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+}
+
+// MoveSong 移动歌曲位置（delta 为 -1 上移，1 下移），与相邻条目交换
+func (r *PlaylistRepository) MoveSong(playlistID, songID uint, delta int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var item model.PlaylistItem
+		if err := tx.Where("playlist_id = ? AND song_id = ?", playlistID, songID).First(&item).Error; err != nil {
+			return err
+		}
+
+		var neighbor model.PlaylistItem
+		err := tx.Where("playlist_id = ? AND position = ?", playlistID, item.Position+delta).First(&neighbor).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil // 已在首位或末位
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&neighbor).Update("position", item.Position).Error; err != nil {
+			return err
+		}
+		return tx.Model(&item).Update("position", neighbor.Position).Error
+	})
+}
+
+// GetSongs 按歌单顺序获取歌曲，返回当前页和总数
+func (r *PlaylistRepository) GetSongs(playlistID uint, offset, limit int) ([]*model.Song, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.PlaylistItem{}).
+		Where("playlist_id = ?", playlistID).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var songs []*model.Song
+	query := r.db.Table("songs").
+		Select("songs.*").
+		Joins("INNER JOIN playlist_items ON playlist_items.song_id = songs.id").
+		Where("playlist_items.playlist_id = ?", playlistID).
+		Order("playlist_items.position ASC").
+		Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&songs).Error
+	return songs, total, err
+}
+
+// ============================================
+// ShareRepository 分享记录数据访问层
+// ============================================
+
+// ShareRepository 分享记录仓库
+type ShareRepository struct {
+	db *gorm.DB
+}
+
+// NewShareRepository 创建分享记录仓库
+func NewShareRepository() *ShareRepository {
+	return &ShareRepository{db: DB}
+}
+
+// FindOrCreate 获取用户对某首歌的分享记录，不存在时用 token 创建
+// 迁移前创建、还没有令牌的记录同样补上 token
+func (r *ShareRepository) FindOrCreate(songID, userID uint, token string) (*model.SongShare, error) {
+	var share model.SongShare
+	err := r.db.Where("song_id = ? AND user_id = ?", songID, userID).First(&share).Error
+
+	if err == gorm.ErrRecordNotFound {
+		share = model.SongShare{
+			SongID: songID,
+			UserID: userID,
+			Token:  token,
+		}
+		if err := r.db.Create(&share).Error; err != nil {
+			return nil, err
+		}
+		return &share, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if share.Token == "" {
+		if err := r.db.Model(&share).Update("token", token).Error; err != nil {
+			return nil, err
+		}
+		share.Token = token
+	}
+	return &share, nil
+}
+
+// FindByToken 根据分享令牌查找分享记录（含歌曲和分享者）
+func (r *ShareRepository) FindByToken(token string) (*model.SongShare, error) {
+	var share model.SongShare
+	err := r.db.Preload("Song").Preload("User").Where("token = ?", token).First(&share).Error
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// IncrementOpens 分享链接被打开次数加一
+func (r *ShareRepository) IncrementOpens(id uint) error {
+	return r.db.Model(&model.SongShare{}).
+		Where("id = ?", id).
+		UpdateColumn("open_count", gorm.Expr("open_count + 1")).Error
+}
+
+// GetStats 获取分享统计：分享链接数和被打开次数
+func (r *ShareRepository) GetStats() (int64, int64, error) {
+	var result struct {
+		Shares int64
+		Opens  int64
+	}
+	err := r.db.Model(&model.SongShare{}).
+		Select("COUNT(*) AS shares, COALESCE(SUM(open_count), 0) AS opens").
+		Scan(&result).Error
+	return result.Shares, result.Opens, err
+}
+
+// ============================================
+// QueueRepository 播放队列数据访问层
+// ============================================
+
+// QueueRepository 播放队列仓库
+type QueueRepository struct {
+	db *gorm.DB
+}
+
+// NewQueueRepository 创建播放队列仓库
+func NewQueueRepository() *QueueRepository {
+	return &QueueRepository{db: DB}
+}
+
+// Get 获取用户的播放队列，不存在时创建
+func (r *QueueRepository) Get(userID uint) (*model.PlayQueue, error) {
+	var queue model.PlayQueue
+	err := r.db.Where("user_id = ?", userID).First(&queue).Error
+
+	if err == gorm.ErrRecordNotFound {
+		queue = model.PlayQueue{
+			UserID:     userID,
+			RepeatMode: model.RepeatOff,
+			Source:     model.QueueSourceLibrary,
+		}
+		if err := r.db.Create(&queue).Error; err != nil {
+			return nil, err
+		}
+		return &queue, nil
+	}
+
+	return &queue, err
+}
+
+// Update 更新播放队列状态
+func (r *QueueRepository) Update(queue *model.PlayQueue) error {
+	return r.db.Save(queue).Error
+}
+
+// Count 队列条目总数
+func (r *QueueRepository) Count(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.QueueItem{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ItemAt 获取指定位置的条目（含歌曲）
+func (r *QueueRepository) ItemAt(userID uint, position int) (*model.QueueItem, error) {
+	var item model.QueueItem
+	err := r.db.Preload("Song").
+		Where("user_id = ? AND position = ?", userID, position).
+		First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetUpcoming 获取 after 之后的条目（含歌曲），返回当前页和总数
+func (r *QueueRepository) GetUpcoming(userID uint, after, offset, limit int) ([]*model.QueueItem, int64, error) {
+	var items []*model.QueueItem
+	var total int64
+
+	query := r.db.Model(&model.QueueItem{}).Where("user_id = ? AND position > ?", userID, after)
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Song").
+		Order("position ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&items).Error
+	return items, total, err
+}
+
+// Append 将歌曲追加到队列末尾，返回实际追加数量
+func (r *QueueRepository) Append(userID uint, songIDs []uint) (int, error) {
+	if len(songIDs) == 0 {
+		return 0, nil
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockQueue(tx, userID); err != nil {
+			return err
+		}
+
+		var maxPosition int
+		if err := tx.Model(&model.QueueItem{}).
+			Where("user_id = ?", userID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+
+		items := make([]*model.QueueItem, 0, len(songIDs))
+		for i, songID := range songIDs {
+			items = append(items, &model.QueueItem{
+				UserID:   userID,
+				SongID:   songID,
+				Position: maxPosition + i + 1,
+			})
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(songIDs), nil
+}
+
+// lockQueue 锁定用户的播放队列行（不存在时先创建），同一用户的并发追加按顺序分配位置
+func lockQueue(tx *gorm.DB, userID uint) error {
+	queue := &model.PlayQueue{
+		UserID:     userID,
+		RepeatMode: model.RepeatOff,
+		Source:     model.QueueSourceLibrary,
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoNothing: true,
+	}).Create(queue).Error; err != nil {
+		return err
+	}
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("user_id = ?", userID).
+		First(&model.PlayQueue{}).Error
+}
+
+// Remove 移除指定位置的条目，并使后续条目的位置前移
+func (r *QueueRepository) Remove(userID uint, position int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND position = ?", userID, position).Delete(&model.QueueItem{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&model.QueueItem{}).
+			Where("user_id = ? AND position > ?", userID, position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+}
+
+// Clear 清空队列、已播放记录并重置播放位置
+func (r *QueueRepository) Clear(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.QueueItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.QueuePlayed{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.PlayQueue{}).Where("user_id = ?", userID).Update("current", 0).Error
+	})
+}
+
+// Shuffle 打乱 after 之后条目的顺序（已播放的条目保持不变）
+func (r *QueueRepository) Shuffle(userID uint, after int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var items []*model.QueueItem
+		if err := tx.Where("user_id = ? AND position > ?", userID, after).Find(&items).Error; err != nil {
+			return err
+		}
+
+		rand.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+		for i, item := range items {
+			if err := tx.Model(item).Update("position", after+i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TrimPlayed 删除 before 之前的已播放条目，其余条目位置整体前移
+// 删除的歌曲记入已播放记录，自动填充时继续排除
+func (r *QueueRepository) TrimPlayed(userID uint, before int) error {
+	if before <= 1 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"INSERT INTO play_queue_played (user_id, song_id) "+
+				"SELECT DISTINCT user_id, song_id FROM play_queue_items WHERE user_id = ? AND position < ? "+
+				"ON CONFLICT DO NOTHING",
+			userID, before,
+		).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND position < ?", userID, before).Delete(&model.QueueItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.QueueItem{}).
+			Where("user_id = ?", userID).
+			Update("position", gorm.Expr("position - ?", before-1)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.PlayQueue{}).
+			Where("user_id = ?", userID).
+			Update("current", gorm.Expr("current - ?", before-1)).Error
+	})
+}
+
+// GetFillCandidates 按队列的来源获取自动填充的歌曲 ID
+// 已在队列中、已播放后被清理的歌曲和需要补档的歌曲会被排除，来源歌曲全部播过后返回空
+func (r *QueueRepository) GetFillCandidates(queue *model.PlayQueue, limit int) ([]uint, error) {
+	query := r.db.Model(&model.Song{}).
+		Where("songs.is_missing = ?", false).
+		Where("songs.id NOT IN (?)", r.db.Model(&model.QueueItem{}).Select("song_id").Where("user_id = ?", queue.UserID)).
+		Where("songs.id NOT IN (?)", r.db.Model(&model.QueuePlayed{}).Select("song_id").Where("user_id = ?", queue.UserID))
+
+	order := "RANDOM()"
+	switch queue.Source {
+	case model.QueueSourceFavorites:
+		query = query.Joins("JOIN favorites ON favorites.song_id = songs.id AND favorites.user_id = ?", queue.UserID)
+		order = "favorites.created_at DESC"
+	case model.QueueSourcePlaylist:
+		playlistID, err := strconv.ParseUint(queue.SourceRef, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Joins("JOIN playlist_items ON playlist_items.song_id = songs.id AND playlist_items.playlist_id = ?", playlistID)
+		order = "playlist_items.position ASC"
+	case model.QueueSourceArtist:
+		artistID, err := strconv.ParseUint(queue.SourceRef, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("songs.id IN (?)", r.db.Model(&model.SongArtist{}).
+			Select("song_id").
+			Where("artist_id = ? AND role IN ?", artistID, []string{model.ArtistRoleMain, model.ArtistRoleFeat}))
+		order = "songs.created_at ASC"
+	case model.QueueSourceAlbum:
+		albumID, err := strconv.ParseUint(queue.SourceRef, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("songs.album_id = ?", albumID)
+		order = "songs.id ASC"
+	case model.QueueSourceStation:
+		query = applySearchFilters(query.Where("songs.status = ?", "active"), ParseSearchQuery(queue.SourceRef))
+	}
+	if queue.Shuffle {
+		order = "RANDOM()"
+	}
+
+	var ids []uint
+	err := query.Order(order).Limit(limit).Pluck("songs.id", &ids).Error
+	return ids, err
+}
+
+// ============================================
+// RecommendRepository 推荐数据访问层
+// ============================================
+
+// SongScore 歌曲得分（播放次数、共同收听人数等）
+type SongScore struct {
+	SongID uint
+	Score  float64
+}
+
+// RecommendRepository 推荐数据仓库
+type RecommendRepository struct {
+	db *gorm.DB
+}
+
+// NewRecommendRepository 创建推荐数据仓库
+func NewRecommendRepository() *RecommendRepository {
+	return &RecommendRepository{db: DB}
+}
+
+// GetPlayCounts 获取用户播放次数最多的歌曲
+func (r *RecommendRepository) GetPlayCounts(userID uint, limit int) ([]SongScore, error) {
+	var scores []SongScore
+	err := r.db.Model(&model.History{}).
+		Select("song_id, COUNT(*) AS score").
+		Where("user_id = ?", userID).
+		Group("song_id").
+		Order("score DESC").
+		Limit(limit).
+		Scan(&scores).Error
+	return scores, err
+}
+
+// GetFavoriteIDs 获取用户收藏的歌曲 ID
+func (r *RecommendRepository) GetFavoriteIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.Favorite{}).Where("user_id = ?", userID).Pluck("song_id", &ids).Error
+	return ids, err
+}
+
+// GetPlayedSince 获取用户在 since 之后播放过的歌曲 ID
+func (r *RecommendRepository) GetPlayedSince(userID uint, since time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.History{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Distinct().
+		Pluck("song_id", &ids).Error
+	return ids, err
+}
+
+// GetCoListened 共同收听：听过种子歌曲的其他用户还听过哪些歌，得分为这些用户的人数
+// 只统计 since 之后的播放，并且最多取 listenerLimit 位最近听过种子歌曲的用户，
+// 避免对整个历史表做自连接；第二步按 (user_id, created_at) 过滤，可以只扫描 idx_history_user_created_song
+func (r *RecommendRepository) GetCoListened(userID uint, seedIDs []uint, since time.Time, listenerLimit, limit int) ([]SongScore, error) {
+	var scores []SongScore
+	if len(seedIDs) == 0 {
+		return scores, nil
+	}
+	listeners := r.db.Table("history").
+		Select("user_id").
+		Where("song_id IN ? AND user_id <> ? AND created_at >= ?", seedIDs, userID, since).
+		Group("user_id").
+		Order("MAX(created_at) DESC").
+		Limit(listenerLimit)
+	err := r.db.Table("(?) AS listeners", listeners).
+		Select("other.song_id, COUNT(DISTINCT other.user_id) AS score").
+		Joins("JOIN history AS other ON other.user_id = listeners.user_id AND other.created_at >= ?", since).
+		Where("other.song_id NOT IN ?", seedIDs).
+		Group("other.song_id").
+		Order("score DESC").
+		Limit(limit).
+		Scan(&scores).Error
+	return scores, err
+}
+
+// GetByMetadata 获取歌手、类型或语言匹配任一条件的歌曲（随机抽样）
+func (r *RecommendRepository) GetByMetadata(artists, genres, languages []string, limit int) ([]*model.Song, error) {
+	var songs []*model.Song
+	var conds []string
+	var args []interface{}
+	if len(artists) > 0 {
+		conds = append(conds, "artist IN ?")
+		args = append(args, artists)
+	}
+	if len(genres) > 0 {
+		conds = append(conds, "genre IN ?")
+		args = append(args, genres)
+	}
+	if len(languages) > 0 {
+		conds = append(conds, "language IN ?")
+		args = append(args, languages)
+	}
+	if len(conds) == 0 {
+		return songs, nil
+	}
+
+	err := r.db.Where("status = ?", "active").
+		Where(strings.Join(conds, " OR "), args...).
+		Order("RANDOM()").
+		Limit(limit).
+		Find(&songs).Error
+	return songs, err
+}
+
+// GetPopular 获取 since 之后播放人数最多的歌曲
+func (r *RecommendRepository) GetPopular(since time.Time, limit int) ([]SongScore, error) {
+	var scores []SongScore
+	err := r.db.Model(&model.History{}).
+		Select("song_id, COUNT(DISTINCT user_id) AS score").
+		Where("created_at >= ?", since).
+		Group("song_id").
+		Order("score DESC").
+		Limit(limit).
+		Scan(&scores).Error
+	return scores, err
+}
+
+// ============================================
+// ArtistRepository 歌手和专辑数据访问层
+// ============================================
+
+// ArtistRepository 歌手和专辑仓库
+type ArtistRepository struct {
+	db *gorm.DB
+}
+
+// NewArtistRepository 创建歌手仓库
+func NewArtistRepository() *ArtistRepository {
+	return &ArtistRepository{db: DB}
+}
+
+// FindByID 根据 ID 查找歌手（含别名）
+func (r *ArtistRepository) FindByID(id uint) (*model.Artist, error) {
+	var artist model.Artist
+	err := r.db.Preload("Aliases").Where("id = ?", id).First(&artist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
+
+// FindByName 根据名称或别名查找歌手（不区分大小写）
+func (r *ArtistRepository) FindByName(name string) (*model.Artist, error) {
+	return findArtistByName(r.db, name)
+}
+
+// List 歌手列表（含歌曲数），keyword 匹配名称或别名
+func (r *ArtistRepository) List(keyword string, offset, limit int) ([]*model.Artist, int64, error) {
+	var artists []*model.Artist
+	var total int64
+
+	query := r.db.Model(&model.Artist{})
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where("name ILIKE ? OR id IN (?)", like,
+			r.db.Model(&model.ArtistAlias{}).Select("artist_id").Where("alias LIKE ?", strings.ToLower(like)))
+	}
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Aliases").
+		Select("artists.*, (SELECT COUNT(DISTINCT song_id) FROM song_artists WHERE song_artists.artist_id = artists.id) AS song_count").
+		Order("sort_key, name").
+		Offset(offset).
+		Limit(limit).
+		Find(&artists).Error
+	return artists, total, err
+}
+
+// GetDuplicates 获取疑似重复的歌手：排序键（拼音 / 罗马字）相同的分为一组，
+// 如 "周杰伦" 和 "周杰倫"
+func (r *ArtistRepository) GetDuplicates() ([][]*model.Artist, error) {
+	var artists []*model.Artist
+	err := r.db.Where("sort_key <> '' AND sort_key IN (?)",
+		r.db.Model(&model.Artist{}).Select("sort_key").Group("sort_key").Having("COUNT(*) > 1")).
+		Select("artists.*, (SELECT COUNT(DISTINCT song_id) FROM song_artists WHERE song_artists.artist_id = artists.id) AS song_count").
+		Order("sort_key, id").
+		Find(&artists).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var groups [][]*model.Artist
+	for i, artist := range artists {
+		if i == 0 || artist.SortKey != artists[i-1].SortKey {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], artist)
+	}
+	return groups, nil
+}
+
+// AddAlias 为歌手添加别名，之后以该别名署名的歌曲会归到此歌手
+func (r *ArtistRepository) AddAlias(artistID uint, alias string) error {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if alias == "" {
+		return fmt.Errorf("别名不能为空")
+	}
+	if existing, err := findArtistByName(r.db, alias); err == nil && existing.ID != artistID {
+		return fmt.Errorf("别名已被歌手 %s（#%d）使用，请改用合并", existing.Name, existing.ID)
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.ArtistAlias{ArtistID: artistID, Alias: alias}).Error
+}
+
+// RemoveAlias 删除歌手别名
+func (r *ArtistRepository) RemoveAlias(artistID, aliasID uint) error {
+	return r.db.Where("id = ? AND artist_id = ?", aliasID, artistID).Delete(&model.ArtistAlias{}).Error
+}
+
+// Merge 将歌手 fromID 合并到 intoID：
+// 署名和专辑转到目标歌手（同名专辑合并），原名称及别名成为目标歌手的别名，最后删除原歌手
+func (r *ArtistRepository) Merge(fromID, intoID uint) error {
+	if fromID == intoID {
+		return fmt.Errorf("不能合并到自身")
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var from, into model.Artist
+		if err := tx.Preload("Aliases").Where("id = ?", fromID).First(&from).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", intoID).First(&into).Error; err != nil {
+			return err
+		}
+
+		// 署名：目标歌手已有相同署名的先删除，其余转移
+		if err := tx.Where("artist_id = ? AND (song_id, role) IN (?)", fromID,
+			tx.Model(&model.SongArtist{}).Select("song_id, role").Where("artist_id = ?", intoID)).
+			Delete(&model.SongArtist{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.SongArtist{}).Where("artist_id = ?", fromID).
+			Update("artist_id", intoID).Error; err != nil {
+			return err
+		}
+
+		// 专辑：目标歌手已有同名专辑的合并歌曲后删除，其余转移
+		var albums []*model.Album
+		if err := tx.Where("artist_id = ?", fromID).Find(&albums).Error; err != nil {
+			return err
+		}
+		for _, album := range albums {
+			var existing model.Album
+			err := tx.Where("artist_id = ? AND LOWER(title) = LOWER(?)", intoID, album.Title).First(&existing).Error
+			if err == gorm.ErrRecordNotFound {
+				if err := tx.Model(album).Update("artist_id", intoID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&model.Song{}).Where("album_id = ?", album.ID).
+				UpdateColumn("album_id", existing.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(album).Error; err != nil {
+				return err
+			}
+		}
+
+		// 别名：原歌手的名称和别名都归到目标歌手
+		if err := tx.Model(&model.ArtistAlias{}).Where("artist_id = ?", fromID).
+			Update("artist_id", intoID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&from).Error; err != nil {
+			return err
+		}
+		if !strings.EqualFold(from.Name, into.Name) {
+			return tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&model.ArtistAlias{ArtistID: intoID, Alias: strings.ToLower(from.Name)}).Error
+		}
+		return nil
+	})
+}
+
+// FindMainBySong 获取歌曲的第一主唱
+func (r *ArtistRepository) FindMainBySong(songID uint) (*model.Artist, error) {
+	var artist model.Artist
+	err := r.db.Joins("JOIN song_artists ON song_artists.artist_id = artists.id").
+		Where("song_artists.song_id = ? AND song_artists.role = ?", songID, model.ArtistRoleMain).
+		Order("song_artists.position").
+		First(&artist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
+
+// FindAlbumByID 根据 ID 查找专辑（含歌手）
+func (r *ArtistRepository) FindAlbumByID(id uint) (*model.Album, error) {
+	var album model.Album
+	err := r.db.Preload("Artist").Where("id = ?", id).First(&album).Error
+	if err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+// GetAlbums 获取歌手的所有专辑（含歌曲数），按年份排列
+func (r *ArtistRepository) GetAlbums(artistID uint) ([]*model.Album, error) {
+	var albums []*model.Album
+	err := r.db.Model(&model.Album{}).
+		Select("albums.*, (SELECT COUNT(*) FROM songs WHERE songs.album_id = albums.id AND songs.status = 'active') AS song_count").
+		Where("artist_id = ?", artistID).
+		Order("year, title").
+		Find(&albums).Error
+	return albums, err
+}
+
+// findArtistByName 按名称或别名查找歌手（不区分大小写）
+func findArtistByName(db *gorm.DB, name string) (*model.Artist, error) {
+	var artist model.Artist
+	err := db.Where("LOWER(name) = LOWER(?)", name).First(&artist).Error
+	if err == gorm.ErrRecordNotFound {
+		err = db.Where("id = (?)",
+			db.Model(&model.ArtistAlias{}).Select("artist_id").Where("alias = ?", strings.ToLower(name))).
+			First(&artist).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
+
+// findOrCreateArtist 按名称或别名查找歌手，不存在时创建
+func findOrCreateArtist(db *gorm.DB, name string) (*model.Artist, error) {
+	artist, err := findArtistByName(db, name)
+	if err == nil {
+		return artist, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	artist = &model.Artist{Name: name}
+	if err := db.Create(artist).Error; err != nil {
+		return nil, err
+	}
+	return artist, nil
+}
+
+// findOrCreateAlbum 查找歌手的专辑（不区分大小写），不存在时创建
+func findOrCreateAlbum(db *gorm.DB, artistID uint, song *model.Song) (*model.Album, error) {
+	var album model.Album
+	err := db.Where("artist_id = ? AND LOWER(title) = LOWER(?)", artistID, song.Album).First(&album).Error
+	if err == nil {
+		return &album, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	album = model.Album{
+		Title:    song.Album,
+		ArtistID: artistID,
+		Year:     song.Year,
+		CoverURL: song.CoverURL,
+	}
+	if err := db.Create(&album).Error; err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+// syncSongCredits 解析歌曲的歌手字段和标题，重新生成主唱 / 合作署名并关联专辑
+func syncSongCredits(tx *gorm.DB, song *model.Song) error {
+	known := func(name string) bool {
+		_, err := findArtistByName(tx, name)
+		return err == nil
+	}
+
+	if err := tx.Where("song_id = ? AND role IN ?", song.ID, []string{model.ArtistRoleMain, model.ArtistRoleFeat}).
+		Delete(&model.SongArtist{}).Error; err != nil {
+		return err
+	}
+
+	var mainArtistID uint
+	positions := make(map[string]int)
+	for _, c := range credits.Parse(song.Artist, song.Title, known) {
+		artist, err := findOrCreateArtist(tx, c.Name)
+		if err != nil {
+			return err
+		}
+
+		role := model.ArtistRoleMain
+		if c.Featured {
+			role = model.ArtistRoleFeat
+		}
+		if role == model.ArtistRoleMain && mainArtistID == 0 {
+			mainArtistID = artist.ID
+		}
+
+		credit := &model.SongArtist{SongID: song.ID, ArtistID: artist.ID, Role: role, Position: positions[role]}
+		positions[role]++
+		// 不同写法解析到同一歌手时忽略重复署名
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(credit).Error; err != nil {
+			return err
+		}
+	}
+
+	var albumID *uint
+	if song.Album != "" && mainArtistID != 0 {
+		album, err := findOrCreateAlbum(tx, mainArtistID, song)
+		if err != nil {
+			return err
+		}
+		albumID = &album.ID
+	}
+	song.AlbumID = albumID
+	return tx.Model(&model.Song{}).Where("id = ?", song.ID).UpdateColumn("album_id", albumID).Error
+}
+
+// ============================================
+// LyricRepository 歌词数据访问层
+// ============================================
+
+// LyricRepository 歌词仓库
+type LyricRepository struct {
+	db *gorm.DB
+}
+
+// NewLyricRepository 创建歌词仓库
+func NewLyricRepository() *LyricRepository {
+	return &LyricRepository{db: DB}
+}
+
+// FindBySong 获取歌曲的歌词
+func (r *LyricRepository) FindBySong(songID uint) (*model.SongLyric, error) {
+	var lyric model.SongLyric
+	err := r.db.Where("song_id = ?", songID).First(&lyric).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lyric, nil
+}
+
+// Save 保存歌曲歌词（已存在则覆盖），纯文本同步写入 songs.lyrics 供全文搜索
+func (r *LyricRepository) Save(lyric *model.SongLyric) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "song_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"source", "synced", "lines", "updated_at"}),
+		}).Create(lyric).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.Song{}).Where("id = ?", lyric.SongID).
+			UpdateColumn("lyrics", lrc.Text(lyric.Lines)).Error
+	})
+}
+
+// Delete 删除歌曲歌词
+func (r *LyricRepository) Delete(songID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("song_id = ?", songID).Delete(&model.SongLyric{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Song{}).Where("id = ?", songID).UpdateColumn("lyrics", "").Error
+	})
+}
+
+// ============================================
+// StatsRepository 个人收听统计数据访问层
+// ============================================
+
+// 统计维度
+const (
+	StatSong     = "song"
+	StatArtist   = "artist"
+	StatGenre    = "genre"
+	StatLanguage = "language"
+)
+
+// ListeningSummary 收听概况
+type ListeningSummary struct {
+	Plays     int64      // 播放次数
+	Seconds   int64      // 收听时长（按歌曲时长累计）
+	Songs     int64      // 听过的不同歌曲数
+	FirstPlay *time.Time // 第一次播放
+	LastPlay  *time.Time // 最近一次播放
+}
+
+// StatCount 某个维度的播放统计
+type StatCount struct {
+	SongID  uint // 仅歌曲维度有值
+	Name    string
+	Plays   int64
+	Seconds int64
+}
+
+// PeriodCount 按时间段（月份等）的播放次数
+type PeriodCount struct {
+	Period int
+	Plays  int64
+}
+
+// StatsRepository 个人收听统计仓库
+// 所有查询都先在 history 上按 user_id + created_at 范围聚合到歌曲（走 idx_history_user_created_song 覆盖索引），
+// 再关联歌曲表，避免逐条关联大量历史记录
+type StatsRepository struct {
+	db *gorm.DB
+}
+
+// NewStatsRepository 创建个人收听统计仓库
+func NewStatsRepository() *StatsRepository {
+	return &StatsRepository{db: DB}
+}
+
+// userHistory 用户在 [from, to) 范围内的播放记录，from / to 为零值表示不限
+func (r *StatsRepository) userHistory(userID uint, from, to time.Time) *gorm.DB {
+	query := r.db.Model(&model.History{}).Where("history.user_id = ?", userID)
+	if !from.IsZero() {
+		query = query.Where("history.created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("history.created_at < ?", to)
+	}
+	return query
+}
+
+// songPlays 按歌曲聚合的播放次数子查询
+func (r *StatsRepository) songPlays(userID uint, from, to time.Time) *gorm.DB {
+	return r.userHistory(userID, from, to).
+		Select("song_id, COUNT(*) AS plays, MIN(created_at) AS first_play, MAX(created_at) AS last_play").
+		Group("song_id")
+}
+
+// GetSummary 获取收听概况
+func (r *StatsRepository) GetSummary(userID uint, from, to time.Time) (*ListeningSummary, error) {
+	var summary ListeningSummary
+	err := r.db.Table("(?) AS plays", r.songPlays(userID, from, to)).
+		Select(`COALESCE(SUM(plays.plays), 0) AS plays,
+			COALESCE(SUM(plays.plays * songs.duration), 0) AS seconds,
+			COUNT(*) AS songs,
+			MIN(plays.first_play) AS first_play,
+			MAX(plays.last_play) AS last_play`).
+		Joins("JOIN songs ON songs.id = plays.song_id").
+		Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// GetTop 获取某个维度播放最多的前 limit 项
+func (r *StatsRepository) GetTop(userID uint, dimension string, from, to time.Time, limit int) ([]StatCount, error) {
+	query := r.db.Table("(?) AS plays", r.songPlays(userID, from, to)).
+		Joins("JOIN songs ON songs.id = plays.song_id")
+
+	switch dimension {
+	case StatSong:
+		query = query.Select("songs.id AS song_id, songs.title || ' - ' || songs.artist AS name, plays.plays, plays.plays * songs.duration AS seconds")
+	case StatArtist:
+		// 按规范化后的主唱统计，合作歌曲计入每位主唱
+		query = query.
+			Joins("JOIN song_artists ON song_artists.song_id = plays.song_id AND song_artists.role = ?", model.ArtistRoleMain).
+			Joins("JOIN artists ON artists.id = song_artists.artist_id").
+			Select("artists.name, SUM(plays.plays) AS plays, SUM(plays.plays * songs.duration) AS seconds").
+			Group("artists.id, artists.name")
+	case StatGenre, StatLanguage:
+		column := "songs." + dimension
+		query = query.
+			Select(column + " AS name, SUM(plays.plays) AS plays, SUM(plays.plays * songs.duration) AS seconds").
+			Where(column + " <> ''").
+			Group(column)
+	default:
+		return nil, fmt.Errorf("未知统计维度: %s", dimension)
+	}
+
+	var counts []StatCount
+	err := query.Order("plays DESC, name").Limit(limit).Scan(&counts).Error
+	return counts, err
+}
+
+// GetActiveDays 获取有播放记录的日期（升序）
+func (r *StatsRepository) GetActiveDays(userID uint, from, to time.Time) ([]time.Time, error) {
+	var days []time.Time
+	err := r.userHistory(userID, from, to).
+		Distinct("DATE(created_at) AS day").
+		Order("day").
+		Pluck("day", &days).Error
+	return days, err
+}
+
+// GetMonthlyPlays 按月份统计播放次数
+func (r *StatsRepository) GetMonthlyPlays(userID uint, from, to time.Time) ([]PeriodCount, error) {
+	var counts []PeriodCount
+	err := r.userHistory(userID, from, to).
+		Select("EXTRACT(MONTH FROM created_at)::int AS period, COUNT(*) AS plays").
+		Group("period").
+		Order("period").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// GetBusiestDay 获取播放次数最多的一天
+func (r *StatsRepository) GetBusiestDay(userID uint, from, to time.Time) (time.Time, int64, error) {
+	var result struct {
+		Day   time.Time
+		Plays int64
+	}
+	err := r.userHistory(userID, from, to).
+		Select("DATE(created_at) AS day, COUNT(*) AS plays").
+		Group("day").
+		Order("plays DESC, day").
+		Limit(1).
+		Scan(&result).Error
+	return result.Day, result.Plays, err
+}
+
+// CountNewSongs 统计在 [from, to) 范围内第一次听到的歌曲数
+func (r *StatsRepository) CountNewSongs(userID uint, from, to time.Time) (int64, error) {
+	var count int64
+	err := r.db.Table("(?) AS plays", r.songPlays(userID, time.Time{}, to)).
+		Where("plays.first_play >= ?", from).
+		Count(&count).Error
+	return count, err
+}
+
+// GetYears 获取有播放记录的年份（升序）
+func (r *StatsRepository) GetYears(userID uint) ([]int, error) {
+	var years []int
+	err := r.userHistory(userID, time.Time{}, time.Time{}).
+		Distinct("EXTRACT(YEAR FROM created_at)::int AS year").
+		Order("year").
+		Pluck("year", &years).Error
+	return years, err
+}
+
+// ============================================
+// ChartRepository 全站排行榜数据访问层
+// ============================================
+
+// 排行榜类型
+const (
+	ChartDay      = "day"
+	ChartWeek     = "week"
+	ChartAll      = "all"
+	ChartTrending = "trending"
+	ChartNew      = "new"
+)
+
+// chartTrendingMinPlays 上飙升榜所需的最近 24 小时最少播放次数，避免偶然播放一两次就上榜
+const chartTrendingMinPlays = 2
+
+// ChartEntry 排行榜条目
+type ChartEntry struct {
+	Song  *model.Song
+	Plays int64 // 榜单对应时间段的播放次数，新歌榜为 0
+}
+
+// ChartRepository 全站排行榜仓库
+// 播放次数来自 song_charts 物化视图（见 sql/migration_charts.sql），由 Refresh 定时刷新
+type ChartRepository struct {
+	db *gorm.DB
+}
+
+// NewChartRepository 创建排行榜仓库
+func NewChartRepository() *ChartRepository {
+	return &ChartRepository{db: DB}
+}
+
+// Refresh 刷新排行榜物化视图，刷新期间不阻塞查询
+func (r *ChartRepository) Refresh() error {
+	return r.db.Exec("REFRESH MATERIALIZED VIEW CONCURRENTLY song_charts").Error
+}
+
+// RefreshedAt 获取排行榜上次刷新的时间，视图为空时返回 nil
+func (r *ChartRepository) RefreshedAt() (*time.Time, error) {
+	var result struct {
+		RefreshedAt *time.Time
+	}
+	err := r.db.Table("song_charts").Select("MAX(refreshed_at) AS refreshed_at").Scan(&result).Error
+	return result.RefreshedAt, err
+}
+
+// GetChart 获取播放排行榜，返回当前页条目和上榜歌曲总数
+func (r *ChartRepository) GetChart(chart string, offset, limit int) ([]ChartEntry, int64, error) {
+	query := r.db.Table("song_charts").
+		Joins("JOIN songs ON songs.id = song_charts.song_id AND songs.status = ?", "active")
+
+	var column, order string
+	switch chart {
+	case ChartDay:
+		column, order = "plays_day", "plays_day DESC"
+	case ChartWeek:
+		column, order = "plays_week", "plays_week DESC"
+	case ChartAll:
+		column, order = "plays_total", "plays_total DESC"
+	case ChartTrending:
+		column, order = "plays_day", "trending_score DESC"
+		query = query.Where("song_charts.trending_score > 0 AND song_charts.plays_day >= ?", chartTrendingMinPlays)
+	default:
+		return nil, 0, fmt.Errorf("未知排行榜: %s", chart)
+	}
+	query = query.Where("song_charts." + column + " > 0")
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		SongID uint
+		Plays  int64
+	}
+	err := query.Select("song_charts.song_id, song_charts." + column + " AS plays").
+		Order("song_charts." + order + ", song_charts.song_id").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, total, err
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.SongID
+	}
+	var songs []*model.Song
+	if err := r.db.Where("id IN ?", ids).Find(&songs).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]*model.Song, len(songs))
+	for _, song := range songs {
+		byID[song.ID] = song
+	}
+
+	entries := make([]ChartEntry, 0, len(rows))
+	for _, row := range rows {
+		if song, ok := byID[row.SongID]; ok {
+			entries = append(entries, ChartEntry{Song: song, Plays: row.Plays})
+		}
+	}
+	return entries, total, nil
+}
+
+// GetNewSongs 获取最新入库的歌曲，返回当前页和歌曲总数
+func (r *ChartRepository) GetNewSongs(offset, limit int) ([]*model.Song, int64, error) {
+	query := r.db.Model(&model.Song{}).Where("status = ?", "active")
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var songs []*model.Song
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&songs).Error
+	return songs, total, err
+}
+
+// ============================================
+// UserSettingRepository 用户设置数据访问层
+// ============================================
+
+// UserSettingRepository 用户设置仓库
+type UserSettingRepository struct {
+	db *gorm.DB
+}
+
+// NewUserSettingRepository 创建用户设置仓库
+func NewUserSettingRepository() *UserSettingRepository {
+	return &UserSettingRepository{db: DB}
+}
+
+// Get 获取用户设置，没有保存过时返回默认设置
+func (r *UserSettingRepository) Get(userID uint) (*model.UserSetting, error) {
+	var setting model.UserSetting
+	err := r.db.Where("user_id = ?", userID).First(&setting).Error
+	if err == gorm.ErrRecordNotFound {
+		return model.DefaultUserSetting(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// Save 保存用户设置（按 user_id 插入或更新）
+func (r *UserSettingRepository) Save(setting *model.UserSetting) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"search_limit", "show_lyrics", "random_filter", "record_history",
+			"notify_new_songs", "notify_announcements", "updated_at",
+		}),
+	}).Create(setting).Error
+}
+
+// FindNewSongSubscribers 查找开启了新歌通知、且收藏过该歌曲任一主唱的歌的用户
+func (r *UserSettingRepository) FindNewSongSubscribers(songID, excludeUserID uint) ([]*model.User, error) {
+	artists := r.db.Model(&model.SongArtist{}).
+		Select("artist_id").
+		Where("song_id = ? AND role = ?", songID, model.ArtistRoleMain)
+	fans := r.db.Table("favorites").
+		Select("favorites.user_id").
+		Joins("JOIN song_artists ON song_artists.song_id = favorites.song_id AND song_artists.role = ?", model.ArtistRoleMain).
+		Where("song_artists.artist_id IN (?)", artists)
+
+	var users []*model.User
+	err := r.db.
+		Joins("JOIN user_settings ON user_settings.user_id = users.id AND user_settings.notify_new_songs").
+		Where("users.id IN (?) AND users.id <> ? AND users.is_active", fans, excludeUserID).
+		Find(&users).Error
+	return users, err
+}
+
+// ============================================
+// InviteRepository 邀请码数据访问层
+// ============================================
+
+// 邀请码无法使用的原因
+var (
+	ErrInviteNotFound = errors.New("邀请码不存在或已作废")
+	ErrInviteExpired  = errors.New("邀请码已过期")
+	ErrInviteUsedUp   = errors.New("邀请码使用次数已满")
+)
+
+// InviteRepository 邀请码仓库
+type InviteRepository struct {
+	db *gorm.DB
+}
+
+// NewInviteRepository 创建邀请码仓库
+func NewInviteRepository() *InviteRepository {
+	return &InviteRepository{db: DB}
+}
+
+// Create 创建邀请码
+func (r *InviteRepository) Create(invite *model.InviteCode) error {
+	return r.db.Create(invite).Error
+}
+
+// FindActive 查找未作废、未过期且未用完的邀请码，按创建时间倒序
+func (r *InviteRepository) FindActive(limit int) ([]model.InviteCode, error) {
+	var invites []model.InviteCode
+	err := r.db.Preload("Creator").
+		Where("NOT revoked AND uses < max_uses AND (expires_at IS NULL OR expires_at > NOW())").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&invites).Error
+	return invites, err
+}
+
+// Revoke 作废邀请码，邀请码不存在时返回 false
+func (r *InviteRepository) Revoke(code string) (bool, error) {
+	result := r.db.Model(&model.InviteCode{}).
+		Where("code = ? AND NOT revoked", code).
+		Update("revoked", true)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Redeem 使用邀请码：校验后增加使用次数，并将用户标记为获准使用
+// 锁定邀请码所在行，多人同时使用同一个邀请码时不会超出次数
+func (r *InviteRepository) Redeem(code string, userID uint) (*model.InviteCode, error) {
+	var invite model.InviteCode
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ? AND NOT revoked", code).
+			First(&invite).Error
+		if err == gorm.ErrRecordNotFound {
+			return ErrInviteNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if invite.IsExpired(time.Now()) {
+			return ErrInviteExpired
+		}
+		if invite.IsUsedUp() {
+			return ErrInviteUsedUp
+		}
+
+		if err := tx.Model(&invite).Update("uses", gorm.Expr("uses + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{"member": true, "invited_by": invite.CreatedBy}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	invite.Uses++
+	return &invite, nil
+}
+
+// ============================================
+// BroadcastRepository 公告数据访问层
+// ============================================
+
+// BroadcastRepository 公告仓库
+type BroadcastRepository struct {
+	db *gorm.DB
+}
+
+// NewBroadcastRepository 创建公告仓库
+func NewBroadcastRepository() *BroadcastRepository {
+	return &BroadcastRepository{db: DB}
+}
+
+// Create 创建公告
+func (r *BroadcastRepository) Create(broadcast *model.Broadcast) error {
+	return r.db.Create(broadcast).Error
+}
+
+// FindByID 根据 ID 查找公告，附带创建者
+func (r *BroadcastRepository) FindByID(id uint) (*model.Broadcast, error) {
+	var broadcast model.Broadcast
+	err := r.db.Preload("Creator").Where("id = ?", id).First(&broadcast).Error
+	if err != nil {
+		return nil, err
+	}
+	return &broadcast, nil
+}
+
+// FindByStatus 查找指定状态的公告，按创建顺序
+func (r *BroadcastRepository) FindByStatus(status string) ([]model.Broadcast, error) {
+	var broadcasts []model.Broadcast
+	err := r.db.Preload("Creator").
+		Where("status = ?", status).
+		Order("id").
+		Find(&broadcasts).Error
+	return broadcasts, err
+}
+
+// Start 确认发送草稿，公告不是草稿时返回 false
+func (r *BroadcastRepository) Start(id uint) (bool, error) {
+	result := r.db.Model(&model.Broadcast{}).
+		Where("id = ? AND status = ?", id, model.BroadcastDraft).
+		Updates(map[string]interface{}{"status": model.BroadcastSending, "started_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Cancel 取消草稿或发送中的公告，已结束时返回 false
+func (r *BroadcastRepository) Cancel(id uint) (bool, error) {
+	result := r.db.Model(&model.Broadcast{}).
+		Where("id = ? AND status IN ?", id, []string{model.BroadcastDraft, model.BroadcastSending}).
+		Updates(map[string]interface{}{"status": model.BroadcastCancelled, "finished_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Finish 标记发送完毕，已取消的公告保持不变并返回 false
+func (r *BroadcastRepository) Finish(id uint) (bool, error) {
+	result := r.db.Model(&model.Broadcast{}).
+		Where("id = ? AND status = ?", id, model.BroadcastSending).
+		Updates(map[string]interface{}{"status": model.BroadcastDone, "finished_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// SaveProgress 保存发送进度
+func (r *BroadcastRepository) SaveProgress(broadcast *model.Broadcast) error {
+	return r.db.Model(&model.Broadcast{}).
+		Where("id = ?", broadcast.ID).
+		Updates(map[string]interface{}{
+			"sent":         broadcast.Sent,
+			"failed":       broadcast.Failed,
+			"blocked":      broadcast.Blocked,
+			"last_user_id": broadcast.LastUserID,
+		}).Error
+}
+
+// CountRecipients 统计公告的接收人数
+func (r *BroadcastRepository) CountRecipients(broadcast *model.Broadcast) (int64, error) {
+	var count int64
+	err := r.recipients(broadcast).Count(&count).Error
+	return count, err
+}
+
+// NextRecipients 按用户 ID 顺序获取 LastUserID 之后的下一批接收人
+func (r *BroadcastRepository) NextRecipients(broadcast *model.Broadcast, limit int) ([]model.User, error) {
+	var users []model.User
+	err := r.recipients(broadcast).
+		Where("users.id > ?", broadcast.LastUserID).
+		Order("users.id").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// recipients 公告的接收人：可以接收消息、未被封禁、没有在 /settings 中关闭公告，并符合发送对象
+func (r *BroadcastRepository) recipients(broadcast *model.Broadcast) *gorm.DB {
+	query := r.db.Model(&model.User{}).
+		Joins("LEFT JOIN user_settings ON user_settings.user_id = users.id").
+		Where("users.is_active AND users.role <> ?", model.RoleBanned).
+		Where("COALESCE(user_settings.notify_announcements, TRUE)")
+	if broadcast.ActiveSince != nil {
+		query = query.Where("users.last_seen >= ?", *broadcast.ActiveSince)
+	}
+	if broadcast.Language != "" {
+		query = query.Where("users.language = ?", broadcast.Language)
+	}
+	if broadcast.MembersOnly {
+		query = query.Where("users.member OR users.role IN ?", []string{model.RoleOwner, model.RoleAdmin})
+	}
+	return query
+}
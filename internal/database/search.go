@@ -0,0 +1,245 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/user/fish-music/pkg/romanize"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchQuery 解析后的搜索条件
+// 支持字段语法，例如：artist:周杰伦 year:2008 稻香
+type SearchQuery struct {
+	Text     string // 自由文本（标题、歌手、专辑、歌词）
+	Artist   string // artist: 歌手
+	Album    string // album: 专辑
+	Genre    string // genre: 类型
+	Language string // lang: / language: 语言
+	Country  string // country: 国家代码
+	YearFrom int    // year: 起始年份
+	YearTo   int    // year: 结束年份
+}
+
+// IsEmpty 是否没有任何搜索条件
+func (q SearchQuery) IsEmpty() bool {
+	return q.Text == "" && q.Artist == "" && q.Album == "" &&
+		q.Genre == "" && q.Language == "" && q.Country == "" && q.YearFrom == 0
+}
+
+// String 还原为搜索语法，ParseSearchQuery(q.String()) 可得到相同的条件
+func (q SearchQuery) String() string {
+	var parts []string
+	add := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if strings.ContainsFunc(value, unicode.IsSpace) {
+			value = `"` + value + `"`
+		}
+		parts = append(parts, key+":"+value)
+	}
+
+	add("artist", q.Artist)
+	add("album", q.Album)
+	add("genre", q.Genre)
+	add("lang", q.Language)
+	add("country", q.Country)
+	if q.YearFrom > 0 {
+		if q.YearFrom == q.YearTo {
+			add("year", strconv.Itoa(q.YearFrom))
+		} else {
+			add("year", fmt.Sprintf("%d-%d", q.YearFrom, q.YearTo))
+		}
+	}
+	if q.Text != "" {
+		parts = append(parts, q.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseSearchQuery 解析搜索关键词
+//
+// 支持的字段：
+//   - artist:周杰伦 / artist:"Jay Chou"
+//   - album:叶惠美
+//   - genre:摇滚
+//   - lang:日语 或 language:日语
+//   - country:JP
+//   - year:2008 / year:2000-2009 / year:2000s
+//
+// 无法识别的 key:value 按普通文本处理
+func ParseSearchQuery(input string) SearchQuery {
+	var q SearchQuery
+	var text []string
+
+	for _, token := range splitSearchTokens(input) {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok {
+			key, value, ok = strings.Cut(token, "：")
+		}
+		value = strings.Trim(value, `"`)
+		if !ok || value == "" {
+			text = append(text, token)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "artist", "歌手":
+			q.Artist = value
+		case "album", "专辑":
+			q.Album = value
+		case "genre", "类型":
+			q.Genre = value
+		case "lang", "language", "语言":
+			q.Language = value
+		case "country", "地区":
+			q.Country = strings.ToUpper(value)
+		case "year", "年份":
+			from, to, ok := parseYearRange(value)
+			if !ok {
+				text = append(text, token)
+				continue
+			}
+			q.YearFrom, q.YearTo = from, to
+		default:
+			text = append(text, token)
+		}
+	}
+
+	q.Text = strings.Join(text, " ")
+	return q
+}
+
+// splitSearchTokens 按空白切分关键词，双引号内的空白保留
+func splitSearchTokens(input string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuote := false
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuote:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// parseYearRange 解析年份：2008、2000-2009、2000s
+func parseYearRange(value string) (int, int, bool) {
+	if strings.HasSuffix(value, "s") {
+		decade, err := strconv.Atoi(strings.TrimSuffix(value, "s"))
+		if err != nil || decade%10 != 0 {
+			return 0, 0, false
+		}
+		return decade, decade + 9, true
+	}
+
+	if from, to, ok := strings.Cut(value, "-"); ok {
+		fromYear, err1 := strconv.Atoi(from)
+		toYear, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil || fromYear > toYear {
+			return 0, 0, false
+		}
+		return fromYear, toYear, true
+	}
+
+	year, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, false
+	}
+	return year, year, true
+}
+
+// applySearchFilters 将搜索条件应用到查询
+// 依赖 sql/migration_search_index.sql 创建的 pg_trgm 扩展和 search_vector 列
+func applySearchFilters(db *gorm.DB, q SearchQuery) *gorm.DB {
+	if q.Text != "" {
+		like := "%" + q.Text + "%"
+		cond := "search_vector @@ plainto_tsquery('simple', ?) OR " +
+			"title ILIKE ? OR artist ILIKE ? OR album ILIKE ? OR " +
+			"title % ? OR artist % ? OR ? <% title"
+		args := []interface{}{q.Text, like, like, like, q.Text, q.Text, q.Text}
+
+		if romanCond, romanArgs := romanizedCondition(q.Text); romanCond != "" {
+			cond += " OR " + romanCond
+			args = append(args, romanArgs...)
+		}
+		db = db.Where(cond, args...)
+	}
+	if q.Artist != "" {
+		db = db.Where("artist ILIKE ? OR artist % ?", "%"+q.Artist+"%", q.Artist)
+	}
+	if q.Album != "" {
+		db = db.Where("album ILIKE ? OR album % ?", "%"+q.Album+"%", q.Album)
+	}
+	if q.Genre != "" {
+		db = db.Where("genre = ?", q.Genre)
+	}
+	if q.Language != "" {
+		db = db.Where("language = ?", q.Language)
+	}
+	if q.Country != "" {
+		db = db.Where("country_code = ?", q.Country)
+	}
+	if q.YearFrom > 0 {
+		db = db.Where("year BETWEEN ? AND ?", q.YearFrom, q.YearTo)
+	}
+	return db
+}
+
+// searchOrder 按相关度排序：标题完全匹配 > 全文检索得分 > 模糊相似度
+func searchOrder(q SearchQuery) interface{} {
+	if q.Text == "" {
+		return "created_at DESC"
+	}
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL: "(CASE WHEN lower(title) = lower(?) THEN 1 ELSE 0 END) DESC, " +
+			"ts_rank(search_vector, plainto_tsquery('simple', ?)) + " +
+			"GREATEST(similarity(title, ?), similarity(artist, ?), word_similarity(?, title)) DESC, " +
+			"created_at DESC",
+		Vars:               []interface{}{q.Text, q.Text, q.Text, q.Text, q.Text},
+		WithoutParentheses: true,
+	}}
+}
+
+// romanizedCondition 拼音 / 罗马字匹配条件
+// 每个词都需出现在完整拼音中（"dao xiang"、"zhoujielun"），
+// 或整体匹配首字母（"zjl"）；关键词包含汉字等非拉丁文字时不生效
+func romanizedCondition(text string) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	var joined strings.Builder
+
+	for _, word := range strings.Fields(text) {
+		normalized := romanize.Normalize(word)
+		if normalized == "" {
+			return "", nil
+		}
+		parts = append(parts, "search_romanized LIKE ?")
+		args = append(args, "%"+normalized+"%")
+		joined.WriteString(normalized)
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	cond := "(" + strings.Join(parts, " AND ") + ") OR search_initials LIKE ?"
+	args = append(args, "%"+joined.String()+"%")
+	return cond, args
+}
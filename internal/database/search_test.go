@@ -0,0 +1,56 @@
+package database
+
+import "testing"
+
+func TestParseSearchQuery(t *testing.T) {
+	cases := []struct {
+		input string
+		want  SearchQuery
+	}{
+		{"周杰伦 稻香", SearchQuery{Text: "周杰伦 稻香"}},
+		{"artist:周杰伦 稻香", SearchQuery{Artist: "周杰伦", Text: "稻香"}},
+		{`artist:"Jay Chou" album:叶惠美`, SearchQuery{Artist: "Jay Chou", Album: "叶惠美"}},
+		{"歌手：周杰伦 晴天", SearchQuery{Artist: "周杰伦", Text: "晴天"}},
+		{"genre:摇滚 lang:日语 country:jp", SearchQuery{Genre: "摇滚", Language: "日语", Country: "JP"}},
+		{"language:英语", SearchQuery{Language: "英语"}},
+		{"year:2008", SearchQuery{YearFrom: 2008, YearTo: 2008}},
+		{"year:2000-2009 晴天", SearchQuery{YearFrom: 2000, YearTo: 2009, Text: "晴天"}},
+		{"year:1990s", SearchQuery{YearFrom: 1990, YearTo: 1999}},
+		{"year:1995s", SearchQuery{Text: "year:1995s"}},
+		{"year:2010-2000", SearchQuery{Text: "year:2010-2000"}},
+		{"year:abc", SearchQuery{Text: "year:abc"}},
+		{"mood:happy 晴天", SearchQuery{Text: "mood:happy 晴天"}},
+		{"artist: 晴天", SearchQuery{Text: "artist: 晴天"}},
+		{"  稻香   ", SearchQuery{Text: "稻香"}},
+		{"", SearchQuery{}},
+	}
+	for _, tc := range cases {
+		if got := ParseSearchQuery(tc.input); got != tc.want {
+			t.Errorf("ParseSearchQuery(%q) = %+v，期望 %+v", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestSearchQueryString(t *testing.T) {
+	inputs := []string{
+		"稻香",
+		`artist:"Jay Chou" year:2000s 晴天`,
+		"album:叶惠美 genre:流行 lang:中文 country:TW year:2003",
+		"year:2000-2009",
+	}
+	for _, input := range inputs {
+		q := ParseSearchQuery(input)
+		if again := ParseSearchQuery(q.String()); again != q {
+			t.Errorf("%q: String() = %q，重新解析为 %+v，期望 %+v", input, q.String(), again, q)
+		}
+	}
+}
+
+func TestSearchQueryIsEmpty(t *testing.T) {
+	if !ParseSearchQuery("  ").IsEmpty() {
+		t.Error("空白关键词应为空条件")
+	}
+	if ParseSearchQuery("year:2008").IsEmpty() {
+		t.Error("只有年份时不应为空条件")
+	}
+}
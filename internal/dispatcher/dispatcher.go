@@ -0,0 +1,208 @@
+// Package dispatcher 并发处理 Telegram 更新，同一聊天内保持顺序
+package dispatcher
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Dispatcher 更新分发器
+// 不同聊天的更新由固定数量的 worker 并行处理，同一聊天的更新按到达顺序依次处理；
+// 每个 worker 处理完一条更新后会把该聊天放回队尾，避免一个繁忙的聊天占住 worker
+type Dispatcher struct {
+	handle   func(tgbotapi.Update)
+	workers  int
+	capacity int
+
+	mu        sync.Mutex
+	work      *sync.Cond // 有可处理的聊天或分发器关闭
+	space     *sync.Cond // 队列有空位或分发器关闭
+	queues    map[int64]*chatQueue
+	ready     []int64 // 有待处理更新、且没有 worker 正在处理的聊天
+	pending   int
+	active    int
+	processed int64
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// chatQueue 单个聊天的待处理更新
+type chatQueue struct {
+	updates []tgbotapi.Update
+}
+
+// Stats 分发器状态
+type Stats struct {
+	Workers      int   // worker 数量
+	Capacity     int   // 最多排队的更新数
+	Pending      int   // 排队中的更新数
+	Active       int   // 正在处理的更新数
+	Chats        int   // 有排队或正在处理更新的聊天数
+	MaxChatDepth int   // 单个聊天最多排队的更新数
+	Processed    int64 // 已处理的更新总数
+}
+
+// New 创建并启动分发器，workers 为并发处理的最大数量，capacity 为最多排队的更新数
+func New(handle func(tgbotapi.Update), workers, capacity int) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	d := &Dispatcher{
+		handle:   handle,
+		workers:  workers,
+		capacity: capacity,
+		queues:   make(map[int64]*chatQueue),
+	}
+	d.work = sync.NewCond(&d.mu)
+	d.space = sync.NewCond(&d.mu)
+
+	d.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.worker()
+	}
+	return d
+}
+
+// Submit 提交一条更新，队列已满时阻塞直到有空位；分发器已关闭时返回 false
+// 长轮询时阻塞会暂停拉取更新，未拉取的更新由 Telegram 服务端保留
+func (d *Dispatcher) Submit(update tgbotapi.Update) bool {
+	key := chatKey(update)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for d.pending >= d.capacity && !d.closed {
+		d.space.Wait()
+	}
+	if d.closed {
+		return false
+	}
+
+	q, ok := d.queues[key]
+	if !ok {
+		// 没有 worker 在处理这个聊天，放入就绪队列
+		q = &chatQueue{}
+		d.queues[key] = q
+		d.ready = append(d.ready, key)
+		d.work.Signal()
+	}
+	q.updates = append(q.updates, update)
+	d.pending++
+	return true
+}
+
+// Shutdown 停止接收新的更新，等待已排队和正在处理的更新全部完成
+// ctx 超时时立即返回 ctx.Err()，剩余的更新继续在后台处理
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	d.work.Broadcast()
+	d.space.Broadcast()
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats 当前状态
+func (d *Dispatcher) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := Stats{
+		Workers:   d.workers,
+		Capacity:  d.capacity,
+		Pending:   d.pending,
+		Active:    d.active,
+		Chats:     len(d.queues),
+		Processed: d.processed,
+	}
+	for _, q := range d.queues {
+		if len(q.updates) > stats.MaxChatDepth {
+			stats.MaxChatDepth = len(q.updates)
+		}
+	}
+	return stats
+}
+
+// worker 循环取出就绪聊天的下一条更新处理，分发器关闭且没有待处理更新时退出
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for {
+		for len(d.ready) == 0 {
+			if d.closed {
+				return
+			}
+			d.work.Wait()
+		}
+
+		key := d.ready[0]
+		d.ready = d.ready[1:]
+		q := d.queues[key]
+		update := q.updates[0]
+		q.updates = q.updates[1:]
+		d.pending--
+		d.active++
+		d.space.Signal()
+
+		d.mu.Unlock()
+		d.run(update)
+		d.mu.Lock()
+
+		d.active--
+		d.processed++
+		if len(q.updates) == 0 {
+			delete(d.queues, key)
+		} else {
+			d.ready = append(d.ready, key)
+			d.work.Signal()
+		}
+	}
+}
+
+// run 处理单条更新，panic 不会导致 worker 退出
+func (d *Dispatcher) run(update tgbotapi.Update) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("处理更新 %d 时发生 panic: %v\n%s", update.UpdateID, p, debug.Stack())
+		}
+	}()
+	d.handle(update)
+}
+
+// chatKey 决定更新的处理顺序：同一聊天的消息和按钮回调按顺序处理，
+// inline 查询等不属于聊天的更新按用户排序
+// 不使用 Update.FromChat，它在 inline 消息的回调（没有 Message）上会 panic
+func chatKey(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.Chat != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil && update.CallbackQuery.Message.Chat != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	if user := update.SentFrom(); user != nil {
+		return user.ID
+	}
+	return 0
+}
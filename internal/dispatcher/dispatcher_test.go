@@ -0,0 +1,235 @@
+package dispatcher
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// chatUpdate 构造一条发往指定聊天的消息更新
+func chatUpdate(updateID int, chatID int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateID,
+		Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: chatID},
+			Chat: &tgbotapi.Chat{ID: chatID, Type: "private"},
+		},
+	}
+}
+
+func shutdown(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown 返回错误: %v", err)
+	}
+}
+
+func TestPerChatOrdering(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[int64][]int)
+
+	d := New(func(update tgbotapi.Update) {
+		// 让不同更新的处理时间不同，打乱完成顺序
+		time.Sleep(time.Duration(update.UpdateID%3) * time.Millisecond)
+		mu.Lock()
+		seen[update.Message.Chat.ID] = append(seen[update.Message.Chat.ID], update.UpdateID)
+		mu.Unlock()
+	}, 4, 100)
+
+	const chats, perChat = 5, 20
+	for i := 0; i < perChat; i++ {
+		for chat := int64(1); chat <= chats; chat++ {
+			d.Submit(chatUpdate(i, chat))
+		}
+	}
+	shutdown(t, d)
+
+	for chat := int64(1); chat <= chats; chat++ {
+		ids := seen[chat]
+		if len(ids) != perChat {
+			t.Fatalf("聊天 %d 处理了 %d 条更新，期望 %d 条", chat, len(ids), perChat)
+		}
+		for i, id := range ids {
+			if id != i {
+				t.Fatalf("聊天 %d 的处理顺序错误: %v", chat, ids)
+			}
+		}
+	}
+}
+
+func TestChatsRunConcurrently(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan int64, 2)
+
+	d := New(func(update tgbotapi.Update) {
+		started <- update.Message.Chat.ID
+		<-release
+	}, 2, 10)
+
+	d.Submit(chatUpdate(1, 1))
+	d.Submit(chatUpdate(2, 2))
+
+	// 第一个聊天阻塞时，第二个聊天也应开始处理
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("不同聊天的更新没有并发处理")
+		}
+	}
+	close(release)
+	shutdown(t, d)
+}
+
+func TestWorkerBound(t *testing.T) {
+	var active, maxActive int32
+	d := New(func(update tgbotapi.Update) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+	}, 3, 100)
+
+	for i := 0; i < 50; i++ {
+		d.Submit(chatUpdate(i, int64(i)))
+	}
+	shutdown(t, d)
+
+	if maxActive > 3 {
+		t.Errorf("同时处理了 %d 条更新，超过 worker 数 3", maxActive)
+	}
+	if stats := d.Stats(); stats.Processed != 50 {
+		t.Errorf("Processed = %d，期望 50", stats.Processed)
+	}
+}
+
+func TestSubmitBlocksWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	d := New(func(update tgbotapi.Update) { <-release }, 1, 2)
+
+	// 第一条被 worker 取走，之后两条排队，队列已满
+	d.Submit(chatUpdate(1, 1))
+	waitFor(t, func() bool { return d.Stats().Active == 1 })
+	d.Submit(chatUpdate(2, 1))
+	d.Submit(chatUpdate(3, 2))
+
+	submitted := make(chan struct{})
+	go func() {
+		d.Submit(chatUpdate(4, 3))
+		close(submitted)
+	}()
+
+	select {
+	case <-submitted:
+		t.Fatal("队列已满时 Submit 应阻塞")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stats := d.Stats()
+	if stats.Pending != 2 || stats.Chats != 2 || stats.MaxChatDepth != 1 {
+		t.Errorf("Stats = %+v，期望 2 条排队、2 个聊天", stats)
+	}
+
+	close(release)
+	select {
+	case <-submitted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("队列有空位后 Submit 应返回")
+	}
+	shutdown(t, d)
+}
+
+func TestShutdownDrainsQueue(t *testing.T) {
+	var handled int32
+	d := New(func(update tgbotapi.Update) {
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&handled, 1)
+	}, 2, 100)
+
+	for i := 0; i < 30; i++ {
+		d.Submit(chatUpdate(i, int64(i%4)))
+	}
+	shutdown(t, d)
+
+	if handled != 30 {
+		t.Errorf("关闭前只处理了 %d 条更新，期望 30 条", handled)
+	}
+	if d.Submit(chatUpdate(99, 1)) {
+		t.Error("关闭后 Submit 应返回 false")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	d := New(func(update tgbotapi.Update) { <-release }, 1, 10)
+	d.Submit(chatUpdate(1, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown 返回 %v，期望超时", err)
+	}
+}
+
+func TestPanicDoesNotStopWorker(t *testing.T) {
+	var handled int32
+	d := New(func(update tgbotapi.Update) {
+		if update.UpdateID == 1 {
+			panic("boom")
+		}
+		atomic.AddInt32(&handled, 1)
+	}, 1, 10)
+
+	d.Submit(chatUpdate(1, 1))
+	d.Submit(chatUpdate(2, 1))
+	shutdown(t, d)
+
+	if handled != 1 {
+		t.Errorf("panic 之后的更新应继续处理，处理数 = %d", handled)
+	}
+}
+
+func TestChatKey(t *testing.T) {
+	cases := []struct {
+		name   string
+		update tgbotapi.Update
+		want   int64
+	}{
+		{"群消息按聊天", tgbotapi.Update{Message: &tgbotapi.Message{From: &tgbotapi.User{ID: 7}, Chat: &tgbotapi.Chat{ID: -100}}}, -100},
+		{"回调按消息所在聊天", tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From: &tgbotapi.User{ID: 7}, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -100}},
+		}}, -100},
+		{"inline 消息的回调按用户", tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{From: &tgbotapi.User{ID: 7}}}, 7},
+		{"inline 查询按用户", tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: &tgbotapi.User{ID: 7}}}, 7},
+		{"空更新", tgbotapi.Update{}, 0},
+	}
+	for _, tc := range cases {
+		if got := chatKey(tc.update); got != tc.want {
+			t.Errorf("%s: chatKey = %d，期望 %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+// waitFor 等待条件成立，最多 2 秒
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待超时")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
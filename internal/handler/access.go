@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/config"
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/internal/router"
+	"github.com/user/fish-music/pkg/i18n"
+	"gorm.io/gorm"
+)
+
+const (
+	// inviteCodeAlphabet 邀请码字符，去掉了容易混淆的 0/O、1/I/L
+	inviteCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	// inviteCodeLength 邀请码长度
+	inviteCodeLength = 8
+	// inviteDefaultDays 邀请码默认有效天数
+	inviteDefaultDays = 7
+	// inviteMaxUses 单个邀请码最多使用次数
+	inviteMaxUses = 1000
+	// inviteMaxDays 邀请码最长有效天数
+	inviteMaxDays = 365
+	// inviteListLimit /invite list 最多显示的邀请码数
+	inviteListLimit = 20
+)
+
+// isMember 开放模式下所有人都可以使用；白名单和邀请制下只允许获准的用户和管理员
+func (h *BotHandler) isMember(c *router.Context) bool {
+	return h.botConfig.Access == config.AccessOpen || c.User.Member || c.User.HasRole(model.RoleAdmin)
+}
+
+// onNotMember 邀请制下处理 /start <邀请码>，其他请求礼貌拒绝
+func (h *BotHandler) onNotMember(c *router.Context) error {
+	tr := h.tr(c.User)
+	switch {
+	case c.CallbackQuery != nil:
+		return h.answerCallback(c.CallbackQuery, tr.T("access.denied_short"), true)
+	case c.Message == nil:
+		return nil
+	}
+
+	message := c.Message
+	if h.botConfig.Access == config.AccessInvite && message.Chat.IsPrivate() && message.Command() == "start" {
+		if code := strings.TrimSpace(message.CommandArguments()); code != "" {
+			return h.redeemInvite(message, c.User, code)
+		}
+	}
+
+	key := "access.whitelist_denied"
+	if h.botConfig.Access == config.AccessInvite {
+		key = "access.invite_denied"
+	}
+	return h.sendHTML(message.Chat.ID, tr.T(key, i18n.Params{"ID": c.User.TelegramID}))
+}
+
+// redeemInvite 使用邀请码加入，成功后发送欢迎信息
+func (h *BotHandler) redeemInvite(message *tgbotapi.Message, user *model.User, code string) error {
+	tr := h.tr(user)
+	code = strings.ToUpper(code)
+	if len(code) > inviteCodeLength*4 {
+		return h.sendHTML(message.Chat.ID, tr.T("access.invite_invalid"))
+	}
+
+	_, err := h.inviteRepo.Redeem(code, user.ID)
+	switch {
+	case errors.Is(err, database.ErrInviteNotFound):
+		return h.sendHTML(message.Chat.ID, tr.T("access.invite_invalid"))
+	case errors.Is(err, database.ErrInviteExpired):
+		return h.sendHTML(message.Chat.ID, tr.T("access.invite_expired"))
+	case errors.Is(err, database.ErrInviteUsedUp):
+		return h.sendHTML(message.Chat.ID, tr.T("access.invite_used_up"))
+	case err != nil:
+		return err
+	}
+	user.Member = true
+
+	if err := h.sendHTML(message.Chat.ID, tr.T("access.joined")); err != nil {
+		return err
+	}
+	return h.sendHTML(message.Chat.ID, tr.T("start.welcome"))
+}
+
+// cmdInvite 管理邀请码
+//
+//	/invite                生成一个单次使用、7 天有效的邀请码
+//	/invite <次数> [天数]  生成可使用多次的邀请码，天数为 0 表示不过期
+//	/invite list           查看可用的邀请码
+//	/invite revoke <邀请码> 作废邀请码
+func (h *BotHandler) cmdInvite(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	args := strings.Fields(message.CommandArguments())
+
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "list":
+			return h.sendInviteList(message.Chat.ID, tr)
+		case "revoke":
+			if len(args) != 2 {
+				return h.sendHTML(message.Chat.ID, tr.T("access.invite_usage"))
+			}
+			code := strings.ToUpper(args[1])
+			revoked, err := h.inviteRepo.Revoke(code)
+			if err != nil {
+				return err
+			}
+			if !revoked {
+				return h.sendHTML(message.Chat.ID, tr.T("access.invite_invalid"))
+			}
+			return h.sendHTML(message.Chat.ID, tr.T("access.invite_revoked", i18n.Params{"Code": code}))
+		}
+	}
+
+	uses, days := 1, inviteDefaultDays
+	if len(args) > 2 {
+		return h.sendHTML(message.Chat.ID, tr.T("access.invite_usage"))
+	}
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > inviteMaxUses {
+			return h.sendHTML(message.Chat.ID, tr.T("access.invite_usage"))
+		}
+		uses = n
+	}
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 || n > inviteMaxDays {
+			return h.sendHTML(message.Chat.ID, tr.T("access.invite_usage"))
+		}
+		days = n
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return err
+	}
+	invite := &model.InviteCode{
+		Code:      code,
+		CreatedBy: user.ID,
+		MaxUses:   uses,
+	}
+	if days > 0 {
+		expiresAt := time.Now().AddDate(0, 0, days)
+		invite.ExpiresAt = &expiresAt
+	}
+	if err := h.inviteRepo.Create(invite); err != nil {
+		return err
+	}
+
+	text := tr.T("access.invite_created", i18n.Params{
+		"Code":    code,
+		"Link":    h.startLink(code),
+		"Details": h.inviteDetails(tr, invite),
+	})
+	if h.botConfig.Access != config.AccessInvite {
+		text += "\n\n" + tr.T("access.invite_mode_hint")
+	}
+	return h.sendHTML(message.Chat.ID, text)
+}
+
+// sendInviteList 发送可用的邀请码列表
+func (h *BotHandler) sendInviteList(chatID int64, tr *i18n.Localizer) error {
+	invites, err := h.inviteRepo.FindActive(inviteListLimit)
+	if err != nil {
+		return err
+	}
+	if len(invites) == 0 {
+		return h.sendHTML(chatID, tr.T("access.invite_list_empty"))
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("access.invite_list_title"))
+	for _, invite := range invites {
+		creator := ""
+		if invite.Creator != nil {
+			creator = html.EscapeString(invite.Creator.GetFullName())
+		}
+		text.WriteString("\n")
+		text.WriteString(tr.T("access.invite_list_item", i18n.Params{
+			"Code":    invite.Code,
+			"Details": h.inviteDetails(tr, &invite),
+			"Creator": creator,
+		}))
+	}
+	return h.sendHTML(chatID, text.String())
+}
+
+// inviteDetails 邀请码的剩余次数和有效期
+func (h *BotHandler) inviteDetails(tr *i18n.Localizer, invite *model.InviteCode) string {
+	uses := tr.N("access.invite_uses_left", int64(invite.RemainingUses()))
+	if invite.ExpiresAt == nil {
+		return uses + " · " + tr.T("access.invite_no_expiry")
+	}
+	return uses + " · " + tr.T("access.invite_expires", i18n.Params{"Date": invite.ExpiresAt.Format("2006-01-02 15:04")})
+}
+
+// cmdAllow 允许用户使用 Bot（白名单）：/allow 用户
+func (h *BotHandler) cmdAllow(message *tgbotapi.Message, user *model.User) error {
+	return h.setMember(message, user, true)
+}
+
+// cmdDisallow 取消用户的使用权限：/disallow 用户
+func (h *BotHandler) cmdDisallow(message *tgbotapi.Message, user *model.User) error {
+	return h.setMember(message, user, false)
+}
+
+// setMember 修改用户是否获准使用，管理员始终可以使用
+func (h *BotHandler) setMember(message *tgbotapi.Message, actor *model.User, member bool) error {
+	tr := h.tr(actor)
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 1 {
+		return h.sendHTML(message.Chat.ID, tr.T("access.member_usage"))
+	}
+
+	target, err := h.findUserByRef(args[0])
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return h.sendHTML(message.Chat.ID, tr.T("roles.user_not_found", i18n.Params{"User": html.EscapeString(args[0])}))
+	}
+	if err != nil {
+		return err
+	}
+	if !member && target.HasRole(model.RoleAdmin) {
+		return h.sendHTML(message.Chat.ID, tr.T("access.staff_always"))
+	}
+
+	if target.Member != member {
+		if err := h.userRepo.UpdateMember(target.ID, member); err != nil {
+			return err
+		}
+		if member {
+			// 对方可能已停用 Bot，通知失败不影响结果
+			if err := h.sendHTML(target.TelegramID, h.tr(target).T("access.allowed_notice")); err != nil {
+				log.Printf("通知用户 %d 失败: %v", target.TelegramID, err)
+			}
+		}
+	}
+
+	key := "access.disallowed"
+	if member {
+		key = "access.allowed"
+	}
+	text := tr.T(key, i18n.Params{"Name": html.EscapeString(target.GetFullName())})
+	if h.botConfig.Access == config.AccessOpen {
+		text += "\n\n" + tr.T("access.open_hint")
+	}
+	return h.sendHTML(message.Chat.ID, text)
+}
+
+// canUseInline inline 查询不经过加载用户，在这里按访问模式和封禁状态检查
+// 开放模式下没有和 Bot 说过话的用户也可以使用
+func (h *BotHandler) canUseInline(from *tgbotapi.User) bool {
+	user, err := h.userRepo.FindByTelegramID(from.ID)
+	if err != nil {
+		return h.botConfig.Access == config.AccessOpen
+	}
+	if user.IsBanned() {
+		return false
+	}
+	return h.botConfig.Access == config.AccessOpen || user.Member || user.HasRole(model.RoleAdmin)
+}
+
+// newInviteCode 生成随机邀请码
+func newInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成邀请码失败: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/user/fish-music/internal/config"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/internal/router"
+)
+
+func TestNewInviteCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := newInviteCode()
+		if err != nil {
+			t.Fatalf("newInviteCode 返回错误: %v", err)
+		}
+		if len(code) != inviteCodeLength {
+			t.Fatalf("邀请码 %q 长度应为 %d", code, inviteCodeLength)
+		}
+		for _, r := range code {
+			if !strings.ContainsRune(inviteCodeAlphabet, r) {
+				t.Fatalf("邀请码 %q 包含不允许的字符 %q", code, r)
+			}
+		}
+		if seen[code] {
+			t.Fatalf("邀请码 %q 重复", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestIsMember(t *testing.T) {
+	cases := []struct {
+		access string
+		user   model.User
+		want   bool
+	}{
+		{config.AccessOpen, model.User{Role: model.RoleListener}, true},
+		{config.AccessInvite, model.User{Role: model.RoleListener}, false},
+		{config.AccessInvite, model.User{Role: model.RoleListener, Member: true}, true},
+		{config.AccessWhitelist, model.User{Role: model.RoleUploader}, false},
+		{config.AccessWhitelist, model.User{Role: model.RoleAdmin}, true},
+		{config.AccessWhitelist, model.User{Role: model.RoleOwner}, true},
+	}
+	for _, tc := range cases {
+		h := &BotHandler{botConfig: &config.BotConfig{Access: tc.access}}
+		user := tc.user
+		if got := h.isMember(&router.Context{User: &user}); got != tc.want {
+			t.Errorf("%s 模式下 %+v: isMember = %v，期望 %v", tc.access, tc.user, got, tc.want)
+		}
+	}
+}
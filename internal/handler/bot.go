@@ -2,6 +2,8 @@ package handler
 
 import (
 	"fmt"
+	"html"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
@@ -9,200 +11,108 @@ import (
 	"github.com/user/fish-music/internal/config"
 	"github.com/user/fish-music/internal/database"
 	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/internal/router"
 	"github.com/user/fish-music/internal/service"
 	"github.com/user/fish-music/pkg/api"
+	"github.com/user/fish-music/pkg/i18n"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // BotHandler Bot 处理器
 type BotHandler struct {
-	bot            *tgbotapi.BotAPI
-	adminID        int64
-	songRepo       *database.SongRepository
-	userRepo       *database.UserRepository
-	favoriteRepo   *database.FavoriteRepository
-	historyRepo    *database.HistoryRepository
-	musicAPI       *api.NeteaseAPI
-	ytdlpService   *service.YTDLPService
-	downloadConfig *config.DownloadConfig
+	bot              *tgbotapi.BotAPI
+	adminID          int64
+	botConfig        *config.BotConfig
+	songRepo         *database.SongRepository
+	userRepo         *database.UserRepository
+	favoriteRepo     *database.FavoriteRepository
+	historyRepo      *database.HistoryRepository
+	groupRepo        *database.GroupSettingRepository
+	playlistRepo     *database.PlaylistRepository
+	shareRepo        *database.ShareRepository
+	queueRepo        *database.QueueRepository
+	artistRepo       *database.ArtistRepository
+	settingsRepo     *database.UserSettingRepository
+	inviteRepo       *database.InviteRepository
+	musicAPI         *api.NeteaseAPI
+	ytdlpService     *service.YTDLPService
+	recommendService *service.RecommendService
+	lyricsService    *service.LyricsService
+	statsService     *service.StatsService
+	chartService     *service.ChartService
+	broadcastService *service.BroadcastService
+	locales          *i18n.Bundle
+	downloadConfig   *config.DownloadConfig
+	groupConfig      *config.GroupConfig
+	logger           *slog.Logger
+	metrics          *router.Metrics
+	router           *router.Router
+	searchPages      *searchCache
 }
 
 // NewBotHandler 创建 Bot 处理器
 func NewBotHandler(
 	bot *tgbotapi.BotAPI,
-	adminID int64,
+	botConfig *config.BotConfig,
 	songRepo *database.SongRepository,
 	userRepo *database.UserRepository,
 	favoriteRepo *database.FavoriteRepository,
 	historyRepo *database.HistoryRepository,
+	groupRepo *database.GroupSettingRepository,
+	playlistRepo *database.PlaylistRepository,
+	shareRepo *database.ShareRepository,
+	queueRepo *database.QueueRepository,
+	artistRepo *database.ArtistRepository,
+	settingsRepo *database.UserSettingRepository,
+	inviteRepo *database.InviteRepository,
 	musicAPI *api.NeteaseAPI,
 	ytdlpService *service.YTDLPService,
+	recommendService *service.RecommendService,
+	lyricsService *service.LyricsService,
+	statsService *service.StatsService,
+	chartService *service.ChartService,
+	broadcastService *service.BroadcastService,
+	locales *i18n.Bundle,
 	downloadConfig *config.DownloadConfig,
+	groupConfig *config.GroupConfig,
+	logger *slog.Logger,
 ) *BotHandler {
-	return &BotHandler{
-		bot:            bot,
-		adminID:        adminID,
-		songRepo:       songRepo,
-		userRepo:       userRepo,
-		favoriteRepo:   favoriteRepo,
-		historyRepo:    historyRepo,
-		musicAPI:       musicAPI,
-		ytdlpService:   ytdlpService,
-		downloadConfig: downloadConfig,
-	}
-}
-
-// HandlePrivateMessage 处理私聊消息
-func (h *BotHandler) HandlePrivateMessage(update tgbotapi.Update) error {
-	message := update.Message
-	if message == nil {
-		return nil
-	}
-
-	// 获取或创建用户
-	user, err := h.userRepo.FindOrCreate(
-		message.From.ID,
-		message.From.UserName,
-		message.From.FirstName,
-		message.From.LastName,
-	)
-	if err != nil {
-		return fmt.Errorf("获取用户失败: %w", err)
-	}
-
-	// 处理命令
-	if message.IsCommand() {
-		return h.handleCommand(message, user)
-	}
-
-	// 处理搜索关键词
-	return h.handleSearch(message, user)
-}
-
-// handleCommand 处理命令
-func (h *BotHandler) handleCommand(message *tgbotapi.Message, user *model.User) error {
-	switch message.Command() {
-	case "start":
-		return h.cmdStart(message, user)
-	case "help":
-		return h.cmdHelp(message, user)
-	case "history":
-		return h.cmdHistory(message, user)
-	case "favorites", "favs":
-		return h.cmdFavorites(message, user)
-	case "random":
-		return h.cmdRandom(message, user)
-	case "songs", "list":
-		return h.cmdSongs(message, user)
-	case "stats":
-		return h.cmdStats(message, user)
-	case "add":
-		return h.cmdAdd(message, user)
-	case "cookies":
-		return h.cmdCookies(message, user)
-	default:
-		return h.cmdUnknown(message, user)
-	}
+	h := &BotHandler{
+		bot:              bot,
+		adminID:          botConfig.AdminID,
+		botConfig:        botConfig,
+		songRepo:         songRepo,
+		userRepo:         userRepo,
+		favoriteRepo:     favoriteRepo,
+		historyRepo:      historyRepo,
+		groupRepo:        groupRepo,
+		playlistRepo:     playlistRepo,
+		shareRepo:        shareRepo,
+		queueRepo:        queueRepo,
+		artistRepo:       artistRepo,
+		settingsRepo:     settingsRepo,
+		inviteRepo:       inviteRepo,
+		musicAPI:         musicAPI,
+		ytdlpService:     ytdlpService,
+		recommendService: recommendService,
+		lyricsService:    lyricsService,
+		statsService:     statsService,
+		chartService:     chartService,
+		broadcastService: broadcastService,
+		locales:          locales,
+		downloadConfig:   downloadConfig,
+		groupConfig:      groupConfig,
+		logger:           logger,
+		metrics:          router.NewMetrics(),
+		searchPages:      newSearchCache(),
+	}
+	h.router = h.newRouter()
+	return h
 }
 
 // cmdAdd 添加音乐命令
 func (h *BotHandler) cmdAdd(message *tgbotapi.Message, user *model.User) error {
-	text := `📥 <b>如何添加音乐到 Fish Music</b>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>⭐ 方法一：YouTube 自动下载（最推荐）</b>
-
-只需发送 YouTube 链接，自动下载并保存！
-
-<b>支持的链接格式：</b>
-• https://www.youtube.com/watch?v=xxxxx
-• https://youtu.be/xxxxx
-
-<b>使用步骤：</b>
-1. 📺 在 YouTube 找到音乐视频
-2. 📋 复制视频链接
-3. 💬 直接发送给机器人
-4. ⏳ 等待 1-3 分钟自动下载
-5. ✅ 下载完成，自动保存！
-
-<b>提示：</b>
-• 可以下载任何 YouTube 音乐视频
-• 自动提取音频为 MP3 格式
-• 自动识别歌手和歌曲信息
-• 单个文件最大 50MB
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>⭐⭐ 方法二：直接发送 MP3 文件</b>
-
-<b>100% 成功率，最可靠的方式！</b>
-
-<b>使用步骤：</b>
-1. 📱 在 Telegram 点击发送文件
-2. 🎵 选择 MP3 音频文件
-3. 💬 发送给机器人
-4. ✅ 立即保存成功！
-
-<b>获取 MP3 的方法：</b>
-• 使用在线 YouTube 转 MP3 工具
-• 从电脑已有的音乐库选择
-• 从其他音乐平台下载后发送
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>⭐⭐⭐ 方法三：手动添加 File ID</b>
-
-如果你有 Telegram 文件的 File ID，可以手动添加。
-
-<b>命令格式：</b>
-<code>/add [歌曲名] [歌手名] [File_ID]</code>
-
-<b>示例：</b>
-<code>/add 稻香 周杰伦 AwADBwADgAD...</code>
-
-<b>如何获取 File ID：</b>
-1. 向机器人 @GetPublicIdBot 发送音频文件
-2. 机器人会返回 File ID
-3. 复制 File ID 使用上面的命令添加
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>💡 推荐使用方案</b>
-
-<b>最佳方案：YouTube 自动下载</b>
-• ✅ 全自动，最方便
-• ✅ 自动识别歌曲信息
-• ⚠️ 需要等待 1-3 分钟
-• ⚠️ 部分 YouTube 视频可能下载失败
-
-<b>最稳方案：发送 MP3 文件</b>
-• ✅ 100% 成功率
-• ✅ 秒速保存
-• ✅ 不受平台限制
-• ⚠️ 需要先获取 MP3 文件
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>❓ 常见问题</b>
-
-Q: YouTube 下载失败怎么办？
-A: 建议使用在线工具转换为 MP3 后发送给我
-
-Q: 可以下载其他平台的视频吗？
-A: 目前主要支持 YouTube，其他平台可能不稳定
-
-Q: 下载需要多久？
-A: 通常 1-3 分钟，取决于视频大小和网络速度
-
-Q: 有文件大小限制吗？
-A: 单个文件最大 50MB
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>🎉 现在就开始添加音乐吧！</b>
-
-直接发送 YouTube 链接试试吧！ 🎵`
+	text := h.tr(user).T("add.guide")
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ParseMode = "HTML"
@@ -211,39 +121,19 @@ A: 单个文件最大 50MB
 }
 
 // cmdStart 开始命令
+// 支持深度链接参数：t.me/<bot>?start=<payload>
 func (h *BotHandler) cmdStart(message *tgbotapi.Message, user *model.User) error {
-	text := `🎵 <b>欢迎来到 Fish Music</b>
-
-你的个人云端音乐库，基于 Telegram 无限存储空间！
-
-<b>🚀 快速开始</b>
-• 发送歌曲名或歌手名搜索音乐
-• 发送 YouTube 链接自动下载音乐 ⭐
-• 直接发送 MP3 文件保存
-
-<b>📱 主要功能</b>
-• <b>/songs</b> - 浏览音乐库 ⭐ 新功能
-• <b>/random</b> - 随机播放一首歌
-• <b>/favorites</b> - 我的收藏列表
-• <b>/history</b> - 播放历史记录
-• <b>/stats</b> - 音乐库统计
-• <b>/add</b> - 添加音乐教程
-• <b>/cookies</b> - 配置 YouTube 下载 ⭐ 新功能
-
-<b>🌟 特色功能</b>
-✅ YouTube 自动下载 - 发链接即可
-✅ 元数据自动识别 - 歌手/地区/年份
-✅ 收藏和历史 - 永久记录
-✅ 无限存储 - 基于 Telegram 云端
-✅ 歌曲分类 - 类型/语言筛选
-
-<b>❓ YouTube 下载失败？</b>
-发送 /cookies 查看配置教程
-
-💡 <b>小技巧</b>
-在任何群组中输入 @BotName 关键词 也能搜索！
+	payload := strings.TrimSpace(message.CommandArguments())
+	switch {
+	case strings.HasPrefix(payload, "s_"):
+		return h.startSharedSong(message, user, strings.TrimPrefix(payload, "s_"))
+	case strings.HasPrefix(payload, "pl_"):
+		return h.startSharedPlaylist(message, user, strings.TrimPrefix(payload, "pl_"))
+	case payload == "add":
+		return h.cmdAdd(message, user)
+	}
 
-需要帮助？使用 /help 查看完整指南`
+	text := h.tr(user).T("start.welcome")
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ParseMode = "HTML"
@@ -253,109 +143,7 @@ func (h *BotHandler) cmdStart(message *tgbotapi.Message, user *model.User) error
 
 // cmdHelp 帮助命令
 func (h *BotHandler) cmdHelp(message *tgbotapi.Message, user *model.User) error {
-	text := `📖 <b>Fish Music 完全使用指南</b>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>🎵 搜索与播放音乐</b>
-
-<b>方式一：搜索歌曲</b>
-直接发送歌曲名或歌手名
-例如：<code>周杰伦 稻香</code>
-
-<b>方式二：群组内搜索</b>
-在任何群组输入：<code>@BotName 歌曲名</code>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>📥 添加音乐的三种方式</b>
-
-<b>⭐ 方式一：YouTube 自动下载（推荐）</b>
-1. 在 YouTube 找到音乐视频
-2. 复制链接发送给我
-3. 自动下载并保存到库中！
-
-支持的链接格式：
-• https://www.youtube.com/watch?v=xxx
-• https://youtu.be/xxx
-
-<b>⭐⭐ 方式二：直接发送 MP3 文件</b>
-1. 在 Telegram 选择发送文件
-2. 选择 MP3 音频文件
-3. 发送给我即可保存
-
-<b>⭐⭐⭐ 方式三：手动添加 File ID</b>
-使用 <code>/add [歌名] [歌手] [File_ID]</code>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>📱 所有命令列表</b>
-
-<b>/start</b> - 查看欢迎信息
-<b>/help</b> - 显示本帮助信息
-<b>/songs</b> 或 <b>/list</b> - 浏览音乐库 ⭐ 新功能
-<b>/random</b> - 随机播放一首歌
-<b>/favorites</b> 或 <b>/favs</b> - 收藏列表
-<b>/history</b> - 播放历史（最近20首）
-<b>/stats</b> - 音乐库统计数据
-<b>/add</b> - 添加音乐详细教程
-<b>/cookies</b> - 配置 YouTube 下载 ⭐ 新功能
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>✨ 功能亮点</b>
-
-❤️ <b>收藏功能</b>
-播放歌曲时点击 ❤️ 按钮即可收藏
-随时查看收藏列表，不会丢失
-
-📜 <b>历史记录</b>
-自动记录所有播放过的歌曲
-支持查看最近 20 首播放记录
-
-🎲 <b>随机播放</b>
-不知道听什么？试试随机播放
-发现音乐库中的惊喜
-
-🌍 <b>智能元数据</b>
-• 自动识别歌手地区（国家 Emoji）
-• 显示发行年份
-• 完整的歌曲信息展示
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>💡 使用技巧</b>
-
-1. <b>批量添加</b>：可以连续发送多个链接
-2. <b>收藏整理</b>：喜欢的歌及时收藏
-3. <b>搜索技巧</b>：歌名+歌手搜索更准确
-4. <b>群组分享</b>：在任何群组都能搜索播放
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>❓ 常见问题</b>
-
-Q: YouTube 下载失败怎么办？
-A: 如果显示 "Sign in to confirm you're not a bot" 错误：
-   1. 发送 <code>/cookies</code> 查看配置教程
-   2. 按提示配置 cookies 即可解决
-   3. 配置后需管理员重启服务
-
-Q: 下载 YouTube 需要多久？
-A: 通常 1-3 分钟，取决于视频大小
-
-Q: 文件大小限制？
-A: 单个文件最大 50MB
-
-Q: 音乐会占用手机空间吗？
-A: 不会！存储在 Telegram 云端
-
-Q: 可以在电脑上用吗？
-A: 可以！Telegram 桌面版同样支持
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-如有问题或建议，请联系管理员 🎵`
+	text := h.tr(user).T("help.text")
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ParseMode = "HTML"
@@ -370,17 +158,9 @@ func (h *BotHandler) cmdHistory(message *tgbotapi.Message, user *model.User) err
 		return err
 	}
 
+	tr := h.tr(user)
 	if len(songs) == 0 {
-		text := `📜 <b>暂无播放历史</b>
-
-你还没有播放过任何歌曲哦～
-
-<b>💡 快速开始：</b>
-• 搜索歌曲：直接发送歌名
-• 随机播放：使用 <code>/random</code>
-• 添加音乐：发送 YouTube 链接
-
-开始播放后，这里会自动记录你的播放历史！`
+		text := tr.T("history.empty")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		msg.ParseMode = "HTML"
 		_, err := h.bot.Send(msg)
@@ -388,8 +168,8 @@ func (h *BotHandler) cmdHistory(message *tgbotapi.Message, user *model.User) err
 	}
 
 	var text strings.Builder
-	text.WriteString("📜 <b>最近播放</b>\n\n")
-	text.WriteString(fmt.Sprintf("显示最近 %d 首播放记录\n\n", len(songs)))
+	text.WriteString(tr.T("history.title") + "\n\n")
+	text.WriteString(tr.N("history.count", int64(len(songs))) + "\n\n")
 
 	for i, song := range songs {
 		emoji := song.GetCountryEmoji()
@@ -399,6 +179,7 @@ func (h *BotHandler) cmdHistory(message *tgbotapi.Message, user *model.User) err
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
 	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(recommendButton(tr)))
 	_, err = h.bot.Send(msg)
 	return err
 }
@@ -410,17 +191,9 @@ func (h *BotHandler) cmdFavorites(message *tgbotapi.Message, user *model.User) e
 		return err
 	}
 
+	tr := h.tr(user)
 	if len(songs) == 0 {
-		text := `⭐ <b>暂无收藏歌曲</b>
-
-你还没有收藏任何歌曲哦～
-
-<b>💡 如何收藏歌曲：</b>
-播放任何歌曲时，点击播放卡片上的 <b>❤️ 收藏</b> 按钮即可！
-
-收藏后的歌曲会永久保存在这里，随时可以查看和播放。
-
-<b>🎵 现在就去搜索喜欢的歌曲吧！</b>`
+		text := tr.T("favorites.empty")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		msg.ParseMode = "HTML"
 		_, err := h.bot.Send(msg)
@@ -428,7 +201,7 @@ func (h *BotHandler) cmdFavorites(message *tgbotapi.Message, user *model.User) e
 	}
 
 	var text strings.Builder
-	text.WriteString(fmt.Sprintf("⭐ <b>我的收藏</b> (共 %d 首)\n\n", len(songs)))
+	text.WriteString(tr.N("favorites.title", int64(len(songs))) + "\n\n")
 
 	for i, song := range songs {
 		emoji := song.GetCountryEmoji()
@@ -438,31 +211,26 @@ func (h *BotHandler) cmdFavorites(message *tgbotapi.Message, user *model.User) e
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
 	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(recommendButton(tr)))
 	_, err = h.bot.Send(msg)
 	return err
 }
 
 // cmdRandom 随机播放命令
+// 带参数时按类型、语言、地区或年代筛选，如 /random 日语、/random 2000s 摇滚
 func (h *BotHandler) cmdRandom(message *tgbotapi.Message, user *model.User) error {
-	song, err := h.songRepo.GetRandom()
-	if err != nil {
-		text := `🎲 <b>音乐库暂无歌曲</b>
-
-音乐库还是空的，添加一些歌曲吧！
-
-<b>📥 添加音乐的方法：</b>
-
-<b>⭐ YouTube 自动下载（推荐）</b>
-发送 YouTube 链接，自动下载音乐
-例如：https://www.youtube.com/watch?v=xxxxx
-
-<b>⭐⭐ 发送 MP3 文件</b>
-直接发送 MP3 文件，秒速保存！
+	if args := strings.TrimSpace(message.CommandArguments()); args != "" {
+		return h.cmdRandomFiltered(message, user, args)
+	}
 
-<b>💡 使用教程：</b>
-发送 <code>/add</code> 查看详细添加教程
+	// 不带参数时使用 /settings 中设置的默认条件
+	if filter := h.userSettings(user).RandomFilter; filter != "" {
+		return h.cmdRandomFiltered(message, user, filter)
+	}
 
-🎵 开始添加你的第一首歌吧！`
+	song, err := h.songRepo.GetRandom()
+	if err != nil {
+		text := h.tr(user).T("random.empty")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		msg.ParseMode = "HTML"
 		_, err := h.bot.Send(msg)
@@ -472,6 +240,24 @@ func (h *BotHandler) cmdRandom(message *tgbotapi.Message, user *model.User) erro
 	return h.sendSong(message.Chat.ID, song, user)
 }
 
+// cmdRandomFiltered 按条件随机播放
+func (h *BotHandler) cmdRandomFiltered(message *tgbotapi.Message, user *model.User, args string) error {
+	q, unknown, err := h.parseStationFilter(args)
+	if err != nil {
+		return err
+	}
+	tr := h.tr(user)
+	if len(unknown) > 0 {
+		return h.sendUnknownCategory(message.Chat.ID, tr, unknown)
+	}
+
+	song, err := h.songRepo.GetRandomFiltered(q)
+	if err != nil {
+		return h.sendHTML(message.Chat.ID, tr.T("random.no_match", i18n.Params{"Filter": html.EscapeString(stationLabel(tr, q))}))
+	}
+	return h.sendSong(message.Chat.ID, song, user)
+}
+
 // cmdStats 统计信息命令
 func (h *BotHandler) cmdStats(message *tgbotapi.Message, user *model.User) error {
 	stats, err := h.songRepo.GetStats()
@@ -479,32 +265,19 @@ func (h *BotHandler) cmdStats(message *tgbotapi.Message, user *model.User) error
 		return err
 	}
 
-	text := fmt.Sprintf(`📊 <b>音乐库统计信息</b>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-🎵 <b>总歌曲数</b>
-   %v 首
-
-🎤 <b>歌手数量</b>
-   %v 位
-
-❌ <b>缺失歌曲</b>
-   %v 首
-
-📅 <b>今日新增</b>
-   %v 首
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
+	shares, opens, err := h.shareRepo.GetStats()
+	if err != nil {
+		return err
+	}
 
-💡 <b>提示：</b>
-缺失的歌曲需要重新补档
-请使用管理后台处理`,
-		stats["total_songs"],
-		stats["total_artists"],
-		stats["missing_songs"],
-		stats["today_added"],
-	)
+	text := h.tr(user).T("stats.library", i18n.Params{
+		"Songs":      stats["total_songs"],
+		"Artists":    stats["total_artists"],
+		"Missing":    stats["missing_songs"],
+		"TodayAdded": stats["today_added"],
+		"Shares":     shares,
+		"Opens":      opens,
+	})
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ParseMode = "HTML"
@@ -514,30 +287,7 @@ func (h *BotHandler) cmdStats(message *tgbotapi.Message, user *model.User) error
 
 // cmdUnknown 未知命令
 func (h *BotHandler) cmdUnknown(message *tgbotapi.Message, user *model.User) error {
-	text := `❓ <b>未知命令</b>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-我不认识这个命令哦～
-
-<b>📱 可用命令列表：</b>
-
-/start - 查看欢迎信息
-/help - 完整使用指南
-/random - 随机播放
-/favorites - 收藏列表
-/history - 播放历史
-/stats - 统计信息
-/add - 添加音乐教程
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>💡 或直接发送：</b>
-• 歌曲名或歌手名搜索
-• YouTube 链接自动下载
-• MP3 文件直接保存
-
-使用 <code>/help</code> 查看完整帮助 🎵`
+	text := h.tr(user).T("unknown.text")
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ParseMode = "HTML"
 	_, err := h.bot.Send(msg)
@@ -553,50 +303,33 @@ func (h *BotHandler) handleSearch(message *tgbotapi.Message, user *model.User) e
 
 	// 检测是否是 URL
 	if strings.HasPrefix(keyword, "http://") || strings.HasPrefix(keyword, "https://") {
-		return h.handleURL(message, keyword)
+		return h.handleURL(message, user, keyword)
 	}
 
-	// 从数据库搜索
-	songs, err := h.songRepo.Search(keyword, 10)
+	// 从数据库搜索第一页，每页数量见用户设置
+	pageSize := h.userSettings(user).SearchLimit
+	songs, total, err := h.songRepo.Search(keyword, 0, pageSize)
 	if err != nil {
 		return err
 	}
 
 	// 如果数据库有结果，直接返回
 	if len(songs) > 0 {
-		return h.sendSearchResults(message.Chat.ID, songs, keyword)
+		text, markup := h.buildSearchResults(h.tr(user), songs, keyword, 0, pageSize, total)
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = markup
+		sent, err := h.bot.Send(msg)
+		if err != nil {
+			return err
+		}
+		// 翻页时按消息找回完整关键词
+		h.searchPages.put(sent.Chat.ID, sent.MessageID, keyword)
+		return nil
 	}
 
 	// 数据库无结果，提示用户如何添加
-	text := fmt.Sprintf(`🔍 <b>未找到相关歌曲</b>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-关键词：<b>%s</b>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>💡 快速添加音乐的方法：</b>
-
-<b>方法一：YouTube 自动下载 ⭐</b>
-直接发送 YouTube 链接，自动下载音乐！
-
-例如：
-• https://www.youtube.com/watch?v=xxxxx
-• https://youtu.be/xxxxx
-
-<b>方法二：发送 MP3 文件 ⭐⭐⭐</b>
-最可靠的方式，100% 成功！
-直接在 Telegram 选择文件发送即可
-
-<b>方法三：查看添加教程</b>
-使用 <code>/add</code> 命令查看详细教程
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>🎵 现在就试试吧！</b>
-
-发送一个 YouTube 链接，或者 MP3 文件～`, keyword)
+	text := h.tr(user).T("search.not_found", i18n.Params{"Keyword": html.EscapeString(keyword)})
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ParseMode = "HTML"
@@ -605,21 +338,18 @@ func (h *BotHandler) handleSearch(message *tgbotapi.Message, user *model.User) e
 }
 
 // handleURL 处理音乐链接
-func (h *BotHandler) handleURL(message *tgbotapi.Message, musicURL string) error {
-	// 获取用户
-	user, err := h.userRepo.FindByTelegramID(message.From.ID)
-	if err != nil {
-		return err
-	}
-
+func (h *BotHandler) handleURL(message *tgbotapi.Message, user *model.User, musicURL string) error {
 	// 检测是否是支持的视频平台
 	if h.isSupportedVideoPlatform(musicURL) {
+		if !user.HasRole(model.RoleUploader) {
+			return h.sendHTML(message.Chat.ID, h.tr(user).T("roles.download_denied"))
+		}
 		// 使用 yt-dlp 下载
 		return h.ytdlpService.DownloadAndSave(message.Chat.ID, musicURL, user)
 	}
 
 	// 其他平台，提示用户
-	return h.handleUnsupportedPlatform(message, musicURL)
+	return h.handleUnsupportedPlatform(message, user, musicURL)
 }
 
 // isSupportedVideoPlatform 检查是否支持的视频平台
@@ -646,36 +376,8 @@ func (h *BotHandler) isSupportedVideoPlatform(url string) bool {
 }
 
 // handleUnsupportedPlatform 处理不支持的平台
-func (h *BotHandler) handleUnsupportedPlatform(message *tgbotapi.Message, musicURL string) error {
-	text := fmt.Sprintf(`📋 <b>收到链接</b>
-
-%s
-
-<b>💡 支持的平台：</b>
-
-🎬 <b>视频平台</b>
-• YouTube: youtube.com
-• Bilibili: bilibili.com
-• 其他 yt-dlp 支持的平台
-
-🎵 <b>音乐平台</b>
-• 网易云音乐
-• QQ音乐、酷狗等
-
-<b>✅ 推荐方法：</b>
-
-1. <b>YouTube/B站</b>
-   直接发送视频链接，我会自动提取音频！
-
-2. <b>网易云等</b>
-   • 发送链接获取歌曲信息
-   • 然后手动下载 MP3 发给我
-
-3. <b>直接发送 MP3</b>
-   最简单可靠的方式！
-
----
-💡 提示：支持的平台会自动下载并添加到音乐库`, musicURL)
+func (h *BotHandler) handleUnsupportedPlatform(message *tgbotapi.Message, user *model.User, musicURL string) error {
+	text := h.tr(user).T("url.unsupported", i18n.Params{"URL": html.EscapeString(musicURL)})
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ParseMode = "HTML"
@@ -683,15 +385,19 @@ func (h *BotHandler) handleUnsupportedPlatform(message *tgbotapi.Message, musicU
 	return err
 }
 
-// sendSearchResults 发送搜索结果（带分页）
-func (h *BotHandler) sendSearchResults(chatID int64, songs []*model.Song, keyword string) error {
+// buildSearchResults 构建搜索结果消息（带分页）
+func (h *BotHandler) buildSearchResults(tr *i18n.Localizer, songs []*model.Song, keyword string, page, pageSize int, total int64) (string, tgbotapi.InlineKeyboardMarkup) {
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	offset := page * pageSize
+
 	var text strings.Builder
-	text.WriteString(fmt.Sprintf("🔍 <b>搜索结果</b>：%s\n\n", keyword))
+	text.WriteString(tr.T("search.title", i18n.Params{"Keyword": html.EscapeString(keyword)}) + "\n")
+	text.WriteString(tr.N("search.summary", total, i18n.Params{"Page": page + 1, "Pages": totalPages}) + "\n\n")
 
 	for i, song := range songs {
 		emoji := song.GetCountryEmoji()
 		year := song.GetYearText()
-		text.WriteString(fmt.Sprintf("%d. %s <b>%s</b> - %s (%s)\n", i+1, emoji, song.Title, song.Artist, year))
+		text.WriteString(fmt.Sprintf("%d. %s <b>%s</b> - %s (%s)\n", offset+i+1, emoji, html.EscapeString(song.Title), html.EscapeString(song.Artist), year))
 	}
 
 	// 创建 Inline Keyboard
@@ -700,7 +406,7 @@ func (h *BotHandler) sendSearchResults(chatID int64, songs []*model.Song, keywor
 
 	for i, song := range songs {
 		btn := tgbotapi.NewInlineKeyboardButtonData(
-			fmt.Sprintf("%d. %s - %s", i+1, truncateString(song.Artist, 15), truncateString(song.Title, 20)),
+			fmt.Sprintf("%d. %s - %s", offset+i+1, truncateString(song.Artist, 15), truncateString(song.Title, 20)),
 			fmt.Sprintf("play_%d", song.ID),
 		)
 		row = append(row, btn)
@@ -712,12 +418,50 @@ func (h *BotHandler) sendSearchResults(chatID int64, songs []*model.Song, keywor
 		}
 	}
 
-	msg := tgbotapi.NewMessage(chatID, text.String())
-	msg.ParseMode = "HTML"
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	// 翻页按钮
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.prev_page"), searchPageData(page-1)))
+	}
+	if page+1 < totalPages {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.next_page"), searchPageData(page+1)))
+	}
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
 
-	_, err := h.bot.Send(msg)
-	return err
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
+
+// callbackSearchPage 搜索翻页回调，原地编辑搜索结果消息
+func (h *BotHandler) callbackSearchPage(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	page, ok := parseSearchPageData(query.Data)
+	if !ok {
+		return h.answerCallback(query, tr.T("search.invalid_page"), true)
+	}
+	keyword, ok := h.searchPages.get(query.Message.Chat.ID, query.Message.MessageID)
+	if !ok {
+		return h.answerCallback(query, tr.T("search.expired"), true)
+	}
+
+	pageSize := h.userSettings(user).SearchLimit
+	songs, total, err := h.songRepo.Search(keyword, page*pageSize, pageSize)
+	if err != nil {
+		return h.answerCallback(query, tr.T("search.failed"), true)
+	}
+	if len(songs) == 0 {
+		return h.answerCallback(query, tr.T("search.no_more"), false)
+	}
+
+	text, markup := h.buildSearchResults(tr, songs, keyword, page, pageSize, total)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup)
+	edit.ParseMode = "HTML"
+	if _, err := h.bot.Send(edit); err != nil {
+		return h.answerCallback(query, tr.T("search.page_failed"), true)
+	}
+
+	return h.answerCallback(query, "", false)
 }
 
 // sendSong 发送歌曲
@@ -734,15 +478,8 @@ func (h *BotHandler) sendSong(chatID int64, song *model.Song, user *model.User)
 	isFavorited, _ := h.favoriteRepo.IsFavorited(user.ID, song.ID)
 
 	// 创建操作按钮
-	var keyboard [][]tgbotapi.InlineKeyboardButton
-
-	favoriteBtn := tgbotapi.NewInlineKeyboardButtonData("❤️ 收藏", fmt.Sprintf("fav_%d", song.ID))
-	if isFavorited {
-		favoriteBtn = tgbotapi.NewInlineKeyboardButtonData("💔 取消收藏", fmt.Sprintf("unfav_%d", song.ID))
-	}
-
-	keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{favoriteBtn})
-	audio.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	settings := h.userSettings(user)
+	audio.ReplyMarkup = service.SongKeyboard(h.tr(user), chatID, song.ID, settings, isFavorited)
 
 	// 发送音频
 	_, err := h.bot.Send(audio)
@@ -754,78 +491,56 @@ func (h *BotHandler) sendSong(chatID int64, song *model.Song, user *model.User)
 		return err
 	}
 
-	// 记录历史
-	h.historyRepo.Add(user.ID, song.ID)
+	// 记录历史（用户可在 /settings 中关闭）
+	if settings.RecordHistory {
+		h.historyRepo.Add(user.ID, song.ID)
+	}
 	h.userRepo.UpdateLastSeen(user.ID)
 
 	return nil
 }
 
-// HandleCallback 处理回调查询
-func (h *BotHandler) HandleCallback(query *tgbotapi.CallbackQuery) error {
-	// 获取用户
-	user, err := h.userRepo.FindByTelegramID(query.From.ID)
-	if err != nil {
-		return h.answerCallback(query, "❌ 用户不存在", true)
-	}
-
-	data := query.Data
-
-	// 解析回调数据
-	if strings.HasPrefix(data, "play_") {
-		return h.callbackPlay(query, user)
-	}
-
-	if strings.HasPrefix(data, "fav_") {
-		return h.callbackFavorite(query, user, true)
-	}
-
-	if strings.HasPrefix(data, "unfav_") {
-		return h.callbackFavorite(query, user, false)
-	}
-
-	return h.answerCallback(query, "❌ 未知操作", true)
-}
-
 // callbackPlay 播放回调
 func (h *BotHandler) callbackPlay(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
 	songIDStr := strings.TrimPrefix(query.Data, "play_")
 	songID, err := strconv.ParseUint(songIDStr, 10, 32)
 	if err != nil {
-		return h.answerCallback(query, "❌ 无效的歌曲ID", true)
+		return h.answerCallback(query, tr.T("song.invalid_id"), true)
 	}
 
 	song, err := h.getSongByID(uint(songID))
 	if err != nil {
-		return h.answerCallback(query, "❌ 歌曲不存在", true)
+		return h.answerCallback(query, tr.T("song.not_found"), true)
 	}
 
 	// 发送歌曲到聊天
 	if err := h.sendSong(query.Message.Chat.ID, song, user); err != nil {
-		return h.answerCallback(query, "❌ 发送失败", true)
+		return h.answerCallback(query, tr.T("song.send_failed"), true)
 	}
 
-	return h.answerCallback(query, "✅ 播放成功", false)
+	return h.answerCallback(query, tr.T("song.played"), false)
 }
 
 // callbackFavorite 收藏回调
 func (h *BotHandler) callbackFavorite(query *tgbotapi.CallbackQuery, user *model.User, add bool) error {
+	tr := h.tr(user)
 	songIDStr := strings.TrimPrefix(strings.TrimPrefix(query.Data, "fav_"), "unfav_")
 	songID, err := strconv.ParseUint(songIDStr, 10, 32)
 	if err != nil {
-		return h.answerCallback(query, "❌ 无效的歌曲ID", true)
+		return h.answerCallback(query, tr.T("song.invalid_id"), true)
 	}
 
 	if add {
 		if err := h.favoriteRepo.Add(user.ID, uint(songID)); err != nil {
-			return h.answerCallback(query, "❌ 收藏失败", true)
+			return h.answerCallback(query, tr.T("song.favorite_failed"), true)
 		}
-		return h.answerCallback(query, "❤️ 已收藏", false)
+		return h.answerCallback(query, tr.T("song.favorited"), false)
 	} else {
 		if err := h.favoriteRepo.Remove(user.ID, uint(songID)); err != nil {
-			return h.answerCallback(query, "❌ 取消收藏失败", true)
+			return h.answerCallback(query, tr.T("song.unfavorite_failed"), true)
 		}
-		return h.answerCallback(query, "💔 已取消收藏", false)
+		return h.answerCallback(query, tr.T("song.unfavorited"), false)
 	}
 }
 
@@ -847,6 +562,11 @@ func (h *BotHandler) getSongByID(id uint) (*model.Song, error) {
 	return &song, nil
 }
 
+// startLink 生成带参数的 Bot 深度链接
+func (h *BotHandler) startLink(payload string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", h.bot.Self.UserName, payload)
+}
+
 // truncateString 截断字符串
 func truncateString(s string, maxLen int) string {
 	// 使用 rune 来正确处理 UTF-8 多字节字符
@@ -857,27 +577,41 @@ func truncateString(s string, maxLen int) string {
 	return string(runes[:maxLen]) + "..."
 }
 
+const (
+	// searchPagePrefix 搜索翻页回调前缀，格式 sp_<页码>，关键词见 searchCache
+	searchPagePrefix = "sp_"
+	// callbackDataMaxLen Telegram 回调数据最大字节数
+	callbackDataMaxLen = 64
+)
+
+// searchPageData 生成搜索翻页回调数据
+func searchPageData(page int) string {
+	return fmt.Sprintf("%s%d", searchPagePrefix, page)
+}
+
+// parseSearchPageData 解析搜索翻页回调数据
+func parseSearchPageData(data string) (int, bool) {
+	page, err := strconv.Atoi(strings.TrimPrefix(data, searchPagePrefix))
+	if err != nil || page < 0 {
+		return 0, false
+	}
+	return page, true
+}
+
 // cmdSongs 显示歌曲列表命令
 func (h *BotHandler) cmdSongs(message *tgbotapi.Message, user *model.User) error {
 	// 随机获取最多10首歌曲
+	tr := h.tr(user)
 	songs, err := h.songRepo.GetRandomSongs(10)
 	if err != nil {
-		text := "❌ 获取歌曲列表失败"
+		text := tr.T("songs.failed")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		_, err := h.bot.Send(msg)
 		return err
 	}
 
 	if len(songs) == 0 {
-		text := `🎵 <b>音乐库是空的</b>
-
-还没有任何歌曲哦～
-
-<b>💡 快速添加音乐：</b>
-• 发送 YouTube 链接自动下载
-• 直接发送 MP3 文件
-
-使用 <code>/add</code> 查看详细教程 🎵`
+		text := tr.T("songs.empty")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		msg.ParseMode = "HTML"
 		_, err := h.bot.Send(msg)
@@ -885,8 +619,8 @@ func (h *BotHandler) cmdSongs(message *tgbotapi.Message, user *model.User) error
 	}
 
 	var text strings.Builder
-	text.WriteString(fmt.Sprintf("🎵 <b>音乐库歌曲列表</b>\n\n"))
-	text.WriteString(fmt.Sprintf("随机展示 %d 首歌曲\n\n", len(songs)))
+	text.WriteString(tr.T("songs.title") + "\n\n")
+	text.WriteString(tr.N("songs.shown", int64(len(songs))) + "\n\n")
 
 	for i, song := range songs {
 		emoji := song.GetCountryEmoji()
@@ -900,7 +634,7 @@ func (h *BotHandler) cmdSongs(message *tgbotapi.Message, user *model.User) error
 	}
 
 	text.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	text.WriteString("💡 点击下方按钮播放歌曲")
+	text.WriteString(tr.T("songs.hint"))
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
 	msg.ParseMode = "HTML"
@@ -931,54 +665,14 @@ func (h *BotHandler) cmdSongs(message *tgbotapi.Message, user *model.User) error
 
 // cmdCookies 配置 YouTube Cookies 命令（仅管理员）
 func (h *BotHandler) cmdCookies(message *tgbotapi.Message, user *model.User) error {
-	// 只有管理员可以使用此命令
-	if message.From.ID != h.adminID {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "❌ 此命令仅管理员可用")
-		msg.ParseMode = "HTML"
-		h.bot.Send(msg)
-		return nil
-	}
+	tr := h.tr(user)
 
 	// 获取命令参数
 	args := message.CommandArguments()
 
 	// 如果没有参数，发送使用说明
 	if args == "" {
-		text := `🍪 <b>YouTube Cookies 配置</b>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-<b>步骤 1：获取 Cookie</b>
-
-1️⃣ 打开 https://www.youtube.com 并登录
-2️⃣ 按 F12 打开开发者工具
-3️⃣ 点击顶部的 "Application" 标签
-4️⃣ 左侧展开：Storage → Cookies
-5️⃣ 点击 "https://www.youtube.com"
-6️⃣ 找到以下 Cookie 之一（按优先级）：
-   • <code>__Secure-3PSID</code> ⭐ 最佳
-   • <code>SID</code> ⭐ 推荐
-   • <code>HSID</code> ⭐ 备用
-7️⃣ 双击 "Value" 列复制值（不是 Name！）
-
-⚠️ <b>重要提示：</b>
-• 必须先登录 YouTube
-• 复制的是 Value 列（很长的一串）
-• 如果找不到 youtube.com，试试 google.com
-
-<b>步骤 2：发送给 Bot</b>
-
-<code>/cookies 你的cookie值</code>
-
-<b>示例：</b>
-<code>/cookies CgQihiJ3...（很长的一串）</code>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-💡 配置后需要重启服务：
-<code>docker compose restart bot</code>
-
-📖 <b>详细教程：</b> https://github.com/qqzhoufan/fish_music/blob/main/COOKES.md`
+		text := tr.T("cookies.guide")
 
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		msg.ParseMode = "HTML"
@@ -991,14 +685,14 @@ func (h *BotHandler) cmdCookies(message *tgbotapi.Message, user *model.User) err
 
 	// 验证 cookie 不为空
 	if cookieValue == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "❌ Cookie 值不能为空")
+		msg := tgbotapi.NewMessage(message.Chat.ID, tr.T("cookies.empty"))
 		h.bot.Send(msg)
 		return nil
 	}
 
 	// 验证 cookie 格式（基本检查）
 	if len(cookieValue) < 20 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "❌ Cookie 值格式不正确（太短）\n\n⚠️ 请确保：\n• 复制的是 Value 列（不是 Name）\n• 复制了完整的值\n• 已登录 YouTube")
+		msg := tgbotapi.NewMessage(message.Chat.ID, tr.T("cookies.too_short"))
 		msg.ParseMode = "HTML"
 		h.bot.Send(msg)
 		return nil
@@ -1009,26 +703,13 @@ func (h *BotHandler) cmdCookies(message *tgbotapi.Message, user *model.User) err
 
 	err := os.WriteFile("/app/youtube-cookies.txt", []byte(cookiesContent), 0644)
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("❌ 保存失败：%v", err))
+		msg := tgbotapi.NewMessage(message.Chat.ID, tr.T("cookies.save_failed", i18n.Params{"Error": err}))
 		h.bot.Send(msg)
 		return err
 	}
 
 	// 发送成功消息
-	text := fmt.Sprintf(`✅ <b>Cookie 配置成功！</b>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-Cookie 已保存到服务器。
-
-<b>下一步：</b>
-重启 Bot 服务使配置生效：
-
-<code>docker compose restart bot</code>
-
-━━━━━━━━━━━━━━━━━━━━━━━━━
-
-💡 测试：发送一个 YouTube 链接试试`)
+	text := tr.T("cookies.saved")
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ParseMode = "HTML"
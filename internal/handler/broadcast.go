@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/config"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// broadcastMaxDays active:N 最多可以指定的天数
+const broadcastMaxDays = 3650
+
+// broadcastTarget /broadcast 的发送范围
+type broadcastTarget struct {
+	days int    // 最近 N 天活跃，0 表示不限
+	lang string // 界面语言，空表示不限
+}
+
+// cmdBroadcast 向用户发送公告
+//
+//	/broadcast [active:N] [lang:xx] 内容  发送 HTML 文本公告
+//	回复一条消息发送 /broadcast [active:N] [lang:xx]  原样转发该消息（可以是图片、音频等）
+//	/broadcast status                      查看发送中的公告
+func (h *BotHandler) cmdBroadcast(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	args := message.CommandArguments()
+	if strings.EqualFold(strings.TrimSpace(args), "status") {
+		return h.sendBroadcastStatus(message.Chat.ID, tr)
+	}
+
+	target, text, ok := parseBroadcastArgs(args)
+	if !ok {
+		return h.sendHTML(message.Chat.ID, tr.T("broadcast.usage"))
+	}
+
+	broadcast := &model.Broadcast{
+		CreatedBy:   user.ID,
+		ChatID:      message.Chat.ID,
+		MembersOnly: h.botConfig.Access != config.AccessOpen,
+	}
+	switch reply := message.ReplyToMessage; {
+	case reply != nil && text == "":
+		broadcast.SourceChatID = reply.Chat.ID
+		broadcast.SourceMessageID = reply.MessageID
+	case reply == nil && text != "":
+		broadcast.Text = text
+	default:
+		return h.sendHTML(message.Chat.ID, tr.T("broadcast.usage"))
+	}
+	if target.days > 0 {
+		since := time.Now().AddDate(0, 0, -target.days)
+		broadcast.ActiveSince = &since
+	}
+	if target.lang != "" {
+		lang, found := h.findLanguage(target.lang)
+		if !found {
+			return h.sendHTML(message.Chat.ID, tr.T("broadcast.invalid_language", i18n.Params{
+				"Language": html.EscapeString(target.lang),
+			}))
+		}
+		broadcast.Language = lang
+	}
+
+	if err := h.broadcastService.Create(broadcast); err != nil {
+		return err
+	}
+	if broadcast.Total == 0 {
+		h.broadcastService.Cancel(broadcast.ID)
+		return h.sendHTML(message.Chat.ID, tr.T("broadcast.no_recipients"))
+	}
+	if err := h.broadcastService.Preview(broadcast); err != nil {
+		h.broadcastService.Cancel(broadcast.ID)
+		return h.sendHTML(message.Chat.ID, tr.T("broadcast.preview_failed", i18n.Params{
+			"Error": html.EscapeString(err.Error()),
+		}))
+	}
+
+	confirm := tgbotapi.NewMessage(message.Chat.ID, tr.T("broadcast.confirm", i18n.Params{
+		"Count":  broadcast.Total,
+		"Target": h.broadcastTargetText(tr, target, broadcast.Language),
+	}))
+	confirm.ParseMode = "HTML"
+	confirm.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("broadcast.btn.send"), fmt.Sprintf("bc_send_%d", broadcast.ID)),
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("broadcast.btn.cancel"), fmt.Sprintf("bc_cancel_%d", broadcast.ID)),
+	))
+	_, err := h.bot.Send(confirm)
+	return err
+}
+
+// broadcastTargetText 发送范围的说明，如“最近 30 天活跃 · English”
+func (h *BotHandler) broadcastTargetText(tr *i18n.Localizer, target broadcastTarget, lang string) string {
+	var parts []string
+	if target.days > 0 {
+		parts = append(parts, tr.N("broadcast.target_active", int64(target.days)))
+	}
+	if lang != "" {
+		parts = append(parts, h.languageName(lang))
+	}
+	if len(parts) == 0 {
+		return tr.T("broadcast.target_all")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// sendBroadcastStatus 发送中的公告及进度，每条附带停止按钮
+func (h *BotHandler) sendBroadcastStatus(chatID int64, tr *i18n.Localizer) error {
+	broadcasts, err := h.broadcastService.Sending()
+	if err != nil {
+		return err
+	}
+	if len(broadcasts) == 0 {
+		return h.sendHTML(chatID, tr.T("broadcast.status_empty"))
+	}
+
+	var text strings.Builder
+	var rows [][]tgbotapi.InlineKeyboardButton
+	text.WriteString(tr.T("broadcast.status_title"))
+	for _, b := range broadcasts {
+		text.WriteString("\n")
+		text.WriteString(tr.T("broadcast.status_item", i18n.Params{
+			"ID":        b.ID,
+			"Processed": b.Processed(),
+			"Total":     b.Total,
+			"Sent":      b.Sent,
+			"Blocked":   b.Blocked,
+			"Failed":    b.Failed,
+		}))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("broadcast.btn.stop_id", i18n.Params{"ID": b.ID}), fmt.Sprintf("bc_stop_%d", b.ID)),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// handleBroadcastCallback 处理公告的确认、取消和停止按钮：bc_<动作>_<公告ID>
+func (h *BotHandler) handleBroadcastCallback(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	parts := strings.Split(query.Data, "_")
+	if len(parts) != 3 {
+		return h.answerCallback(query, tr.T("common.unknown_action"), true)
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return h.answerCallback(query, tr.T("common.unknown_action"), true)
+	}
+	broadcastID := uint(id)
+
+	var (
+		done   bool
+		text   string
+		markup *tgbotapi.InlineKeyboardMarkup
+	)
+	switch parts[1] {
+	case "send":
+		done, err = h.broadcastService.Start(broadcastID)
+		text = tr.T("broadcast.started", i18n.Params{"ID": broadcastID})
+		stop := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("broadcast.btn.stop"), fmt.Sprintf("bc_stop_%d", broadcastID)),
+		))
+		markup = &stop
+	case "cancel":
+		done, err = h.broadcastService.Cancel(broadcastID)
+		text = tr.T("broadcast.cancelled", i18n.Params{"ID": broadcastID})
+	case "stop":
+		done, err = h.broadcastService.Cancel(broadcastID)
+		text = tr.T("broadcast.stopped", i18n.Params{"ID": broadcastID})
+	default:
+		return h.answerCallback(query, tr.T("common.unknown_action"), true)
+	}
+	if err != nil {
+		return err
+	}
+	if !done {
+		return h.answerCallback(query, tr.T("broadcast.already_handled"), true)
+	}
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = markup
+	h.bot.Send(edit)
+	return h.answerCallback(query, "", false)
+}
+
+// parseBroadcastArgs 解析开头的 active:N、lang:xx 选项，其余部分原样作为公告内容（保留换行）
+// active 的天数不合法时 ok 为 false
+func parseBroadcastArgs(args string) (target broadcastTarget, text string, ok bool) {
+	rest := strings.TrimLeftFunc(args, unicode.IsSpace)
+	for rest != "" {
+		token := rest
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			token = rest[:i]
+		}
+		key, value, found := strings.Cut(token, ":")
+		if !found || value == "" {
+			break
+		}
+		switch strings.ToLower(key) {
+		case "active":
+			days, err := strconv.Atoi(value)
+			if err != nil || days < 1 || days > broadcastMaxDays {
+				return target, "", false
+			}
+			target.days = days
+		case "lang":
+			target.lang = value
+		default:
+			return target, strings.TrimSpace(rest), true
+		}
+		rest = strings.TrimLeftFunc(rest[len(token):], unicode.IsSpace)
+	}
+	return target, strings.TrimSpace(rest), true
+}
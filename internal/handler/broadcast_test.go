@@ -0,0 +1,37 @@
+package handler
+
+import "testing"
+
+func TestParseBroadcastArgs(t *testing.T) {
+	cases := []struct {
+		args string
+		days int
+		lang string
+		text string
+		ok   bool
+	}{
+		{"新功能上线啦", 0, "", "新功能上线啦", true},
+		{"active:30 lang:en Hello\n\nworld", 30, "en", "Hello\n\nworld", true},
+		{"  lang:zh-TW  第一行\n第二行 ", 0, "zh-TW", "第一行\n第二行", true},
+		{"active:7", 7, "", "", true},
+		{"<b>注意:</b> 维护通知", 0, "", "<b>注意:</b> 维护通知", true},
+		{"https://example.com 新网站", 0, "", "https://example.com 新网站", true},
+		{"active:0 hi", 0, "", "", false},
+		{"active:abc hi", 0, "", "", false},
+		{"", 0, "", "", true},
+	}
+	for _, tc := range cases {
+		target, text, ok := parseBroadcastArgs(tc.args)
+		if ok != tc.ok {
+			t.Errorf("%q: ok = %v，期望 %v", tc.args, ok, tc.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if target.days != tc.days || target.lang != tc.lang || text != tc.text {
+			t.Errorf("%q: 解析为 days=%d lang=%q text=%q，期望 days=%d lang=%q text=%q",
+				tc.args, target.days, target.lang, text, tc.days, tc.lang, tc.text)
+		}
+	}
+}
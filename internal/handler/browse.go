@@ -0,0 +1,386 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+const (
+	// artistPageSize 歌手索引每页显示的歌手数
+	artistPageSize = 20
+	// artistSongPageSize 歌手页面每页显示的歌曲数
+	artistSongPageSize = 10
+)
+
+// artistEntry 歌手索引条目
+type artistEntry struct {
+	database.ArtistCount
+	letter string // 首字母索引（A-Z 或 #）
+}
+
+// cmdArtists 歌手索引命令：/artists [首字母]
+func (h *BotHandler) cmdArtists(message *tgbotapi.Message, user *model.User) error {
+	letter := strings.ToUpper(strings.TrimSpace(message.CommandArguments()))
+	if len([]rune(letter)) > 1 {
+		letter = string([]rune(letter)[:1])
+	}
+
+	text, markup, err := h.buildArtistIndex(h.tr(user), letter, 0)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// getArtistIndex 获取按拼音 / 罗马字字母顺序排列的歌手列表
+func (h *BotHandler) getArtistIndex() ([]artistEntry, error) {
+	artists, err := h.songRepo.GetArtists()
+	if err != nil {
+		return nil, err
+	}
+
+	// 仓库已按排序键排好序，这里只计算首字母
+	entries := make([]artistEntry, 0, len(artists))
+	for _, artist := range artists {
+		letter := "#"
+		if key := artist.SortKey; key != "" && key[0] >= 'a' && key[0] <= 'z' {
+			letter = strings.ToUpper(key[:1])
+		}
+		entries = append(entries, artistEntry{ArtistCount: artist, letter: letter})
+	}
+	return entries, nil
+}
+
+// buildArtistIndex 构建歌手索引页面，letter 非空时只显示该首字母的歌手
+func (h *BotHandler) buildArtistIndex(tr *i18n.Localizer, letter string, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	entries, err := h.getArtistIndex()
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	// 统计出现过的首字母，用于字母跳转
+	var letters []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if !seen[entry.letter] {
+			seen[entry.letter] = true
+			letters = append(letters, entry.letter)
+		}
+	}
+	sort.Strings(letters)
+
+	if letter != "" {
+		var filtered []artistEntry
+		for _, entry := range entries {
+			if entry.letter == letter {
+				filtered = append(filtered, entry)
+			}
+		}
+		entries = filtered
+	}
+
+	totalPages := (len(entries) + artistPageSize - 1) / artistPageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+	start := page * artistPageSize
+	end := start + artistPageSize
+	if end > len(entries) {
+		end = len(entries)
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("browse.artists_title"))
+	if letter != "" {
+		text.WriteString(" · " + html.EscapeString(letter))
+	}
+	text.WriteString("\n" + tr.N("browse.artists_count", int64(len(entries)), i18n.Params{"Page": page + 1, "Pages": totalPages}) + "\n\n")
+	if len(entries) == 0 {
+		text.WriteString(tr.T("browse.artists_empty"))
+	} else {
+		text.WriteString(tr.T("browse.artists_hint"))
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, entry := range entries[start:end] {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s (%d)", truncateString(entry.Name, 16), entry.Count),
+			fmt.Sprintf("ar_%d", entry.ArtistID),
+		))
+		if len(row) == 2 || i == end-start-1 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+
+	// 翻页按钮：ars_<页码>_<首字母>
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.prev_page"), fmt.Sprintf("ars_%d_%s", page-1, letter)))
+	}
+	if page+1 < totalPages {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.next_page"), fmt.Sprintf("ars_%d_%s", page+1, letter)))
+	}
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+
+	// 字母跳转，每行 7 个
+	row = []tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardButtonData(tr.T("browse.all_letters"), "ars_0_")}
+	for _, l := range letters {
+		label := l
+		if l == letter {
+			label = "·" + l + "·"
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, "ars_0_"+l))
+		if len(row) == 7 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboard = append(keyboard, row)
+	}
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// buildArtistView 构建歌手页面：专辑列表和分页的歌曲列表（含合作歌曲）
+func (h *BotHandler) buildArtistView(tr *i18n.Localizer, artistID uint, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	artist, err := h.artistRepo.FindByID(artistID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	songs, err := h.songRepo.GetByArtist(artistID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	if len(songs) == 0 {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("歌手没有歌曲: %s", artist.Name)
+	}
+	albums, err := h.artistRepo.GetAlbums(artistID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	totalPages := (len(songs) + artistSongPageSize - 1) / artistSongPageSize
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+	start := page * artistSongPageSize
+	end := start + artistSongPageSize
+	if end > len(songs) {
+		end = len(songs)
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🎤 <b>%s</b>\n", html.EscapeString(artist.Name)))
+	if len(artist.Aliases) > 0 {
+		aliases := make([]string, 0, len(artist.Aliases))
+		for _, alias := range artist.Aliases {
+			aliases = append(aliases, alias.Alias)
+		}
+		text.WriteString(tr.T("browse.aliases", i18n.Params{"Aliases": html.EscapeString(strings.Join(aliases, " / "))}) + "\n")
+	}
+	text.WriteString(tr.N("browse.song_count", int64(len(songs))) + " · " + tr.N("browse.album_count", int64(len(albums))) + "\n")
+
+	if len(albums) > 0 {
+		text.WriteString("\n" + tr.T("browse.albums") + "\n")
+		for _, album := range albums {
+			text.WriteString("• " + tr.N("browse.album_item", album.SongCount, i18n.Params{"Title": html.EscapeString(album.Title)}) + "\n")
+		}
+	}
+
+	text.WriteString("\n" + tr.T("browse.songs", i18n.Params{"Page": page + 1, "Pages": totalPages}) + "\n")
+	for i, song := range songs[start:end] {
+		album := song.Album
+		if album == "" {
+			album = tr.T("browse.album_unknown")
+		}
+		line := fmt.Sprintf("%d. %s <b>%s</b> · %s", start+i+1, song.GetCountryEmoji(), html.EscapeString(song.Title), html.EscapeString(album))
+		// 合作歌曲标出原唱
+		if !strings.EqualFold(song.Artist, artist.Name) {
+			line += " " + tr.T("browse.original_artist", i18n.Params{"Artist": html.EscapeString(song.Artist)})
+		}
+		text.WriteString(line + "\n")
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+
+	// 专辑按钮，每行 2 个
+	var row []tgbotapi.InlineKeyboardButton
+	for i, album := range albums {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("💿 %s", truncateString(album.Title, 16)),
+			fmt.Sprintf("al_%d", album.ID),
+		))
+		if len(row) == 2 || i == len(albums)-1 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+
+	// 歌曲播放按钮，每行 2 个
+	for i, song := range songs[start:end] {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%d. %s", start+i+1, truncateString(song.Title, 18)),
+			fmt.Sprintf("play_%d", song.ID),
+		))
+		if len(row) == 2 || i == end-start-1 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+
+	// 翻页按钮：ar_<歌手ID>_<页码>
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.prev_page"), fmt.Sprintf("ar_%d_%d", artistID, page-1)))
+	}
+	if page+1 < totalPages {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.next_page"), fmt.Sprintf("ar_%d_%d", artistID, page+1)))
+	}
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("browse.play_artist"), fmt.Sprintf("arp_%d", artistID)),
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("browse.back_to_artists"), "ars_0_"),
+	))
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// buildAlbumView 构建专辑页面
+func (h *BotHandler) buildAlbumView(tr *i18n.Localizer, albumID uint) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	album, err := h.artistRepo.FindAlbumByID(albumID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	songs, err := h.songRepo.GetByAlbum(albumID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("💿 <b>%s</b>\n", html.EscapeString(album.Title)))
+	if album.Artist != nil {
+		text.WriteString("🎤 " + html.EscapeString(album.Artist.Name))
+	}
+	if album.Year > 0 {
+		text.WriteString(" · " + tr.T("browse.album_year", i18n.Params{"Year": album.Year}))
+	}
+	text.WriteString(" · " + tr.N("browse.song_count", int64(len(songs))) + "\n\n")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, song := range songs {
+		text.WriteString(fmt.Sprintf("%d. %s\n", i+1, html.EscapeString(song.Title)))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%d. %s", i+1, truncateString(song.Title, 30)),
+				fmt.Sprintf("play_%d", song.ID),
+			),
+		))
+	}
+
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("browse.play_album"), fmt.Sprintf("alp_%d", album.ID)),
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("browse.back_to_artist"), fmt.Sprintf("ar_%d", album.ArtistID)),
+	))
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// handleBrowseCallback 处理歌手 / 专辑浏览回调
+//
+//	ars_<页码>_<首字母>：歌手索引
+//	ar_<歌手ID>[_<页码>]：歌手页面
+//	arp_<歌手ID>：播放歌手全部歌曲
+//	al_<专辑ID>：专辑页面
+//	alp_<专辑ID>：播放整张专辑
+func (h *BotHandler) handleBrowseCallback(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	if query.Message == nil {
+		return h.answerCallback(query, tr.T("browse.private_only"), true)
+	}
+
+	prefix, rest, _ := strings.Cut(query.Data, "_")
+	args := strings.Split(rest, "_")
+
+	if prefix == "ars" {
+		letter := ""
+		if len(args) > 1 {
+			letter = args[1]
+		}
+		text, markup, err := h.buildArtistIndex(tr, letter, callbackPage(args, 0))
+		if err != nil {
+			return h.answerCallback(query, tr.T("browse.artists_failed"), true)
+		}
+		return h.editBrowseView(query, text, markup)
+	}
+
+	// 其余回调的第一个参数是歌手或专辑 ID
+	id, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return h.answerCallback(query, tr.T("browse.invalid_id"), true)
+	}
+
+	switch prefix {
+	case "ar":
+		text, markup, err := h.buildArtistView(tr, uint(id), callbackPage(args, 1))
+		if err != nil {
+			return h.answerCallback(query, tr.T("browse.artist_failed"), true)
+		}
+		return h.editBrowseView(query, text, markup)
+
+	case "al":
+		text, markup, err := h.buildAlbumView(tr, uint(id))
+		if err != nil {
+			return h.answerCallback(query, tr.T("browse.album_failed"), true)
+		}
+		return h.editBrowseView(query, text, markup)
+
+	case "arp":
+		if err := h.startQueueFrom(query.Message.Chat.ID, user, model.QueueSourceArtist, args[0]); err != nil {
+			return h.answerCallback(query, tr.T("queue.play_failed"), true)
+		}
+		return h.answerCallback(query, "", false)
+
+	case "alp":
+		if err := h.startQueueFrom(query.Message.Chat.ID, user, model.QueueSourceAlbum, args[0]); err != nil {
+			return h.answerCallback(query, tr.T("queue.play_failed"), true)
+		}
+		return h.answerCallback(query, "", false)
+	}
+
+	return h.answerCallback(query, tr.T("common.unknown_action"), true)
+}
+
+// editBrowseView 原地刷新浏览页面
+func (h *BotHandler) editBrowseView(query *tgbotapi.CallbackQuery, text string, markup tgbotapi.InlineKeyboardMarkup) error {
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup)
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+	return h.answerCallback(query, "", false)
+}
+
+// browseCallbackPrefixes 歌手 / 专辑浏览回调前缀
+var browseCallbackPrefixes = []string{"ars", "ar", "arp", "al", "alp"}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// chartTabs 排行榜切换按钮顺序，按钮文字和标题见消息目录的 charts.tab / charts.title
+var chartTabs = []string{
+	database.ChartDay,
+	database.ChartWeek,
+	database.ChartAll,
+	database.ChartTrending,
+	database.ChartNew,
+}
+
+// cmdTop 排行榜命令：/top [day|week|all|trending]，默认本周
+func (h *BotHandler) cmdTop(message *tgbotapi.Message, user *model.User) error {
+	chart := database.ChartWeek
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "day", "today", "今日", "日":
+		chart = database.ChartDay
+	case "all", "总榜", "总":
+		chart = database.ChartAll
+	case "trending", "hot", "飙升":
+		chart = database.ChartTrending
+	case "new", "新歌":
+		chart = database.ChartNew
+	}
+	return h.sendChart(message.Chat.ID, user, chart)
+}
+
+// cmdNew 新歌榜命令：最近入库的歌曲
+func (h *BotHandler) cmdNew(message *tgbotapi.Message, user *model.User) error {
+	return h.sendChart(message.Chat.ID, user, database.ChartNew)
+}
+
+// sendChart 发送排行榜第一页
+func (h *BotHandler) sendChart(chatID int64, user *model.User, chart string) error {
+	tr := h.tr(user)
+	text, markup, err := h.buildChart(tr, chart, 0)
+	if err != nil {
+		log.Printf("获取排行榜失败: %v", err)
+		text = tr.T("charts.failed")
+		if user.HasRole(model.RoleAdmin) {
+			text += "\n\n" + tr.T("charts.migration_hint")
+		}
+		return h.sendHTML(chatID, text)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// handleChartCallback 处理排行榜回调：ch_<榜单>_<页码>，切换榜单或翻页
+func (h *BotHandler) handleChartCallback(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	if query.Message == nil {
+		return h.answerCallback(query, tr.T("charts.private_only"), true)
+	}
+
+	args := strings.Split(query.Data, "_")
+	if len(args) < 2 {
+		return h.answerCallback(query, tr.T("charts.invalid"), true)
+	}
+
+	text, markup, err := h.buildChart(tr, args[1], callbackPage(args, 2))
+	if err != nil {
+		log.Printf("获取排行榜失败: %v", err)
+		return h.answerCallback(query, tr.T("charts.failed_short"), true)
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup)
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+	return h.answerCallback(query, "", false)
+}
+
+// buildChart 构建排行榜页面
+func (h *BotHandler) buildChart(tr *i18n.Localizer, chart string, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	result, err := h.chartService.GetChart(chart, page)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var tabRow []tgbotapi.InlineKeyboardButton
+	for _, tab := range chartTabs {
+		label := tr.T("charts.tab." + tab)
+		if tab == chart {
+			label = "· " + label + " ·"
+		}
+		tabRow = append(tabRow, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("ch_%s_0", tab)))
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("<b>%s</b>", tr.T("charts.title."+chart)))
+	if result.TotalPages > 1 {
+		text.WriteString(tr.T("charts.page", i18n.Params{"Page": result.Page + 1, "Pages": result.TotalPages}))
+	}
+	text.WriteString("\n\n")
+
+	if len(result.Entries) == 0 {
+		if chart == database.ChartNew {
+			text.WriteString(tr.T("charts.empty_new"))
+		} else {
+			text.WriteString(tr.T("charts.empty"))
+		}
+	}
+	for i, entry := range result.Entries {
+		song := entry.Song
+		line := fmt.Sprintf("%s %s <b>%s</b> - %s", chartRank(result.Offset+i+1), song.GetCountryEmoji(),
+			html.EscapeString(song.Title), html.EscapeString(song.Artist))
+		switch chart {
+		case database.ChartNew:
+			line += fmt.Sprintf(" · %s", song.CreatedAt.Format("01-02"))
+		case database.ChartTrending:
+			line += tr.N("charts.plays_today", entry.Plays)
+		default:
+			line += tr.N("charts.plays", entry.Plays)
+		}
+		text.WriteString(line + "\n")
+	}
+
+	if result.RefreshedAt != nil {
+		text.WriteString("\n" + tr.T("charts.updated", i18n.Params{"Time": result.RefreshedAt.Format("01-02 15:04")}))
+	}
+
+	keyboard := [][]tgbotapi.InlineKeyboardButton{tabRow}
+
+	// 歌曲播放按钮，每行 2 个
+	var row []tgbotapi.InlineKeyboardButton
+	for i, entry := range result.Entries {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%d. %s", result.Offset+i+1, truncateString(entry.Song.Title, 18)),
+			fmt.Sprintf("play_%d", entry.Song.ID),
+		))
+		if len(row) == 2 || i == len(result.Entries)-1 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+
+	// 翻页按钮
+	var nav []tgbotapi.InlineKeyboardButton
+	if result.Page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.prev_page"), fmt.Sprintf("ch_%s_%d", chart, result.Page-1)))
+	}
+	if result.Page+1 < result.TotalPages {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.next_page"), fmt.Sprintf("ch_%s_%d", chart, result.Page+1)))
+	}
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// chartRank 排名显示，前三名用奖牌
+func chartRank(rank int) string {
+	switch rank {
+	case 1:
+		return "🥇"
+	case 2:
+		return "🥈"
+	case 3:
+		return "🥉"
+	}
+	return fmt.Sprintf("%d.", rank)
+}
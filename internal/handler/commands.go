@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+)
+
+// commandMenu 命令在哪些 Telegram 命令菜单中显示
+type commandMenu int
+
+const (
+	menuPrivate commandMenu = 1 << iota // 私聊
+	menuGroup                           // 群组
+)
+
+// menuScope Telegram 命令菜单范围及其包含的命令
+type menuScope struct {
+	scope tgbotapi.BotCommandScope
+	menu  commandMenu
+	role  string // 只包含该角色可以使用的命令
+}
+
+// botCommand 命令注册表条目，同时用于注册路由（见 routes.go）和 setMyCommands
+// 菜单说明取自消息目录的 commands.<name>
+type botCommand struct {
+	name    string
+	aliases []string    // 别名，只用于分发，不显示在菜单中
+	menu    commandMenu // 为 0 时不显示在菜单中，但仍可使用
+	role    string      // 私聊使用所需的最低角色，为空表示所有用户
+	private func(h *BotHandler, message *tgbotapi.Message, user *model.User) error
+	group   func(h *BotHandler, message *tgbotapi.Message, user *model.User, setting *model.GroupSetting) error
+}
+
+// botCommands 命令注册表，菜单按此顺序显示
+var botCommands []botCommand
+
+// 角色命令修改角色后需要根据命令表重新注册菜单，直接初始化会形成初始化循环
+func init() {
+	botCommands = []botCommand{
+		{name: "start", private: (*BotHandler).cmdStart, group: (*BotHandler).groupCmdHelp},
+		{name: "help", menu: menuPrivate | menuGroup, private: (*BotHandler).cmdHelp, group: (*BotHandler).groupCmdHelp},
+		{name: "song", aliases: []string{"search"}, menu: menuGroup, group: (*BotHandler).groupCmdSearch},
+		{name: "random", menu: menuPrivate | menuGroup, private: (*BotHandler).cmdRandom, group: (*BotHandler).groupCmdRandom},
+		{name: "radio", menu: menuPrivate, private: (*BotHandler).cmdRadio},
+		{name: "recommend", menu: menuPrivate, private: (*BotHandler).cmdRecommend},
+		{name: "top", menu: menuPrivate, private: (*BotHandler).cmdTop},
+		{name: "new", menu: menuPrivate, private: (*BotHandler).cmdNew},
+		{name: "artists", menu: menuPrivate, private: (*BotHandler).cmdArtists},
+		{name: "favorites", aliases: []string{"favs"}, menu: menuPrivate, private: (*BotHandler).cmdFavorites},
+		{name: "lists", menu: menuPrivate, private: (*BotHandler).cmdLists},
+		{name: "newlist", private: (*BotHandler).cmdNewList},
+		{name: "addto", private: (*BotHandler).cmdAddTo},
+		{name: "playlist", private: (*BotHandler).cmdPlaylist},
+		{name: "queue", menu: menuPrivate, private: (*BotHandler).cmdQueue},
+		{name: "next", private: (*BotHandler).cmdNext},
+		{name: "lyrics", menu: menuPrivate, private: (*BotHandler).cmdLyrics},
+		{name: "history", menu: menuPrivate, private: (*BotHandler).cmdHistory},
+		{name: "mystats", menu: menuPrivate, private: (*BotHandler).cmdMyStats},
+		{name: "recap", menu: menuPrivate, private: (*BotHandler).cmdRecap},
+		{name: "songs", aliases: []string{"list"}, private: (*BotHandler).cmdSongs},
+		{name: "stats", menu: menuPrivate, private: (*BotHandler).cmdStats},
+		{name: "add", menu: menuPrivate, private: (*BotHandler).cmdAdd},
+		{name: "settings", menu: menuPrivate, private: (*BotHandler).cmdSettings},
+		{name: "language", aliases: []string{"lang"}, menu: menuPrivate, private: (*BotHandler).cmdLanguage},
+		{name: "groupset", menu: menuGroup, group: (*BotHandler).groupCmdSettings},
+		{name: "webtoken", menu: menuPrivate, role: model.RoleAdmin, private: (*BotHandler).cmdWebToken},
+		{name: "cookies", menu: menuPrivate, role: model.RoleAdmin, private: (*BotHandler).cmdCookies},
+		{name: "invite", menu: menuPrivate, role: model.RoleAdmin, private: (*BotHandler).cmdInvite},
+		{name: "allow", menu: menuPrivate, role: model.RoleAdmin, private: (*BotHandler).cmdAllow},
+		{name: "disallow", menu: menuPrivate, role: model.RoleAdmin, private: (*BotHandler).cmdDisallow},
+		{name: "broadcast", menu: menuPrivate, role: model.RoleAdmin, private: (*BotHandler).cmdBroadcast},
+		{name: "promote", menu: menuPrivate, role: model.RoleOwner, private: (*BotHandler).cmdPromote},
+		{name: "demote", menu: menuPrivate, role: model.RoleOwner, private: (*BotHandler).cmdDemote},
+		{name: "ban", menu: menuPrivate, role: model.RoleOwner, private: (*BotHandler).cmdBan},
+		{name: "unban", menu: menuPrivate, role: model.RoleOwner, private: (*BotHandler).cmdUnban},
+	}
+}
+
+// RegisterCommands 向 Telegram 注册命令菜单（setMyCommands）
+// 私聊和群组分别使用各自的范围，只包含所有用户可用的命令；
+// 所有者和管理员的私聊单独注册一份包含其可用命令的菜单
+func (h *BotHandler) RegisterCommands() error {
+	scopes := []menuScope{
+		{tgbotapi.NewBotCommandScopeAllPrivateChats(), menuPrivate, model.RoleListener},
+	}
+	if h.groupConfig.Enabled {
+		scopes = append(scopes, menuScope{tgbotapi.NewBotCommandScopeAllGroupChats(), menuGroup, model.RoleListener})
+	} else if err := h.deleteMenu(tgbotapi.NewBotCommandScopeAllGroupChats()); err != nil {
+		log.Printf("清除群组命令菜单失败: %v", err)
+	}
+
+	staff, err := h.userRepo.FindByRoles(model.RoleOwner, model.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("查询管理员失败: %w", err)
+	}
+	ownerFound := false
+	for _, u := range staff {
+		if u.TelegramID == h.adminID {
+			ownerFound = true
+			u.Role = model.RoleOwner
+		}
+		scopes = append(scopes, menuScope{tgbotapi.NewBotCommandScopeChat(u.TelegramID), menuPrivate, u.Role})
+	}
+	if h.adminID != 0 && !ownerFound {
+		// 所有者还没有和 Bot 说过话
+		scopes = append(scopes, menuScope{tgbotapi.NewBotCommandScopeChat(h.adminID), menuPrivate, model.RoleOwner})
+	}
+
+	for _, s := range scopes {
+		if err := h.publishMenu(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateStaffMenu 角色变更后更新用户私聊的命令菜单：
+// 管理员及以上使用单独的菜单，其他用户删除单独的菜单，回到所有私聊的菜单
+func (h *BotHandler) updateStaffMenu(user *model.User) error {
+	scope := tgbotapi.NewBotCommandScopeChat(user.TelegramID)
+	if user.HasRole(model.RoleAdmin) {
+		return h.publishMenu(menuScope{scope, menuPrivate, user.Role})
+	}
+	return h.deleteMenu(scope)
+}
+
+// publishMenu 注册一个范围内的命令菜单，每种语言单独注册一份说明
+// Telegram 的语言代码只有两个字母，同一语言的多个变体（如 zh-CN、zh-TW）以先出现的为准
+func (h *BotHandler) publishMenu(s menuScope) error {
+	for _, lang := range h.menuLanguages() {
+		commands := h.menuCommands(lang.name, s.menu, s.role)
+		// 默认语言同时注册为不带语言代码的版本，供其他语言的客户端使用
+		if lang.name == h.locales.DefaultLanguage() {
+			if _, err := h.bot.Request(tgbotapi.NewSetMyCommandsWithScope(s.scope, commands...)); err != nil {
+				return fmt.Errorf("注册命令菜单失败 (%s): %w", s.scope.Type, err)
+			}
+		}
+		if _, err := h.bot.Request(tgbotapi.NewSetMyCommandsWithScopeAndLanguage(s.scope, lang.code, commands...)); err != nil {
+			return fmt.Errorf("注册命令菜单失败 (%s, %s): %w", s.scope.Type, lang.code, err)
+		}
+	}
+	return nil
+}
+
+// deleteMenu 删除一个范围内所有语言的命令菜单
+func (h *BotHandler) deleteMenu(scope tgbotapi.BotCommandScope) error {
+	if _, err := h.bot.Request(tgbotapi.NewDeleteMyCommandsWithScope(scope)); err != nil {
+		return err
+	}
+	for _, lang := range h.menuLanguages() {
+		if _, err := h.bot.Request(tgbotapi.NewDeleteMyCommandsWithScopeAndLanguage(scope, lang.code)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// menuLanguage 注册菜单使用的语言及其两个字母的语言代码
+type menuLanguage struct {
+	name string
+	code string
+}
+
+// menuLanguages 需要注册菜单的语言，每个语言代码只保留先出现的变体
+func (h *BotHandler) menuLanguages() []menuLanguage {
+	var langs []menuLanguage
+	published := make(map[string]bool)
+	for _, lang := range h.locales.Languages() {
+		code, _, _ := strings.Cut(strings.ToLower(lang), "-")
+		if published[code] {
+			continue
+		}
+		published[code] = true
+		langs = append(langs, menuLanguage{lang, code})
+	}
+	return langs
+}
+
+// menuCommands 某个菜单范围内、指定角色可以使用的命令列表
+func (h *BotHandler) menuCommands(lang string, menu commandMenu, role string) []tgbotapi.BotCommand {
+	tr := h.locales.Localizer(lang)
+	var commands []tgbotapi.BotCommand
+	for _, cmd := range botCommands {
+		if cmd.menu&menu == 0 {
+			continue
+		}
+		if cmd.role != "" && !model.RoleAtLeast(role, cmd.role) {
+			continue
+		}
+		commands = append(commands, tgbotapi.BotCommand{
+			Command:     cmd.name,
+			Description: tr.T("commands." + cmd.name),
+		})
+	}
+	return commands
+}
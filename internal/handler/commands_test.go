@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/user/fish-music/internal/locales"
+	"github.com/user/fish-music/internal/model"
+)
+
+func TestMenuCommandsByRole(t *testing.T) {
+	bundle, err := locales.Load()
+	if err != nil {
+		t.Fatalf("加载消息目录失败: %v", err)
+	}
+	h := &BotHandler{locales: bundle}
+
+	names := func(role string) string {
+		var list []string
+		for _, cmd := range h.menuCommands(bundle.DefaultLanguage(), menuPrivate, role) {
+			list = append(list, cmd.Command)
+		}
+		return " " + strings.Join(list, " ") + " "
+	}
+
+	cases := []struct {
+		role    string
+		include []string
+		exclude []string
+	}{
+		{model.RoleListener, []string{"help", "settings"}, []string{"webtoken", "cookies", "promote"}},
+		{model.RoleUploader, []string{"help"}, []string{"webtoken", "cookies", "promote"}},
+		{model.RoleAdmin, []string{"help", "webtoken", "cookies"}, []string{"promote", "ban"}},
+		{model.RoleOwner, []string{"help", "cookies", "promote", "demote", "ban", "unban"}, nil},
+	}
+	for _, tc := range cases {
+		menu := names(tc.role)
+		for _, name := range tc.include {
+			if !strings.Contains(menu, " "+name+" ") {
+				t.Errorf("%s 的菜单缺少 /%s: %s", tc.role, name, menu)
+			}
+		}
+		for _, name := range tc.exclude {
+			if strings.Contains(menu, " "+name+" ") {
+				t.Errorf("%s 的菜单不应包含 /%s: %s", tc.role, name, menu)
+			}
+		}
+	}
+}
+
+func TestMenuDescriptions(t *testing.T) {
+	bundle, err := locales.Load()
+	if err != nil {
+		t.Fatalf("加载消息目录失败: %v", err)
+	}
+	h := &BotHandler{locales: bundle}
+
+	for _, lang := range bundle.Languages() {
+		for _, cmd := range h.menuCommands(lang, menuPrivate|menuGroup, model.RoleOwner) {
+			if cmd.Description == "" || strings.HasPrefix(cmd.Description, "commands.") {
+				t.Errorf("%s 缺少 /%s 的菜单说明", lang, cmd.Command)
+			}
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// groupCmdSearch 群内搜索：/song 歌名
+func (h *BotHandler) groupCmdSearch(message *tgbotapi.Message, user *model.User, setting *model.GroupSetting) error {
+	return h.handleGroupQuery(message, user, setting, strings.TrimSpace(message.CommandArguments()))
+}
+
+// groupCmdRandom 群内随机播放
+func (h *BotHandler) groupCmdRandom(message *tgbotapi.Message, user *model.User, setting *model.GroupSetting) error {
+	song, err := h.songRepo.GetRandom()
+	if err != nil {
+		return h.sendGroupNotice(message, setting, h.tr(user).T("group.library_empty"))
+	}
+	return h.sendSong(message.Chat.ID, song, user)
+}
+
+// groupCmdSettings 群组设置：/groupset
+func (h *BotHandler) groupCmdSettings(message *tgbotapi.Message, user *model.User, setting *model.GroupSetting) error {
+	return h.cmdGroupSettings(message, h.tr(user), setting)
+}
+
+// groupCmdHelp 群内帮助
+func (h *BotHandler) groupCmdHelp(message *tgbotapi.Message, user *model.User, setting *model.GroupSetting) error {
+	return h.sendGroupNotice(message, setting, h.groupHelpText(h.tr(user)))
+}
+
+// handleGroupQuery 处理群内搜索或下载请求
+func (h *BotHandler) handleGroupQuery(message *tgbotapi.Message, user *model.User, setting *model.GroupSetting, keyword string) error {
+	tr := h.tr(user)
+	if keyword == "" {
+		return h.sendGroupNotice(message, setting, h.groupHelpText(tr))
+	}
+
+	// 链接：按用户角色和群组设置检查下载权限
+	if strings.HasPrefix(keyword, "http://") || strings.HasPrefix(keyword, "https://") {
+		if !user.HasRole(model.RoleUploader) {
+			return h.sendGroupNotice(message, setting, tr.T("roles.download_denied"))
+		}
+		allowed, reason := h.canDownloadInGroup(message, tr, setting)
+		if !allowed {
+			return h.sendGroupNotice(message, setting, reason)
+		}
+		if !h.isSupportedVideoPlatform(keyword) {
+			return h.sendGroupNotice(message, setting, tr.T("group.unsupported_url"))
+		}
+		return h.ytdlpService.DownloadAndSave(message.Chat.ID, keyword, user)
+	}
+
+	limit := h.groupConfig.ResultLimit
+	if limit <= 0 {
+		limit = 5
+	}
+
+	songs, total, err := h.songRepo.Search(keyword, 0, limit)
+	if err != nil {
+		return err
+	}
+	if len(songs) == 0 {
+		return h.sendGroupNotice(message, setting, tr.T("group.not_found", i18n.Params{
+			"Keyword": html.EscapeString(keyword),
+		}))
+	}
+
+	// 紧凑列表：每首一行，按钮只显示序号
+	var text strings.Builder
+	text.WriteString(tr.N("group.results", total, i18n.Params{"Keyword": html.EscapeString(keyword)}) + "\n")
+
+	var row []tgbotapi.InlineKeyboardButton
+	for i, song := range songs {
+		text.WriteString(fmt.Sprintf("%d. %s - %s\n", i+1, html.EscapeString(truncateString(song.Title, 20)), html.EscapeString(truncateString(song.Artist, 15))))
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			strconv.Itoa(i+1),
+			fmt.Sprintf("play_%d", song.ID),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+
+	sent, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	h.scheduleCleanup(setting, sent)
+	return nil
+}
+
+// cmdGroupSettings 查看或修改群组设置（仅群管理员）
+// 用法：/groupset download all|admins|off，/groupset cleanup <秒>
+func (h *BotHandler) cmdGroupSettings(message *tgbotapi.Message, tr *i18n.Localizer, setting *model.GroupSetting) error {
+	args := strings.Fields(message.CommandArguments())
+
+	if len(args) == 0 {
+		return h.sendGroupNotice(message, setting, tr.T("group.settings", groupSettingParams(tr, setting)))
+	}
+
+	if !h.isGroupAdmin(message.Chat.ID, message.From.ID) {
+		return h.sendGroupNotice(message, setting, tr.T("group.admin_only"))
+	}
+
+	if len(args) != 2 {
+		return h.sendGroupNotice(message, setting, tr.T("group.usage"))
+	}
+
+	switch args[0] {
+	case "download":
+		switch args[1] {
+		case model.GroupDownloadAll, model.GroupDownloadAdmins, model.GroupDownloadOff:
+			setting.DownloadPolicy = args[1]
+		default:
+			return h.sendGroupNotice(message, setting, tr.T("group.invalid_policy"))
+		}
+	case "cleanup":
+		seconds, err := strconv.Atoi(args[1])
+		if err != nil || seconds < 0 {
+			return h.sendGroupNotice(message, setting, tr.T("group.invalid_cleanup"))
+		}
+		setting.CleanupAfter = seconds
+	default:
+		return h.sendGroupNotice(message, setting, tr.T("group.unknown_setting"))
+	}
+
+	if err := h.groupRepo.Update(setting); err != nil {
+		return err
+	}
+
+	return h.sendGroupNotice(message, setting, tr.T("group.updated", groupSettingParams(tr, setting)))
+}
+
+// groupSettingParams 群组设置页面的参数：下载权限和消息清理时间
+func groupSettingParams(tr *i18n.Localizer, setting *model.GroupSetting) i18n.Params {
+	cleanup := tr.T("group.cleanup_off")
+	if setting.CleanupAfter > 0 {
+		cleanup = tr.N("group.cleanup_after", int64(setting.CleanupAfter))
+	}
+	policy := setting.DownloadPolicy
+	if policy != model.GroupDownloadAll && policy != model.GroupDownloadOff {
+		policy = model.GroupDownloadAdmins
+	}
+	return i18n.Params{
+		"Policy":  tr.T("group.policy." + policy),
+		"Cleanup": cleanup,
+	}
+}
+
+// getGroupSetting 获取群组设置，新群组使用配置文件中的默认值
+func (h *BotHandler) getGroupSetting(chat *tgbotapi.Chat) (*model.GroupSetting, error) {
+	return h.groupRepo.FindOrCreate(chat.ID, chat.Title, model.GroupSetting{
+		DownloadPolicy: h.groupConfig.DownloadPolicy,
+		CleanupAfter:   h.groupConfig.CleanupAfter,
+	})
+}
+
+// canDownloadInGroup 检查成员是否有权在群内触发下载
+func (h *BotHandler) canDownloadInGroup(message *tgbotapi.Message, tr *i18n.Localizer, setting *model.GroupSetting) (bool, string) {
+	switch setting.DownloadPolicy {
+	case model.GroupDownloadAll:
+		return true, ""
+	case model.GroupDownloadOff:
+		return false, tr.T("group.download_off")
+	default:
+		if h.isGroupAdmin(message.Chat.ID, message.From.ID) {
+			return true, ""
+		}
+		return false, tr.T("group.download_admins")
+	}
+}
+
+// isGroupAdmin 检查用户是否为群管理员（Bot 管理员始终视为管理员）
+func (h *BotHandler) isGroupAdmin(chatID, userID int64) bool {
+	if userID == h.adminID {
+		return true
+	}
+
+	member, err := h.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
+	if err != nil {
+		log.Printf("获取群成员信息失败: %v", err)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// isCommandForMe 判断命令是否发给本 Bot（/cmd 或 /cmd@本Bot）
+func (h *BotHandler) isCommandForMe(message *tgbotapi.Message) bool {
+	_, target, found := strings.Cut(message.CommandWithAt(), "@")
+	return !found || strings.EqualFold(target, h.bot.Self.UserName)
+}
+
+// isMentioned 判断消息是否 @ 了本 Bot 或回复了本 Bot 的消息
+func (h *BotHandler) isMentioned(message *tgbotapi.Message) bool {
+	if message.ReplyToMessage != nil && message.ReplyToMessage.From != nil &&
+		message.ReplyToMessage.From.ID == h.bot.Self.ID {
+		return true
+	}
+
+	for _, entity := range message.Entities {
+		if entity.Type == "text_mention" && entity.User != nil && entity.User.ID == h.bot.Self.ID {
+			return true
+		}
+	}
+
+	mention := "@" + h.bot.Self.UserName
+	for _, word := range strings.Fields(message.Text) {
+		if strings.EqualFold(word, mention) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripMention 去掉消息中的 @Bot 提及，返回剩余关键词
+func (h *BotHandler) stripMention(text string) string {
+	mention := "@" + h.bot.Self.UserName
+	var words []string
+	for _, word := range strings.Fields(text) {
+		if !strings.EqualFold(word, mention) {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// sendGroupNotice 以回复形式发送群内提示，并按设置自动清理
+func (h *BotHandler) sendGroupNotice(message *tgbotapi.Message, setting *model.GroupSetting, text string) error {
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyToMessageID = message.MessageID
+
+	sent, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	h.scheduleCleanup(setting, sent)
+	return nil
+}
+
+// scheduleCleanup 到期后删除 Bot 在群内发送的消息（重启后未执行的清理会丢失）
+func (h *BotHandler) scheduleCleanup(setting *model.GroupSetting, sent tgbotapi.Message) {
+	if setting.CleanupAfter <= 0 {
+		return
+	}
+
+	time.AfterFunc(time.Duration(setting.CleanupAfter)*time.Second, func() {
+		if _, err := h.bot.Request(tgbotapi.NewDeleteMessage(sent.Chat.ID, sent.MessageID)); err != nil {
+			log.Printf("清理群消息失败: %v", err)
+		}
+	})
+}
+
+// groupHelpText 群内帮助信息
+func (h *BotHandler) groupHelpText(tr *i18n.Localizer) string {
+	return tr.T("group.help", i18n.Params{"Bot": h.bot.Self.UserName})
+}
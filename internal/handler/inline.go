@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+)
+
+const (
+	// inlinePageSize 每次 inline 查询返回的结果数（Telegram 上限 50）
+	inlinePageSize = 20
+	// inlineCacheTime inline 结果缓存时间（秒）
+	inlineCacheTime = 30
+)
+
+// handleInlineQuery 处理 inline 查询（@bot 关键词），可在任意聊天中分享歌曲
+func (h *BotHandler) handleInlineQuery(query *tgbotapi.InlineQuery) error {
+	if !h.canUseInline(query.From) {
+		_, err := h.bot.Request(tgbotapi.InlineConfig{
+			InlineQueryID: query.ID,
+			Results:       []interface{}{},
+			IsPersonal:    true,
+			CacheTime:     inlineCacheTime,
+		})
+		return err
+	}
+
+	keyword := strings.TrimSpace(query.Query)
+
+	// offset 为上一页返回的 next_offset
+	offset, _ := strconv.Atoi(query.Offset)
+	if offset < 0 {
+		offset = 0
+	}
+
+	songs, total, err := h.songRepo.Search(keyword, offset, inlinePageSize)
+	if err != nil {
+		return fmt.Errorf("inline 搜索失败: %w", err)
+	}
+
+	results := make([]interface{}, 0, len(songs))
+	for _, song := range songs {
+		result := tgbotapi.NewInlineQueryResultCachedAudio(strconv.FormatUint(uint64(song.ID), 10), song.FileID)
+		result.Caption = fmt.Sprintf("🎵 %s - %s\n%s %s", song.Artist, song.Title, song.GetCountryEmoji(), song.GetYearText())
+		results = append(results, result)
+	}
+
+	// 按用户缓存：是否有权访问因人而异（白名单、邀请制、封禁），共享缓存会把结果返回给无权访问的人
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		IsPersonal:    true,
+		CacheTime:     inlineCacheTime,
+	}
+
+	// 还有更多结果时返回下一页偏移量，空字符串表示没有更多
+	if next := offset + len(songs); int64(next) < total {
+		answer.NextOffset = strconv.Itoa(next)
+	}
+
+	// 无结果时引导用户到私聊添加音乐
+	if total == 0 && offset == 0 {
+		// inline 查询不加载用户，按 Telegram 客户端语言显示
+		tr := h.locales.Localizer(h.locales.Match(query.From.LanguageCode))
+		answer.SwitchPMText = tr.T("inline.not_found")
+		answer.SwitchPMParameter = "add"
+	}
+
+	_, err = h.bot.Request(answer)
+	return err
+}
+
+// handleChosenInlineResult 记录用户通过 inline 分享的歌曲
+// 需要在 @BotFather 中开启 /setinlinefeedback 才会收到此更新
+func (h *BotHandler) handleChosenInlineResult(result *tgbotapi.ChosenInlineResult, user *model.User) error {
+	songID, err := strconv.ParseUint(result.ResultID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("无效的歌曲ID: %s", result.ResultID)
+	}
+
+	if h.userSettings(user).RecordHistory {
+		if err := h.historyRepo.Add(user.ID, uint(songID)); err != nil {
+			return fmt.Errorf("记录历史失败: %w", err)
+		}
+	}
+	return h.userRepo.UpdateLastSeen(user.ID)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// tr 获取用户界面语言的翻译器
+func (h *BotHandler) tr(user *model.User) *i18n.Localizer {
+	return h.locales.Localizer(user.Language)
+}
+
+// cmdLanguage 切换界面语言命令：/language [语言]，不带参数时显示语言选择按钮
+func (h *BotHandler) cmdLanguage(message *tgbotapi.Message, user *model.User) error {
+	if arg := strings.TrimSpace(message.CommandArguments()); arg != "" {
+		lang, ok := h.findLanguage(arg)
+		if !ok {
+			return h.sendHTML(message.Chat.ID, h.tr(user).T("language.invalid"))
+		}
+		if err := h.userRepo.UpdateLanguage(user.ID, lang); err != nil {
+			return err
+		}
+		user.Language = lang
+		return h.sendHTML(message.Chat.ID, h.tr(user).T("language.changed", i18n.Params{"Name": h.languageName(lang)}))
+	}
+
+	text, markup := h.buildLanguagePicker(user)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+// callbackLanguage 语言选择按钮：lang_<语言>
+func (h *BotHandler) callbackLanguage(query *tgbotapi.CallbackQuery, user *model.User) error {
+	lang, ok := h.findLanguage(strings.TrimPrefix(query.Data, "lang_"))
+	if !ok {
+		return h.answerCallback(query, h.tr(user).T("language.invalid"), true)
+	}
+	if err := h.userRepo.UpdateLanguage(user.ID, lang); err != nil {
+		return err
+	}
+	user.Language = lang
+
+	if query.Message != nil {
+		text, markup := h.buildLanguagePicker(user)
+		edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup)
+		edit.ParseMode = "HTML"
+		h.bot.Send(edit)
+	}
+	return h.answerCallback(query, h.tr(user).T("language.changed", i18n.Params{"Name": h.languageName(lang)}), false)
+}
+
+// buildLanguagePicker 构建语言选择页面，每种语言用自己的名称显示
+func (h *BotHandler) buildLanguagePicker(user *model.User) (string, tgbotapi.InlineKeyboardMarkup) {
+	tr := h.tr(user)
+	text := tr.T("language.title") + "\n\n" + tr.T("language.current", i18n.Params{"Name": h.languageName(tr.Lang())})
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range h.locales.Languages() {
+		label := h.languageName(lang)
+		if lang == tr.Lang() {
+			label = "· " + label + " ·"
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, "lang_"+lang))
+	}
+	back := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(tr.T("settings.btn.back"), "set_show"))
+	return text, tgbotapi.NewInlineKeyboardMarkup(row, back)
+}
+
+// findLanguage 按语言标签或语言名称查找已支持的语言
+func (h *BotHandler) findLanguage(arg string) (string, bool) {
+	for _, lang := range h.locales.Languages() {
+		if strings.EqualFold(arg, lang) || strings.EqualFold(arg, h.languageName(lang)) {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// languageName 语言的自称，如 English、简体中文
+func (h *BotHandler) languageName(lang string) string {
+	return h.locales.Localizer(lang).T("language.name")
+}
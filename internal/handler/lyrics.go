@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/internal/service"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+const (
+	// lyricsMessageLimit 单条歌词消息的最大长度（Telegram 上限 4096 个 UTF-16 字符，留出余量）
+	lyricsMessageLimit = 3900
+	// lyricsMaxFileSize 上传歌词文件的最大字节数
+	lyricsMaxFileSize = 512 * 1024
+)
+
+// cmdLyrics 歌词命令：/lyrics [歌名或歌曲ID]，不带参数时显示最近播放的歌曲
+func (h *BotHandler) cmdLyrics(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	arg := strings.TrimSpace(message.CommandArguments())
+
+	var song *model.Song
+	if arg == "" {
+		histories, err := h.historyRepo.GetRecentHistory(user.ID, 1)
+		if err != nil || len(histories) == 0 || histories[0].Song == nil {
+			return h.sendHTML(message.Chat.ID, tr.T("lyrics.usage"))
+		}
+		song = histories[0].Song
+	} else {
+		if id, err := strconv.ParseUint(arg, 10, 32); err == nil {
+			song, _ = h.getSongByID(uint(id))
+		}
+		if song == nil {
+			songs, _, err := h.songRepo.Search(arg, 0, 1)
+			if err != nil || len(songs) == 0 {
+				return h.sendHTML(message.Chat.ID, tr.T("lyrics.song_not_found", i18n.Params{
+					"Keyword": html.EscapeString(arg),
+				}))
+			}
+			song = songs[0]
+		}
+	}
+
+	return h.sendLyrics(message.Chat.ID, song, user)
+}
+
+// callbackLyrics 歌曲卡片上的歌词按钮：lyr_<歌曲ID>
+func (h *BotHandler) callbackLyrics(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	if query.Message == nil {
+		return h.answerCallback(query, tr.T("lyrics.private_only"), true)
+	}
+
+	song, err := h.lyricsCallbackSong(query, "lyr_")
+	if err != nil {
+		return h.answerCallback(query, tr.T("song.not_found"), true)
+	}
+
+	h.answerCallback(query, tr.T("lyrics.loading"), false)
+	return h.sendLyrics(query.Message.Chat.ID, song, user)
+}
+
+// callbackLyricsExport 导出 LRC 文件：lyrx_<歌曲ID>
+func (h *BotHandler) callbackLyricsExport(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	if query.Message == nil {
+		return h.answerCallback(query, tr.T("lyrics.private_only"), true)
+	}
+
+	song, err := h.lyricsCallbackSong(query, "lyrx_")
+	if err != nil {
+		return h.answerCallback(query, tr.T("song.not_found"), true)
+	}
+	lyric, err := h.lyricsService.Get(song)
+	if err != nil {
+		return h.answerCallback(query, tr.T("lyrics.not_found_short"), true)
+	}
+
+	doc := tgbotapi.NewDocument(query.Message.Chat.ID, tgbotapi.FileBytes{
+		Name:  lyricsFileName(song),
+		Bytes: []byte(lyric.LRC(song)),
+	})
+	if _, err := h.bot.Send(doc); err != nil {
+		return h.answerCallback(query, tr.T("lyrics.export_failed"), true)
+	}
+	return h.answerCallback(query, "", false)
+}
+
+// lyricsCallbackSong 解析歌词回调中的歌曲
+func (h *BotHandler) lyricsCallbackSong(query *tgbotapi.CallbackQuery, prefix string) (*model.Song, error) {
+	songID, err := strconv.ParseUint(strings.TrimPrefix(query.Data, prefix), 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	return h.getSongByID(uint(songID))
+}
+
+// sendLyrics 发送歌曲歌词，超出长度时拆分为多条消息
+func (h *BotHandler) sendLyrics(chatID int64, song *model.Song, user *model.User) error {
+	h.bot.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+
+	tr := h.tr(user)
+	header := fmt.Sprintf("📝 <b>%s</b> - %s\n\n", html.EscapeString(song.Title), html.EscapeString(song.Artist))
+
+	lyric, err := h.lyricsService.Get(song)
+	if err == service.ErrNoLyrics {
+		text := header + tr.T("lyrics.not_found")
+		if user.HasRole(model.RoleAdmin) {
+			text += "\n\n" + tr.T("lyrics.upload_hint", i18n.Params{"ID": song.ID})
+		}
+		return h.sendHTML(chatID, text)
+	}
+	if err != nil {
+		log.Printf("获取歌词失败: %v", err)
+		return h.sendHTML(chatID, tr.T("lyrics.failed"))
+	}
+
+	footer := "\n\n"
+	if lyric.Synced {
+		footer += tr.T("lyrics.synced")
+	} else {
+		footer += tr.T("lyrics.plain")
+	}
+	if lyric.Source == model.LyricSourceUpload {
+		footer += " · " + tr.T("lyrics.uploaded")
+	}
+
+	lines := make([]string, len(lyric.Lines))
+	for i, line := range lyric.Lines {
+		lines[i] = html.EscapeString(line.Text)
+	}
+	chunks := splitLyrics(lines, lyricsMessageLimit-utf16Len(header)-utf16Len(footer))
+
+	for i, chunk := range chunks {
+		text := chunk
+		if i == 0 {
+			text = header + text
+		}
+		if len(chunks) > 1 {
+			text += "\n\n" + tr.T("lyrics.part", i18n.Params{"Page": i + 1, "Pages": len(chunks)})
+		}
+
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = "HTML"
+		if i == len(chunks)-1 {
+			msg.Text += footer
+			if lyric.Synced {
+				msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(tr.T("lyrics.export"), fmt.Sprintf("lyrx_%d", song.ID)),
+				))
+			}
+		}
+		if _, err := h.bot.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleLyricsUpload 管理员上传 .lrc 歌词文件，说明中填写歌曲 ID（权限检查见 routes.go）
+func (h *BotHandler) handleLyricsUpload(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	caption := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Caption), "/lyrics"))
+	songID, err := strconv.ParseUint(strings.TrimPrefix(caption, "#"), 10, 32)
+	if err != nil {
+		return h.sendHTML(message.Chat.ID, tr.T("lyrics.upload_usage"))
+	}
+	song, err := h.getSongByID(uint(songID))
+	if err != nil {
+		return h.sendHTML(message.Chat.ID, tr.T("lyrics.upload_song_not_found", i18n.Params{"ID": songID}))
+	}
+
+	if message.Document.FileSize > lyricsMaxFileSize {
+		return h.sendHTML(message.Chat.ID, tr.T("lyrics.upload_too_large"))
+	}
+	content, err := h.downloadFile(message.Document.FileID)
+	if err != nil {
+		log.Printf("下载歌词文件失败: %v", err)
+		return h.sendHTML(message.Chat.ID, tr.T("lyrics.upload_download_failed"))
+	}
+
+	lyric, err := h.lyricsService.Upload(song, string(content))
+	if err == service.ErrNoLyrics {
+		return h.sendHTML(message.Chat.ID, tr.T("lyrics.upload_empty"))
+	}
+	if err != nil {
+		log.Printf("保存歌词失败: %v", err)
+		return h.sendHTML(message.Chat.ID, tr.T("lyrics.upload_failed"))
+	}
+
+	key := "lyrics.upload_saved_plain"
+	if lyric.Synced {
+		key = "lyrics.upload_saved_synced"
+	}
+	return h.sendHTML(message.Chat.ID, tr.N(key, int64(len(lyric.Lines)), i18n.Params{
+		"Title":  html.EscapeString(song.Title),
+		"Artist": html.EscapeString(song.Artist),
+	}))
+}
+
+// downloadFile 下载用户发送的文件
+func (h *BotHandler) downloadFile(fileID string) ([]byte, error) {
+	url, err := h.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载文件失败: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, lyricsMaxFileSize))
+}
+
+// isLyricsFile 是否为歌词文件
+func isLyricsFile(doc *tgbotapi.Document) bool {
+	if doc == nil {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(doc.FileName))
+	return ext == ".lrc" || ext == ".txt"
+}
+
+// lyricsFileName 导出的 LRC 文件名
+func lyricsFileName(song *model.Song) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(fmt.Sprintf("%s - %s", song.Artist, song.Title))
+	return name + ".lrc"
+}
+
+// splitLyrics 按行拼接歌词，每段不超过 limit 个 UTF-16 字符
+func splitLyrics(lines []string, limit int) []string {
+	var chunks []string
+	var current []string
+	size := 0
+
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(current, "\n")); text != "" {
+			chunks = append(chunks, text)
+		}
+		current, size = nil, 0
+	}
+
+	for _, line := range lines {
+		for _, part := range splitLongLine(line, limit) {
+			n := utf16Len(part) + 1
+			if size+n > limit {
+				flush()
+			}
+			current = append(current, part)
+			size += n
+		}
+	}
+	flush()
+	return chunks
+}
+
+// splitLongLine 将超过 limit 的单行歌词硬拆分
+func splitLongLine(line string, limit int) []string {
+	if utf16Len(line) < limit {
+		return []string{line}
+	}
+
+	var parts []string
+	var part []rune
+	size := 0
+	for _, r := range line {
+		n := 1
+		if r >= 0x10000 {
+			n = 2 // 代理对
+		}
+		if size+n >= limit {
+			parts = append(parts, string(part))
+			part, size = nil, 0
+		}
+		part = append(part, r)
+		size += n
+	}
+	return append(parts, string(part))
+}
+
+// utf16Len 字符串的 UTF-16 长度（Telegram 按此计算消息长度）
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// 个人统计的时间范围
+const (
+	statsPeriodAll   = "all"
+	statsPeriodYear  = "year"
+	statsPeriodMonth = "month"
+	statsPeriodWeek  = "week"
+)
+
+// statsPeriods 时间范围按钮顺序，按钮文字见消息目录的 mystats.period
+var statsPeriods = []string{
+	statsPeriodAll,
+	statsPeriodYear,
+	statsPeriodMonth,
+	statsPeriodWeek,
+}
+
+// cmdMyStats 个人收听统计命令：/mystats [week|month|year|年份]
+func (h *BotHandler) cmdMyStats(message *tgbotapi.Message, user *model.User) error {
+	arg := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+
+	// 带年份时直接显示年度回顾
+	if year, err := strconv.Atoi(arg); err == nil {
+		return h.sendRecap(message.Chat.ID, user, year)
+	}
+
+	period := statsPeriodAll
+	switch arg {
+	case "year", "今年", "年":
+		period = statsPeriodYear
+	case "month", "本月", "月":
+		period = statsPeriodMonth
+	case "week", "本周", "周":
+		period = statsPeriodWeek
+	}
+
+	text, markup, err := h.buildMyStats(user, period)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// cmdRecap 年度回顾命令：/recap [年份]，默认今年
+func (h *BotHandler) cmdRecap(message *tgbotapi.Message, user *model.User) error {
+	year, err := strconv.Atoi(strings.TrimSpace(message.CommandArguments()))
+	if err != nil {
+		year = h.defaultRecapYear(user)
+	}
+	return h.sendRecap(message.Chat.ID, user, year)
+}
+
+// sendRecap 发送年度回顾
+func (h *BotHandler) sendRecap(chatID int64, user *model.User, year int) error {
+	text, markup, err := h.buildRecap(user, year)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// defaultRecapYear 默认回顾的年份：今年还没有播放记录时取最近有记录的一年
+func (h *BotHandler) defaultRecapYear(user *model.User) int {
+	year := time.Now().Year()
+	years, err := h.statsService.GetYears(user.ID)
+	if err != nil || len(years) == 0 {
+		return year
+	}
+	if last := years[len(years)-1]; last < year {
+		return last
+	}
+	return year
+}
+
+// handleStatsCallback 处理个人统计回调
+//
+//	ms_<时间范围>：切换统计时间范围
+//	rcp_<年份>：年度回顾
+func (h *BotHandler) handleStatsCallback(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	if query.Message == nil {
+		return h.answerCallback(query, tr.T("mystats.private_only"), true)
+	}
+
+	prefix, arg, _ := strings.Cut(query.Data, "_")
+
+	var text string
+	var markup tgbotapi.InlineKeyboardMarkup
+	var err error
+	switch prefix {
+	case "ms":
+		text, markup, err = h.buildMyStats(user, arg)
+	case "rcp":
+		year, convErr := strconv.Atoi(arg)
+		if convErr != nil {
+			return h.answerCallback(query, tr.T("mystats.invalid_year"), true)
+		}
+		text, markup, err = h.buildRecap(user, year)
+	default:
+		return h.answerCallback(query, tr.T("common.unknown_action"), true)
+	}
+	if err != nil {
+		return h.answerCallback(query, tr.T("mystats.failed"), true)
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup)
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+	return h.answerCallback(query, "", false)
+}
+
+// statsPeriodRange 时间范围对应的起止时间
+func statsPeriodRange(period string, now time.Time) (from, to time.Time) {
+	y, m, d := now.Date()
+	switch period {
+	case statsPeriodYear:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, now.Location()), time.Time{}
+	case statsPeriodMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, now.Location()), time.Time{}
+	case statsPeriodWeek:
+		return time.Date(y, m, d, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -6), time.Time{}
+	}
+	return time.Time{}, time.Time{}
+}
+
+// buildMyStats 构建个人统计页面
+func (h *BotHandler) buildMyStats(user *model.User, period string) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	from, to := statsPeriodRange(period, time.Now())
+	stats, err := h.statsService.GetStats(user.ID, from, to)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	tr := h.tr(user)
+	var periodLabel string
+	var periodRow []tgbotapi.InlineKeyboardButton
+	for _, p := range statsPeriods {
+		label := tr.T("mystats.period." + p)
+		if p == period {
+			periodLabel = label
+			label = "· " + label + " ·"
+		}
+		periodRow = append(periodRow, tgbotapi.NewInlineKeyboardButtonData(label, "ms_"+p))
+	}
+	if periodLabel == "" {
+		periodLabel = tr.T("mystats.period." + statsPeriods[0])
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("mystats.title", i18n.Params{"Period": periodLabel}) + "\n\n")
+
+	if stats.Plays == 0 {
+		text.WriteString(tr.T("mystats.empty"))
+	} else {
+		text.WriteString(tr.N("mystats.plays", stats.Plays) + " · " + tr.N("mystats.songs", stats.Songs) + "\n")
+		text.WriteString(tr.T("mystats.listen_time", i18n.Params{"Time": formatListenTime(tr, stats.Seconds)}) + "\n")
+		text.WriteString(tr.N("mystats.active_days", int64(stats.ActiveDays)))
+		if stats.CurrentStreak > 1 {
+			text.WriteString(" · " + tr.N("mystats.current_streak", int64(stats.CurrentStreak)))
+		}
+		text.WriteString(" · " + tr.N("mystats.longest_streak", int64(stats.LongestStreak)) + "\n")
+
+		writeStatRanking(&text, tr, tr.T("mystats.top_artists"), stats.TopArtists)
+		writeStatRanking(&text, tr, tr.T("mystats.top_songs"), stats.TopSongs)
+		writeStatShares(&text, tr, tr.T("mystats.genres"), stats.TopGenres, stats.Plays)
+		writeStatShares(&text, tr, tr.T("mystats.languages"), stats.TopLanguages, stats.Plays)
+	}
+
+	keyboard := [][]tgbotapi.InlineKeyboardButton{
+		periodRow,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("mystats.recap_button"), fmt.Sprintf("rcp_%d", h.defaultRecapYear(user))),
+		),
+	}
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// buildRecap 构建年度回顾页面
+func (h *BotHandler) buildRecap(user *model.User, year int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	recap, err := h.statsService.GetYearRecap(user.ID, year)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	tr := h.tr(user)
+	var text strings.Builder
+	text.WriteString(tr.T("recap.title", i18n.Params{"Year": year}) + "\n\n")
+
+	if recap.Plays == 0 {
+		text.WriteString(tr.T("recap.empty", i18n.Params{"Year": year}))
+	} else {
+		text.WriteString(tr.N("recap.summary", recap.Plays, i18n.Params{
+			"Songs": recap.Songs,
+			"Time":  formatListenTime(tr, recap.Seconds),
+		}) + "\n")
+		if recap.NewSongs > 0 {
+			text.WriteString(tr.N("recap.new_songs", recap.NewSongs) + "\n")
+		}
+		text.WriteString("\n")
+
+		if len(recap.TopArtists) > 0 {
+			top := recap.TopArtists[0]
+			text.WriteString(tr.N("recap.top_artist", top.Plays, i18n.Params{"Name": html.EscapeString(top.Name)}) + "\n")
+		}
+		if len(recap.TopSongs) > 0 {
+			top := recap.TopSongs[0]
+			text.WriteString(tr.N("recap.top_song", top.Plays, i18n.Params{"Name": html.EscapeString(top.Name)}) + "\n")
+		}
+		if len(recap.TopGenres) > 0 {
+			text.WriteString(tr.T("recap.top_genre", i18n.Params{"Name": html.EscapeString(recap.TopGenres[0].Name)}) + "\n")
+		}
+		if len(recap.TopLanguages) > 0 {
+			text.WriteString(tr.T("recap.top_language", i18n.Params{"Name": html.EscapeString(recap.TopLanguages[0].Name)}) + "\n")
+		}
+		if recap.TopMonth > 0 {
+			month := time.Month(recap.TopMonth)
+			text.WriteString(tr.N("recap.top_month", recap.TopMonthPlays, i18n.Params{
+				"Month":     int(month),
+				"MonthName": month.String(),
+			}) + "\n")
+		}
+		if recap.BusiestPlays > 0 {
+			text.WriteString(tr.N("recap.busiest_day", recap.BusiestPlays, i18n.Params{
+				"Month":     int(recap.BusiestDay.Month()),
+				"MonthName": recap.BusiestDay.Month().String(),
+				"Day":       recap.BusiestDay.Day(),
+			}) + "\n")
+		}
+		text.WriteString(tr.T("recap.streak", i18n.Params{
+			"ActiveDays": recap.ActiveDays,
+			"Longest":    recap.LongestStreak,
+		}) + "\n")
+
+		writeStatRanking(&text, tr, tr.T("recap.artist_ranking"), recap.TopArtists)
+		writeStatRanking(&text, tr, tr.T("recap.song_ranking"), recap.TopSongs)
+	}
+
+	// 前后年份切换：只显示有记录的年份
+	var nav []tgbotapi.InlineKeyboardButton
+	if years, err := h.statsService.GetYears(user.ID); err == nil {
+		prev, next := 0, 0
+		for _, y := range years {
+			if y < year {
+				prev = y
+			}
+			if y > year && next == 0 {
+				next = y
+			}
+		}
+		if prev > 0 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("◀️ %d", prev), fmt.Sprintf("rcp_%d", prev)))
+		}
+		if next > 0 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d ▶️", next), fmt.Sprintf("rcp_%d", next)))
+		}
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("mystats.stats_button"), "ms_"+statsPeriodAll),
+	))
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// writeStatRanking 输出排行榜
+func writeStatRanking(text *strings.Builder, tr *i18n.Localizer, title string, counts []database.StatCount) {
+	if len(counts) == 0 {
+		return
+	}
+	text.WriteString(fmt.Sprintf("\n<b>%s</b>\n", title))
+	for i, count := range counts {
+		text.WriteString(tr.N("mystats.ranking_item", count.Plays, i18n.Params{
+			"Rank": i + 1,
+			"Name": html.EscapeString(count.Name),
+		}) + "\n")
+	}
+}
+
+// writeStatShares 输出各项占总播放次数的比例
+func writeStatShares(text *strings.Builder, tr *i18n.Localizer, title string, counts []database.StatCount, total int64) {
+	if len(counts) == 0 || total == 0 {
+		return
+	}
+	parts := make([]string, 0, len(counts))
+	for _, count := range counts {
+		parts = append(parts, fmt.Sprintf("%s %d%%", html.EscapeString(count.Name), count.Plays*100/total))
+	}
+	text.WriteString("\n" + tr.T("mystats.shares", i18n.Params{"Title": title, "Items": strings.Join(parts, " · ")}) + "\n")
+}
+
+// formatListenTime 格式化收听时长
+func formatListenTime(tr *i18n.Localizer, seconds int64) string {
+	hours := seconds / 3600
+	minutes := seconds % 3600 / 60
+	if hours == 0 {
+		return tr.N("mystats.minutes", minutes)
+	}
+	return tr.T("mystats.hours", i18n.Params{"Hours": hours, "Minutes": minutes})
+}
@@ -0,0 +1,663 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+const (
+	// playlistPageSize 歌单每页显示的歌曲数
+	playlistPageSize = 8
+	// playlistNameMaxLen 歌单名称最大长度（字符）
+	playlistNameMaxLen = 50
+)
+
+// playlistRole 用户对歌单的权限
+type playlistRole int
+
+const (
+	playlistRoleNone         playlistRole = iota // 无权访问
+	playlistRoleViewer                           // 通过分享链接查看
+	playlistRoleCollaborator                     // 协作者，可添加歌曲
+	playlistRoleOwner                            // 创建者，可编辑、分享、删除
+)
+
+// getPlaylistRole 获取用户对歌单的权限
+func (h *BotHandler) getPlaylistRole(playlist *model.Playlist, user *model.User) playlistRole {
+	if playlist.UserID == user.ID {
+		return playlistRoleOwner
+	}
+	if playlist.IsCollaborative {
+		if ok, _ := h.playlistRepo.IsCollaborator(playlist.ID, user.ID); ok {
+			return playlistRoleCollaborator
+		}
+	}
+	if playlist.IsShared() {
+		return playlistRoleViewer
+	}
+	return playlistRoleNone
+}
+
+// cmdNewList 创建歌单命令：/newlist 歌单名称
+func (h *BotHandler) cmdNewList(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	name := strings.TrimSpace(message.CommandArguments())
+	if name == "" {
+		return h.sendHTML(message.Chat.ID, tr.T("playlist.new_usage"))
+	}
+	if len([]rune(name)) > playlistNameMaxLen {
+		return h.sendHTML(message.Chat.ID, tr.N("playlist.name_too_long", playlistNameMaxLen))
+	}
+
+	playlist, err := h.playlistRepo.Create(user.ID, name)
+	if err != nil {
+		return err
+	}
+
+	return h.sendHTML(message.Chat.ID, tr.T("playlist.created", i18n.Params{
+		"Name": html.EscapeString(playlist.Name),
+		"ID":   playlist.ID,
+	}))
+}
+
+// cmdLists 我的歌单命令
+func (h *BotHandler) cmdLists(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	playlists, err := h.playlistRepo.GetByUser(user.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(playlists) == 0 {
+		return h.sendHTML(message.Chat.ID, tr.T("playlist.empty_list"))
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.N("playlist.list_title", int64(len(playlists))))
+	text.WriteString("\n\n")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, playlist := range playlists {
+		// 标记他人创建的协作歌单
+		mark := ""
+		if playlist.UserID != user.ID {
+			mark = " 👥"
+		}
+		text.WriteString(tr.N("playlist.list_item", int64(playlist.SongCount), i18n.Params{
+			"ID":   playlist.ID,
+			"Name": html.EscapeString(playlist.Name),
+			"Mark": mark,
+		}))
+		text.WriteString("\n")
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("📂 %s (%d)", truncateString(playlist.Name, 20), playlist.SongCount),
+				fmt.Sprintf("plv_%d_0", playlist.ID),
+			),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// cmdAddTo 将最近播放的歌曲加入歌单：/addto 歌单名称或编号
+func (h *BotHandler) cmdAddTo(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		return h.sendHTML(message.Chat.ID, tr.T("playlist.addto_usage"))
+	}
+
+	playlist, err := h.findUserPlaylist(user, arg)
+	if err != nil {
+		return h.sendHTML(message.Chat.ID, tr.T("playlist.not_found_hint", i18n.Params{"Name": html.EscapeString(arg)}))
+	}
+
+	histories, err := h.historyRepo.GetRecentHistory(user.ID, 1)
+	if err != nil {
+		return err
+	}
+	if len(histories) == 0 || histories[0].Song == nil {
+		return h.sendHTML(message.Chat.ID, tr.T("playlist.no_history"))
+	}
+	song := histories[0].Song
+
+	added, err := h.playlistRepo.AddSong(playlist.ID, song.ID)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return h.sendHTML(message.Chat.ID, tr.T("playlist.song_exists", i18n.Params{
+			"Title":    html.EscapeString(song.Title),
+			"Playlist": html.EscapeString(playlist.Name),
+		}))
+	}
+	return h.sendHTML(message.Chat.ID, tr.T("playlist.song_added", i18n.Params{
+		"Artist":   html.EscapeString(song.Artist),
+		"Title":    html.EscapeString(song.Title),
+		"Playlist": html.EscapeString(playlist.Name),
+	}))
+}
+
+// cmdPlaylist 查看歌单：/playlist 歌单名称或编号
+// 也支持 /playlist rename 编号 新名称
+func (h *BotHandler) cmdPlaylist(message *tgbotapi.Message, user *model.User) error {
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		return h.cmdLists(message, user)
+	}
+
+	if rest, ok := strings.CutPrefix(arg, "rename "); ok {
+		return h.renamePlaylist(message, user, strings.TrimSpace(rest))
+	}
+
+	tr := h.tr(user)
+	playlist, err := h.findUserPlaylist(user, arg)
+	if err != nil {
+		return h.sendHTML(message.Chat.ID, tr.T("playlist.not_found_hint", i18n.Params{"Name": html.EscapeString(arg)}))
+	}
+
+	text, markup, err := h.buildPlaylistView(tr, playlist, 0, false, h.getPlaylistRole(playlist, user))
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// renamePlaylist 重命名歌单：/playlist rename 编号 新名称
+func (h *BotHandler) renamePlaylist(message *tgbotapi.Message, user *model.User, args string) error {
+	tr := h.tr(user)
+	idStr, name, _ := strings.Cut(args, " ")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return h.sendHTML(message.Chat.ID, tr.T("playlist.rename_usage"))
+	}
+	if len([]rune(name)) > playlistNameMaxLen {
+		return h.sendHTML(message.Chat.ID, tr.N("playlist.name_too_long", playlistNameMaxLen))
+	}
+
+	playlist, err := h.findUserPlaylist(user, idStr)
+	if err != nil || playlist.UserID != user.ID {
+		return h.sendHTML(message.Chat.ID, tr.T("playlist.not_found", i18n.Params{"Name": html.EscapeString(idStr)}))
+	}
+
+	if err := h.playlistRepo.Rename(playlist.ID, name); err != nil {
+		return err
+	}
+	return h.sendHTML(message.Chat.ID, tr.T("playlist.renamed", i18n.Params{"Name": html.EscapeString(name)}))
+}
+
+// findUserPlaylist 按编号或名称查找用户自己的歌单或已加入的协作歌单
+func (h *BotHandler) findUserPlaylist(user *model.User, arg string) (*model.Playlist, error) {
+	arg = strings.TrimPrefix(arg, "#")
+	if id, err := strconv.ParseUint(arg, 10, 32); err == nil {
+		playlist, err := h.playlistRepo.FindByID(uint(id))
+		if err != nil {
+			return nil, err
+		}
+		if h.getPlaylistRole(playlist, user) < playlistRoleCollaborator {
+			return nil, fmt.Errorf("歌单不属于当前用户")
+		}
+		return playlist, nil
+	}
+
+	playlists, err := h.playlistRepo.GetByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, playlist := range playlists {
+		if strings.EqualFold(playlist.Name, arg) {
+			return playlist, nil
+		}
+	}
+	return nil, fmt.Errorf("歌单不存在: %s", arg)
+}
+
+// buildPlaylistView 构建歌单页面，editing 为 true 时显示排序和删除按钮（仅创建者）
+// 非创建者只显示播放和复制操作
+func (h *BotHandler) buildPlaylistView(tr *i18n.Localizer, playlist *model.Playlist, page int, editing bool, role playlistRole) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	editing = editing && role == playlistRoleOwner
+
+	songs, total, err := h.playlistRepo.GetSongs(playlist.ID, page*playlistPageSize, playlistPageSize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	totalPages := int((total + playlistPageSize - 1) / playlistPageSize)
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	// 移除歌曲后当前页可能已不存在，回到最后一页
+	if page >= totalPages {
+		return h.buildPlaylistView(tr, playlist, totalPages-1, editing, role)
+	}
+	offset := page * playlistPageSize
+
+	var text strings.Builder
+	text.WriteString(tr.T("playlist.view_title", i18n.Params{
+		"Name": html.EscapeString(playlist.Name),
+		"ID":   playlist.ID,
+	}))
+	text.WriteString("\n")
+	if role != playlistRoleOwner {
+		if owner, err := h.userRepo.FindByID(playlist.UserID); err == nil {
+			text.WriteString(tr.T("playlist.owner", i18n.Params{"Name": html.EscapeString(owner.GetFullName())}))
+			text.WriteString("\n")
+		}
+	}
+	if playlist.IsCollaborative {
+		text.WriteString(tr.T("playlist.collaborative"))
+		text.WriteString("\n")
+	}
+	text.WriteString(tr.N("playlist.view_stats", total, i18n.Params{
+		"Page":  page + 1,
+		"Pages": totalPages,
+	}))
+	text.WriteString("\n\n")
+
+	if total == 0 {
+		text.WriteString(tr.T("playlist.view_empty"))
+	}
+	for i, song := range songs {
+		text.WriteString(fmt.Sprintf("%d. %s <b>%s</b> - %s\n", offset+i+1, song.GetCountryEmoji(), html.EscapeString(song.Title), html.EscapeString(song.Artist)))
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if editing {
+		// 编辑模式：每首一行，上移 / 下移 / 移除
+		for i, song := range songs {
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d. %s", offset+i+1, truncateString(song.Title, 12)), fmt.Sprintf("play_%d", song.ID)),
+				tgbotapi.NewInlineKeyboardButtonData("⬆️", fmt.Sprintf("plmv_%d_%d_u_%d", playlist.ID, song.ID, page)),
+				tgbotapi.NewInlineKeyboardButtonData("⬇️", fmt.Sprintf("plmv_%d_%d_d_%d", playlist.ID, song.ID, page)),
+				tgbotapi.NewInlineKeyboardButtonData("❌", fmt.Sprintf("plrm_%d_%d_%d", playlist.ID, song.ID, page)),
+			))
+		}
+	} else {
+		// 浏览模式：播放按钮，每行 2 个
+		row := []tgbotapi.InlineKeyboardButton{}
+		for i, song := range songs {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%d. %s", offset+i+1, truncateString(song.Title, 20)),
+				fmt.Sprintf("play_%d", song.ID),
+			))
+			if len(row) == 2 || i == len(songs)-1 {
+				keyboard = append(keyboard, row)
+				row = []tgbotapi.InlineKeyboardButton{}
+			}
+		}
+	}
+
+	// 翻页按钮
+	mode := "plv"
+	if editing {
+		mode = "ple"
+	}
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.prev_page"), fmt.Sprintf("%s_%d_%d", mode, playlist.ID, page-1)))
+	}
+	if page+1 < totalPages {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.next_page"), fmt.Sprintf("%s_%d_%d", mode, playlist.ID, page+1)))
+	}
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+
+	// 操作按钮
+	switch {
+	case role != playlistRoleOwner:
+		if total > 0 {
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.play_all"), fmt.Sprintf("plplay_%d", playlist.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.copy"), fmt.Sprintf("plcopy_%d", playlist.ID)),
+			))
+		}
+	case editing:
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.done"), fmt.Sprintf("plv_%d_%d", playlist.ID, page)),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.delete"), fmt.Sprintf("pldel_%d", playlist.ID)),
+		))
+	case total > 0:
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.play_all"), fmt.Sprintf("plplay_%d", playlist.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.edit"), fmt.Sprintf("ple_%d_%d", playlist.ID, page)),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.share"), fmt.Sprintf("plshare_%d", playlist.ID)),
+		))
+	default:
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.share"), fmt.Sprintf("plshare_%d", playlist.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.delete"), fmt.Sprintf("pldel_%d", playlist.ID)),
+		))
+	}
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// handlePlaylistCallback 处理歌单相关回调
+func (h *BotHandler) handlePlaylistCallback(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	prefix, rest, _ := strings.Cut(query.Data, "_")
+	args := strings.Split(rest, "_")
+
+	// pladd_<歌曲ID>：打开歌单选择器
+	if prefix == "pladd" {
+		songID, err := strconv.ParseUint(rest, 10, 32)
+		if err != nil {
+			return h.answerCallback(query, tr.T("playlist.invalid_song"), true)
+		}
+		return h.callbackPlaylistPicker(query, user, uint(songID))
+	}
+
+	// 其余回调的第一个参数都是歌单 ID
+	playlistID, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return h.answerCallback(query, tr.T("playlist.invalid"), true)
+	}
+	playlist, err := h.playlistRepo.FindByID(uint(playlistID))
+	if err != nil {
+		return h.answerCallback(query, tr.T("playlist.missing"), true)
+	}
+
+	// 按操作检查权限
+	role := h.getPlaylistRole(playlist, user)
+	required := playlistRoleOwner
+	switch prefix {
+	case "plv", "plplay", "plcopy":
+		required = playlistRoleViewer
+	case "plput":
+		required = playlistRoleCollaborator
+	}
+	if role < required {
+		if role == playlistRoleNone {
+			return h.answerCallback(query, tr.T("playlist.unavailable"), true)
+		}
+		return h.answerCallback(query, tr.T("playlist.owner_only"), true)
+	}
+
+	switch prefix {
+	case "plput":
+		// plput_<歌单ID>_<歌曲ID>
+		if len(args) != 2 {
+			return h.answerCallback(query, tr.T("common.invalid_action"), true)
+		}
+		songID, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return h.answerCallback(query, tr.T("playlist.invalid_song"), true)
+		}
+		added, err := h.playlistRepo.AddSong(playlist.ID, uint(songID))
+		if err != nil {
+			return h.answerCallback(query, tr.T("playlist.add_failed"), true)
+		}
+		h.bot.Request(tgbotapi.NewDeleteMessage(query.Message.Chat.ID, query.Message.MessageID))
+		if !added {
+			return h.answerCallback(query, tr.T("playlist.already_added"), false)
+		}
+		return h.answerCallback(query, tr.T("playlist.added_to", i18n.Params{"Name": playlist.Name}), false)
+
+	case "plv", "ple":
+		// plv_<歌单ID>_<页码> / ple_<歌单ID>_<页码>
+		return h.editPlaylistView(query, tr, playlist, callbackPage(args, 1), prefix == "ple", role)
+
+	case "plmv":
+		// plmv_<歌单ID>_<歌曲ID>_<u|d>_<页码>
+		if len(args) != 4 {
+			return h.answerCallback(query, tr.T("common.invalid_action"), true)
+		}
+		songID, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return h.answerCallback(query, tr.T("playlist.invalid_song"), true)
+		}
+		delta := 1
+		if args[2] == "u" {
+			delta = -1
+		}
+		if err := h.playlistRepo.MoveSong(playlist.ID, uint(songID), delta); err != nil {
+			return h.answerCallback(query, tr.T("playlist.move_failed"), true)
+		}
+		return h.editPlaylistView(query, tr, playlist, callbackPage(args, 3), true, role)
+
+	case "plrm":
+		// plrm_<歌单ID>_<歌曲ID>_<页码>
+		if len(args) != 3 {
+			return h.answerCallback(query, tr.T("common.invalid_action"), true)
+		}
+		songID, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return h.answerCallback(query, tr.T("playlist.invalid_song"), true)
+		}
+		if err := h.playlistRepo.RemoveSong(playlist.ID, uint(songID)); err != nil {
+			return h.answerCallback(query, tr.T("playlist.remove_failed"), true)
+		}
+		return h.editPlaylistView(query, tr, playlist, callbackPage(args, 2), true, role)
+
+	case "plplay":
+		return h.callbackPlaylistPlay(query, user, playlist)
+
+	case "plcopy":
+		copied, err := h.playlistRepo.Copy(playlist.ID, user.ID, playlist.Name)
+		if err != nil {
+			return h.answerCallback(query, tr.T("playlist.copy_failed"), true)
+		}
+		return h.answerCallback(query, tr.T("playlist.copied", i18n.Params{"Name": copied.Name, "ID": copied.ID}), true)
+
+	case "plshare", "plrevoke", "plcollab":
+		return h.callbackPlaylistShare(query, tr, playlist, prefix)
+
+	case "pldel":
+		// 删除前确认
+		markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.confirm_delete"), fmt.Sprintf("pldelok_%d", playlist.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.cancel"), fmt.Sprintf("plv_%d_0", playlist.ID)),
+		))
+		edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID,
+			tr.N("playlist.delete_confirm", int64(playlist.SongCount), i18n.Params{"Name": html.EscapeString(playlist.Name)}), markup)
+		edit.ParseMode = "HTML"
+		h.bot.Send(edit)
+		return h.answerCallback(query, "", false)
+
+	case "pldelok":
+		if err := h.playlistRepo.Delete(playlist.ID); err != nil {
+			return h.answerCallback(query, tr.T("playlist.delete_failed"), true)
+		}
+		edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID,
+			tr.T("playlist.deleted", i18n.Params{"Name": html.EscapeString(playlist.Name)}))
+		edit.ParseMode = "HTML"
+		h.bot.Send(edit)
+		return h.answerCallback(query, tr.T("playlist.deleted_short"), false)
+	}
+
+	return h.answerCallback(query, tr.T("common.unknown_action"), true)
+}
+
+// callbackPlaylistPicker 显示歌单选择器，选择后将歌曲加入歌单
+func (h *BotHandler) callbackPlaylistPicker(query *tgbotapi.CallbackQuery, user *model.User, songID uint) error {
+	tr := h.tr(user)
+	playlists, err := h.playlistRepo.GetByUser(user.ID)
+	if err != nil {
+		return h.answerCallback(query, tr.T("playlist.load_failed"), true)
+	}
+	if len(playlists) == 0 {
+		return h.answerCallback(query, tr.T("playlist.picker_empty"), true)
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, playlist := range playlists {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("📂 %s (%d)", truncateString(playlist.Name, 20), playlist.SongCount),
+				fmt.Sprintf("plput_%d_%d", playlist.ID, songID),
+			),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, tr.T("playlist.picker_title"))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	if _, err := h.bot.Send(msg); err != nil {
+		return h.answerCallback(query, tr.T("playlist.send_failed"), true)
+	}
+	return h.answerCallback(query, "", false)
+}
+
+// callbackPlaylistPlay 播放整个歌单：以歌单为来源重建播放队列，按顺序逐首播放
+func (h *BotHandler) callbackPlaylistPlay(query *tgbotapi.CallbackQuery, user *model.User, playlist *model.Playlist) error {
+	if err := h.startQueueFrom(query.Message.Chat.ID, user, model.QueueSourcePlaylist, strconv.FormatUint(uint64(playlist.ID), 10)); err != nil {
+		return h.answerCallback(query, h.tr(user).T("playlist.play_failed"), true)
+	}
+	return h.answerCallback(query, "", false)
+}
+
+// callbackPlaylistShare 歌单分享设置：生成链接、撤销链接、切换协作
+func (h *BotHandler) callbackPlaylistShare(query *tgbotapi.CallbackQuery, tr *i18n.Localizer, playlist *model.Playlist, action string) error {
+	switch action {
+	case "plshare":
+		if !playlist.IsShared() {
+			token, err := generateShareToken()
+			if err != nil {
+				return h.answerCallback(query, tr.T("playlist.link_failed"), true)
+			}
+			if err := h.playlistRepo.SetShareToken(playlist.ID, token); err != nil {
+				return h.answerCallback(query, tr.T("playlist.link_failed"), true)
+			}
+			playlist.ShareToken = token
+		}
+	case "plrevoke":
+		if err := h.playlistRepo.SetShareToken(playlist.ID, ""); err != nil {
+			return h.answerCallback(query, tr.T("playlist.revoke_failed"), true)
+		}
+		playlist.ShareToken = ""
+	case "plcollab":
+		if err := h.playlistRepo.SetCollaborative(playlist.ID, !playlist.IsCollaborative); err != nil {
+			return h.answerCallback(query, tr.T("playlist.collab_failed"), true)
+		}
+		playlist.IsCollaborative = !playlist.IsCollaborative
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("playlist.share_title", i18n.Params{"Name": html.EscapeString(playlist.Name)}))
+	text.WriteString("\n\n")
+	if playlist.IsShared() {
+		text.WriteString(tr.T("playlist.share_link", i18n.Params{"Link": h.startLink("pl_" + playlist.ShareToken)}))
+	} else {
+		text.WriteString(tr.T("playlist.share_revoked"))
+	}
+	text.WriteString("\n\n")
+	if playlist.IsCollaborative {
+		text.WriteString(tr.T("playlist.collab_enabled"))
+	} else {
+		text.WriteString(tr.T("playlist.collab_disabled"))
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	if playlist.IsShared() {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.revoke"), fmt.Sprintf("plrevoke_%d", playlist.ID)))
+	} else {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.regenerate"), fmt.Sprintf("plshare_%d", playlist.ID)))
+	}
+	collabText := tr.T("playlist.btn.collab_on")
+	if playlist.IsCollaborative {
+		collabText = tr.T("playlist.btn.collab_off")
+	}
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData(collabText, fmt.Sprintf("plcollab_%d", playlist.ID)))
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(row, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("playlist.btn.back"), fmt.Sprintf("plv_%d_0", playlist.ID)),
+	))
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text.String(), markup)
+	edit.ParseMode = "HTML"
+	edit.DisableWebPagePreview = true
+	h.bot.Send(edit)
+	return h.answerCallback(query, "", false)
+}
+
+// startSharedPlaylist 通过分享链接（/start pl_<token>）打开歌单
+func (h *BotHandler) startSharedPlaylist(message *tgbotapi.Message, user *model.User, token string) error {
+	playlist, err := h.playlistRepo.FindByShareToken(token)
+	if err != nil {
+		return h.sendHTML(message.Chat.ID, h.tr(user).T("playlist.unavailable"))
+	}
+
+	// 协作歌单：打开链接即加入，之后可以在 /lists 中看到并添加歌曲
+	if playlist.IsCollaborative && playlist.UserID != user.ID {
+		if err := h.playlistRepo.AddCollaborator(playlist.ID, user.ID); err != nil {
+			return err
+		}
+	}
+
+	text, markup, err := h.buildPlaylistView(h.tr(user), playlist, 0, false, h.getPlaylistRole(playlist, user))
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// generateShareToken 生成随机分享令牌
+func generateShareToken() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// editPlaylistView 原地刷新歌单页面
+func (h *BotHandler) editPlaylistView(query *tgbotapi.CallbackQuery, tr *i18n.Localizer, playlist *model.Playlist, page int, editing bool, role playlistRole) error {
+	text, markup, err := h.buildPlaylistView(tr, playlist, page, editing, role)
+	if err != nil {
+		return h.answerCallback(query, tr.T("playlist.load_failed"), true)
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup)
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+	return h.answerCallback(query, "", false)
+}
+
+// playlistCallbackPrefixes 歌单相关回调前缀
+var playlistCallbackPrefixes = []string{
+	"pladd", "plput", "plv", "ple", "plmv", "plrm", "plplay", "pldel", "pldelok",
+	"plcopy", "plshare", "plrevoke", "plcollab",
+}
+
+// callbackPage 从回调参数中解析页码，无效时返回 0
+func callbackPage(args []string, index int) int {
+	if index >= len(args) {
+		return 0
+	}
+	page, err := strconv.Atoi(args[index])
+	if err != nil || page < 0 {
+		return 0
+	}
+	return page
+}
+
+// sendHTML 发送 HTML 格式的文本消息
+func (h *BotHandler) sendHTML(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	_, err := h.bot.Send(msg)
+	return err
+}
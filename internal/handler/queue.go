@@ -0,0 +1,586 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+const (
+	// queuePageSize 队列页面每页显示的歌曲数
+	queuePageSize = 10
+	// queueFillSize 每次自动填充的歌曲数
+	queueFillSize = 20
+	// queueHistoryKeep 队列中保留的已播放条目数，超出两倍时清理
+	queueHistoryKeep = 50
+)
+
+// cmdQueue 播放队列命令
+// 用法：/queue 查看队列，/queue fav|all|artist 歌手|list 歌单 从指定来源开始播放，
+// /queue shuffle 切换随机，/queue repeat off|one|all 设置循环，/queue clear 清空
+func (h *BotHandler) cmdQueue(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	action, arg, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch strings.ToLower(action) {
+	case "":
+		return h.sendQueueView(message.Chat.ID, user, 0)
+
+	case "fav", "收藏":
+		return h.startQueueFrom(message.Chat.ID, user, model.QueueSourceFavorites, "")
+
+	case "all", "全库":
+		return h.startQueueFrom(message.Chat.ID, user, model.QueueSourceLibrary, "")
+
+	case "artist", "歌手":
+		if arg == "" {
+			return h.sendHTML(message.Chat.ID, tr.T("queue.artist_usage"))
+		}
+		artist, err := h.artistRepo.FindByName(arg)
+		if err != nil {
+			return h.sendHTML(message.Chat.ID, tr.T("queue.artist_not_found", i18n.Params{
+				"Name": html.EscapeString(arg),
+			}))
+		}
+		return h.startQueueFrom(message.Chat.ID, user, model.QueueSourceArtist, strconv.FormatUint(uint64(artist.ID), 10))
+
+	case "list", "歌单":
+		if arg == "" {
+			return h.sendHTML(message.Chat.ID, tr.T("queue.playlist_usage"))
+		}
+		playlist, err := h.findUserPlaylist(user, arg)
+		if err != nil {
+			return h.sendHTML(message.Chat.ID, tr.T("playlist.not_found_hint", i18n.Params{
+				"Name": html.EscapeString(arg),
+			}))
+		}
+		return h.startQueueFrom(message.Chat.ID, user, model.QueueSourcePlaylist, strconv.FormatUint(uint64(playlist.ID), 10))
+
+	case "shuffle", "随机":
+		queue, err := h.toggleQueueShuffle(user)
+		if err != nil {
+			return err
+		}
+		return h.sendHTML(message.Chat.ID, shuffleText(tr, queue.Shuffle))
+
+	case "repeat", "循环":
+		queue, err := h.queueRepo.Get(user.ID)
+		if err != nil {
+			return err
+		}
+		switch arg {
+		case "":
+			queue.RepeatMode = queue.NextRepeatMode()
+		case model.RepeatOff, model.RepeatOne, model.RepeatAll:
+			queue.RepeatMode = arg
+		default:
+			return h.sendHTML(message.Chat.ID, tr.T("queue.repeat_usage"))
+		}
+		if err := h.queueRepo.Update(queue); err != nil {
+			return err
+		}
+		return h.sendHTML(message.Chat.ID, tr.T("queue.repeat_set", i18n.Params{"Mode": repeatText(tr, queue.RepeatMode)}))
+
+	case "clear", "清空":
+		if err := h.queueRepo.Clear(user.ID); err != nil {
+			return err
+		}
+		return h.sendHTML(message.Chat.ID, tr.T("queue.cleared"))
+	}
+
+	return h.sendHTML(message.Chat.ID, tr.T("queue.usage"))
+}
+
+// cmdNext 下一首命令
+func (h *BotHandler) cmdNext(message *tgbotapi.Message, user *model.User) error {
+	return h.playQueueNext(message.Chat.ID, user, false)
+}
+
+// playQueueNext 播放队列中的下一首，skip 为 true 时忽略单曲循环
+func (h *BotHandler) playQueueNext(chatID int64, user *model.User, skip bool) error {
+	song, err := h.advanceQueue(user, skip)
+	if err != nil {
+		return err
+	}
+	if song == nil {
+		return h.sendHTML(chatID, h.tr(user).T("queue.finished"))
+	}
+	return h.sendSong(chatID, song, user)
+}
+
+// advanceQueue 将队列移动到下一首并返回该歌曲，队列播完且无法填充时返回 nil
+func (h *BotHandler) advanceQueue(user *model.User, skip bool) (*model.Song, error) {
+	queue, err := h.queueRepo.Get(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	total, err := h.queueRepo.Count(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := queue.Current + 1
+	switch {
+	case !skip && queue.RepeatMode == model.RepeatOne && queue.Current > 0 && int64(queue.Current) <= total:
+		next = queue.Current
+	case int64(next) > total && queue.RepeatMode == model.RepeatAll && total > 0:
+		// 列表循环：回到开头，随机模式下重新打乱
+		next = 1
+		if queue.Shuffle {
+			if err := h.queueRepo.Shuffle(user.ID, 0); err != nil {
+				return nil, err
+			}
+		}
+	case int64(next) > total:
+		filled, err := h.fillQueue(queue)
+		if err != nil {
+			return nil, err
+		}
+		if filled == 0 {
+			return nil, nil
+		}
+	}
+
+	item, err := h.queueRepo.ItemAt(user.ID, next)
+	if err != nil {
+		return nil, err
+	}
+
+	queue.Current = next
+	if err := h.queueRepo.Update(queue); err != nil {
+		return nil, err
+	}
+
+	// 列表循环需要保留完整列表，其余模式只保留最近播放的条目
+	if queue.RepeatMode != model.RepeatAll && queue.Current > queueHistoryKeep*2 {
+		if err := h.queueRepo.TrimPlayed(user.ID, queue.Current-queueHistoryKeep); err != nil {
+			log.Printf("清理播放队列失败: %v", err)
+		}
+	}
+
+	return item.Song, nil
+}
+
+// fillQueue 从队列来源自动填充歌曲，返回填充数量
+func (h *BotHandler) fillQueue(queue *model.PlayQueue) (int, error) {
+	ids, err := h.queueRepo.GetFillCandidates(queue, queueFillSize)
+	if err != nil {
+		return 0, err
+	}
+	return h.queueRepo.Append(queue.UserID, ids)
+}
+
+// startQueueFrom 切换队列来源：清空队列，从新来源填充并开始播放
+func (h *BotHandler) startQueueFrom(chatID int64, user *model.User, source, ref string) error {
+	queue, err := h.queueRepo.Get(user.ID)
+	if err != nil {
+		return err
+	}
+	if err := h.queueRepo.Clear(user.ID); err != nil {
+		return err
+	}
+
+	queue.Current = 0
+	queue.Source = source
+	queue.SourceRef = ref
+	if err := h.queueRepo.Update(queue); err != nil {
+		return err
+	}
+
+	filled, err := h.fillQueue(queue)
+	if err != nil {
+		return err
+	}
+	tr := h.tr(user)
+	if filled == 0 {
+		return h.sendHTML(chatID, tr.T("queue.source_empty", i18n.Params{"Source": h.queueSourceText(tr, queue)}))
+	}
+
+	if err := h.sendHTML(chatID, tr.T("queue.started", i18n.Params{"Source": h.queueSourceText(tr, queue)})); err != nil {
+		return err
+	}
+	return h.playQueueNext(chatID, user, false)
+}
+
+// toggleQueueShuffle 切换随机播放，开启时打乱尚未播放的条目
+func (h *BotHandler) toggleQueueShuffle(user *model.User) (*model.PlayQueue, error) {
+	queue, err := h.queueRepo.Get(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	queue.Shuffle = !queue.Shuffle
+	if queue.Shuffle {
+		if err := h.queueRepo.Shuffle(user.ID, queue.Current); err != nil {
+			return nil, err
+		}
+	}
+	return queue, h.queueRepo.Update(queue)
+}
+
+// queueSourceText 获取队列来源显示文本
+func (h *BotHandler) queueSourceText(tr *i18n.Localizer, queue *model.PlayQueue) string {
+	switch queue.Source {
+	case model.QueueSourceFavorites:
+		return tr.T("queue.source.favorites")
+	case model.QueueSourceArtist:
+		if id, err := strconv.ParseUint(queue.SourceRef, 10, 32); err == nil {
+			if artist, err := h.artistRepo.FindByID(uint(id)); err == nil {
+				return tr.T("queue.source.artist_named", i18n.Params{"Name": html.EscapeString(artist.Name)})
+			}
+		}
+		return tr.T("queue.source.artist")
+	case model.QueueSourceAlbum:
+		if id, err := strconv.ParseUint(queue.SourceRef, 10, 32); err == nil {
+			if album, err := h.artistRepo.FindAlbumByID(uint(id)); err == nil {
+				return tr.T("queue.source.album_named", i18n.Params{"Name": html.EscapeString(album.Title)})
+			}
+		}
+		return tr.T("queue.source.album")
+	case model.QueueSourceStation:
+		return tr.T("queue.source.station", i18n.Params{
+			"Name": html.EscapeString(stationLabel(tr, database.ParseSearchQuery(queue.SourceRef))),
+		})
+	case model.QueueSourcePlaylist:
+		if id, err := strconv.ParseUint(queue.SourceRef, 10, 32); err == nil {
+			if playlist, err := h.playlistRepo.FindByID(uint(id)); err == nil {
+				return tr.T("queue.source.playlist_named", i18n.Params{"Name": html.EscapeString(playlist.Name)})
+			}
+		}
+		return tr.T("queue.source.playlist")
+	default:
+		return tr.T("queue.source.library")
+	}
+}
+
+// buildQueueView 构建播放队列页面
+func (h *BotHandler) buildQueueView(user *model.User, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	queue, err := h.queueRepo.Get(user.ID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	items, total, err := h.queueRepo.GetUpcoming(user.ID, queue.Current, page*queuePageSize, queuePageSize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	totalPages := int((total + queuePageSize - 1) / queuePageSize)
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	// 移除歌曲后当前页可能已不存在，回到最后一页
+	if page >= totalPages {
+		return h.buildQueueView(user, totalPages-1)
+	}
+
+	tr := h.tr(user)
+	var text strings.Builder
+	text.WriteString(tr.T("queue.title") + "\n\n")
+	text.WriteString(tr.T("queue.modes", i18n.Params{
+		"Shuffle": onOffText(tr, queue.Shuffle),
+		"Repeat":  repeatText(tr, queue.RepeatMode),
+	}) + "\n")
+	text.WriteString(tr.T("queue.fill_source", i18n.Params{"Source": h.queueSourceText(tr, queue)}) + "\n")
+	if queue.Current > 0 {
+		if current, err := h.queueRepo.ItemAt(user.ID, queue.Current); err == nil && current.Song != nil {
+			text.WriteString(tr.T("queue.now_playing", i18n.Params{
+				"Title":  html.EscapeString(current.Song.Title),
+				"Artist": html.EscapeString(current.Song.Artist),
+			}) + "\n")
+		}
+	}
+
+	text.WriteString("\n" + tr.N("queue.upcoming", total, i18n.Params{"Page": page + 1, "Pages": totalPages}) + "\n")
+	if total == 0 {
+		text.WriteString(tr.T("queue.upcoming_empty"))
+	}
+	for i, item := range items {
+		if item.Song == nil {
+			continue
+		}
+		text.WriteString(fmt.Sprintf("%d. %s - %s\n", page*queuePageSize+i+1, html.EscapeString(truncateString(item.Song.Title, 25)), html.EscapeString(truncateString(item.Song.Artist, 15))))
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+
+	// 移除按钮，每行 5 个
+	var row []tgbotapi.InlineKeyboardButton
+	for i, item := range items {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("❌ %d", page*queuePageSize+i+1),
+			fmt.Sprintf("qu_rm_%d_%d", item.Position, page),
+		))
+		if len(row) == 5 || i == len(items)-1 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+
+	// 翻页按钮
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.prev_page"), fmt.Sprintf("qu_v_%d", page-1)))
+	}
+	if page+1 < totalPages {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(tr.T("common.next_page"), fmt.Sprintf("qu_v_%d", page+1)))
+	}
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+
+	keyboard = append(keyboard,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("song.queue_next"), "qu_next"),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("queue.btn.shuffle"), "qu_shuf"),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("queue.btn.repeat"), "qu_rep"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("queue.btn.source"), "qu_src"),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("queue.btn.clear"), "qu_clr"),
+		),
+	)
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// sendQueueView 发送播放队列页面
+func (h *BotHandler) sendQueueView(chatID int64, user *model.User, page int) error {
+	text, markup, err := h.buildQueueView(user, page)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// editQueueView 原地刷新播放队列页面
+func (h *BotHandler) editQueueView(query *tgbotapi.CallbackQuery, user *model.User, page int, notice string) error {
+	text, markup, err := h.buildQueueView(user, page)
+	if err != nil {
+		return h.answerCallback(query, h.tr(user).T("queue.failed"), true)
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup)
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+	return h.answerCallback(query, notice, false)
+}
+
+// handleQueueCallback 处理播放队列相关回调（qu_ 前缀）
+func (h *BotHandler) handleQueueCallback(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	args := strings.Split(strings.TrimPrefix(query.Data, "qu_"), "_")
+
+	// 内联消息没有所在聊天，改为私聊发送
+	chatID := query.From.ID
+	if query.Message != nil {
+		chatID = query.Message.Chat.ID
+	}
+
+	switch args[0] {
+	case "next", "skip":
+		// qu_next：下一首（遵循单曲循环），qu_skip：跳过当前歌曲
+		if err := h.playQueueNext(chatID, user, args[0] == "skip"); err != nil {
+			log.Printf("播放队列失败: %v", err)
+			return h.answerCallback(query, tr.T("queue.play_failed"), true)
+		}
+		return h.answerCallback(query, "", false)
+
+	case "add":
+		// qu_add_<歌曲ID>：加入队列末尾
+		if len(args) != 2 {
+			return h.answerCallback(query, tr.T("common.invalid_action"), true)
+		}
+		songID, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return h.answerCallback(query, tr.T("song.invalid_id"), true)
+		}
+		if _, err := h.queueRepo.Append(user.ID, []uint{uint(songID)}); err != nil {
+			return h.answerCallback(query, tr.T("queue.add_failed"), true)
+		}
+		return h.answerCallback(query, tr.T("queue.added"), false)
+	}
+
+	// 以下操作都需要在队列页面中进行
+	if query.Message == nil {
+		return h.answerCallback(query, tr.T("queue.private_only"), true)
+	}
+
+	switch args[0] {
+	case "v":
+		// qu_v_<页码>
+		return h.editQueueView(query, user, callbackPage(args, 1), "")
+
+	case "rm":
+		// qu_rm_<位置>_<页码>
+		if len(args) != 3 {
+			return h.answerCallback(query, tr.T("common.invalid_action"), true)
+		}
+		position, err := strconv.Atoi(args[1])
+		if err != nil {
+			return h.answerCallback(query, tr.T("queue.invalid_position"), true)
+		}
+		if err := h.queueRepo.Remove(user.ID, position); err != nil {
+			return h.answerCallback(query, tr.T("queue.remove_failed"), true)
+		}
+		return h.editQueueView(query, user, callbackPage(args, 2), tr.T("queue.removed"))
+
+	case "shuf":
+		queue, err := h.toggleQueueShuffle(user)
+		if err != nil {
+			return h.answerCallback(query, tr.T("queue.update_failed"), true)
+		}
+		return h.editQueueView(query, user, 0, shuffleText(tr, queue.Shuffle))
+
+	case "rep":
+		queue, err := h.queueRepo.Get(user.ID)
+		if err != nil {
+			return h.answerCallback(query, tr.T("queue.update_failed"), true)
+		}
+		queue.RepeatMode = queue.NextRepeatMode()
+		if err := h.queueRepo.Update(queue); err != nil {
+			return h.answerCallback(query, tr.T("queue.update_failed"), true)
+		}
+		return h.editQueueView(query, user, 0, "🔁 "+repeatText(tr, queue.RepeatMode))
+
+	case "clr":
+		if err := h.queueRepo.Clear(user.ID); err != nil {
+			return h.answerCallback(query, tr.T("queue.clear_failed"), true)
+		}
+		return h.editQueueView(query, user, 0, tr.T("queue.cleared_short"))
+
+	case "src":
+		if len(args) == 1 {
+			return h.callbackQueueSourcePicker(query, user)
+		}
+		return h.callbackQueueSource(query, user, args[1:])
+	}
+
+	return h.answerCallback(query, tr.T("common.unknown_action"), true)
+}
+
+// callbackQueueSourcePicker 显示自动填充来源选择器
+func (h *BotHandler) callbackQueueSourcePicker(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	keyboard := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("queue.source.library"), "qu_src_lib"),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("queue.source.favorites"), "qu_src_fav"),
+		),
+	}
+
+	// 当前歌曲的主唱
+	if queue, err := h.queueRepo.Get(user.ID); err == nil && queue.Current > 0 {
+		if current, err := h.queueRepo.ItemAt(user.ID, queue.Current); err == nil {
+			if artist, err := h.artistRepo.FindMainBySong(current.SongID); err == nil {
+				keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(
+						fmt.Sprintf("🎤 %s", truncateString(artist.Name, 20)),
+						fmt.Sprintf("qu_src_art_%d", artist.ID),
+					),
+				))
+			}
+		}
+	}
+
+	if playlists, err := h.playlistRepo.GetByUser(user.ID); err == nil {
+		for _, playlist := range playlists {
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					fmt.Sprintf("📂 %s (%d)", truncateString(playlist.Name, 20), playlist.SongCount),
+					fmt.Sprintf("qu_src_pl_%d", playlist.ID),
+				),
+			))
+		}
+	}
+
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("queue.btn.back"), "qu_v_0"),
+	))
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID,
+		tr.T("queue.source_picker"), tgbotapi.NewInlineKeyboardMarkup(keyboard...))
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+	return h.answerCallback(query, "", false)
+}
+
+// callbackQueueSource 切换队列来源：qu_src_lib、qu_src_fav、qu_src_art_<歌手ID>、qu_src_pl_<歌单ID>
+func (h *BotHandler) callbackQueueSource(query *tgbotapi.CallbackQuery, user *model.User, args []string) error {
+	tr := h.tr(user)
+	source, ref := model.QueueSourceLibrary, ""
+
+	switch args[0] {
+	case "lib":
+	case "fav":
+		source = model.QueueSourceFavorites
+	case "art", "pl":
+		if len(args) != 2 {
+			return h.answerCallback(query, tr.T("common.invalid_action"), true)
+		}
+		id, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return h.answerCallback(query, tr.T("common.invalid_action"), true)
+		}
+
+		if args[0] == "art" {
+			artist, err := h.artistRepo.FindByID(uint(id))
+			if err != nil {
+				return h.answerCallback(query, tr.T("queue.artist_missing"), true)
+			}
+			source, ref = model.QueueSourceArtist, strconv.FormatUint(uint64(artist.ID), 10)
+			break
+		}
+
+		playlist, err := h.playlistRepo.FindByID(uint(id))
+		if err != nil || h.getPlaylistRole(playlist, user) < playlistRoleViewer {
+			return h.answerCallback(query, tr.T("playlist.missing"), true)
+		}
+		source, ref = model.QueueSourcePlaylist, strconv.FormatUint(uint64(playlist.ID), 10)
+	default:
+		return h.answerCallback(query, tr.T("common.unknown_action"), true)
+	}
+
+	h.bot.Request(tgbotapi.NewDeleteMessage(query.Message.Chat.ID, query.Message.MessageID))
+	if err := h.startQueueFrom(query.Message.Chat.ID, user, source, ref); err != nil {
+		log.Printf("切换队列来源失败: %v", err)
+		return h.answerCallback(query, tr.T("queue.switch_failed"), true)
+	}
+	return h.answerCallback(query, "", false)
+}
+
+// onOffText 开关状态文本
+func onOffText(tr *i18n.Localizer, on bool) string {
+	if on {
+		return tr.T("common.on")
+	}
+	return tr.T("common.off")
+}
+
+// shuffleText 切换随机播放后的提示
+func shuffleText(tr *i18n.Localizer, on bool) string {
+	return tr.T("queue.shuffle_toggled", i18n.Params{"State": onOffText(tr, on)})
+}
+
+// repeatText 循环模式显示文本
+func repeatText(tr *i18n.Localizer, mode string) string {
+	switch mode {
+	case model.RepeatOne, model.RepeatAll:
+		return tr.T("queue.repeat." + mode)
+	default:
+		return tr.T("queue.repeat.off")
+	}
+}
@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// radioValueLimit 每个分类最多显示的取值数
+const radioValueLimit = 20
+
+// radioDimension 电台分类维度
+type radioDimension struct {
+	key      string // 回调数据中的简写
+	category string // database.CategoryXXX
+	label    string // 名称的消息键
+}
+
+var radioDimensions = []radioDimension{
+	{"g", database.CategoryGenre, "radio.dimension.genre"},
+	{"l", database.CategoryLanguage, "radio.dimension.language"},
+	{"c", database.CategoryCountry, "radio.dimension.country"},
+	{"y", database.CategoryDecade, "radio.dimension.decade"},
+}
+
+// findRadioDimension 根据简写查找分类维度
+func findRadioDimension(key string) (radioDimension, bool) {
+	for _, dim := range radioDimensions {
+		if dim.key == key {
+			return dim, true
+		}
+	}
+	return radioDimension{}, false
+}
+
+// cmdRadio 电台命令
+// 用法：/radio 打开分类选择，/radio 日语 2000s 直接收听对应电台
+func (h *BotHandler) cmdRadio(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args != "" {
+		q, unknown, err := h.parseStationFilter(args)
+		if err != nil {
+			return err
+		}
+		if len(unknown) > 0 {
+			return h.sendUnknownCategory(message.Chat.ID, tr, unknown)
+		}
+		return h.startQueueFrom(message.Chat.ID, user, model.QueueSourceStation, q.String())
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, tr.T("radio.menu"))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = radioMenuMarkup(tr)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+// radioMenuMarkup 电台分类维度选择按钮
+func radioMenuMarkup(tr *i18n.Localizer) tgbotapi.InlineKeyboardMarkup {
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, dim := range radioDimensions {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(tr.T(dim.label), "rd_d_"+dim.key))
+		if len(row) == 2 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
+
+// handleRadioCallback 处理电台回调
+// rd_m：返回分类维度，rd_d_<维度>：列出取值，rd_s_<维度>_<取值>：开始收听
+func (h *BotHandler) handleRadioCallback(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	if query.Message == nil {
+		return h.answerCallback(query, tr.T("radio.private_only"), true)
+	}
+	args := strings.SplitN(strings.TrimPrefix(query.Data, "rd_"), "_", 3)
+
+	switch args[0] {
+	case "m":
+		edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, tr.T("radio.menu"), radioMenuMarkup(tr))
+		edit.ParseMode = "HTML"
+		h.bot.Send(edit)
+		return h.answerCallback(query, "", false)
+
+	case "d":
+		if len(args) != 2 {
+			return h.answerCallback(query, tr.T("common.invalid_action"), true)
+		}
+		dim, ok := findRadioDimension(args[1])
+		if !ok {
+			return h.answerCallback(query, tr.T("radio.unknown_dimension"), true)
+		}
+		return h.callbackRadioValues(query, tr, dim)
+
+	case "s":
+		if len(args) != 3 {
+			return h.answerCallback(query, tr.T("common.invalid_action"), true)
+		}
+		dim, ok := findRadioDimension(args[1])
+		if !ok {
+			return h.answerCallback(query, tr.T("radio.unknown_dimension"), true)
+		}
+		q, ok := stationFilterFor(dim.category, args[2])
+		if !ok {
+			return h.answerCallback(query, tr.T("radio.invalid_value"), true)
+		}
+
+		h.bot.Request(tgbotapi.NewDeleteMessage(query.Message.Chat.ID, query.Message.MessageID))
+		if err := h.startQueueFrom(query.Message.Chat.ID, user, model.QueueSourceStation, q.String()); err != nil {
+			return h.answerCallback(query, tr.T("radio.start_failed"), true)
+		}
+		return h.answerCallback(query, "", false)
+	}
+
+	return h.answerCallback(query, tr.T("common.unknown_action"), true)
+}
+
+// callbackRadioValues 列出某一分类在音乐库中的所有取值
+func (h *BotHandler) callbackRadioValues(query *tgbotapi.CallbackQuery, tr *i18n.Localizer, dim radioDimension) error {
+	counts, err := h.songRepo.GetCategoryCounts(dim.category)
+	if err != nil {
+		return h.answerCallback(query, tr.T("radio.values_failed"), true)
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, count := range counts {
+		if i >= radioValueLimit {
+			break
+		}
+		data := fmt.Sprintf("rd_s_%s_%s", dim.key, count.Value)
+		if len(data) > callbackDataMaxLen {
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s (%d)", categoryValueText(dim.category, count.Value), count.Count),
+			data,
+		))
+		if len(row) == 2 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboard = append(keyboard, row)
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("radio.back"), "rd_m"),
+	))
+
+	text := tr.T("radio.values", i18n.Params{"Dimension": tr.T(dim.label)})
+	if len(counts) == 0 {
+		text = tr.T("radio.values_empty", i18n.Params{"Dimension": tr.T(dim.label)})
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, tgbotapi.NewInlineKeyboardMarkup(keyboard...))
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+	return h.answerCallback(query, "", false)
+}
+
+// parseStationFilter 解析 /random 和 /radio 的参数，如 "日语 2000s 摇滚"
+// 每个词依次尝试匹配年代、类型、语言和地区，也支持 genre:摇滚 这样的搜索语法；
+// 返回无法识别的词
+func (h *BotHandler) parseStationFilter(args string) (database.SearchQuery, []string, error) {
+	q := database.ParseSearchQuery(args)
+	words := strings.Fields(q.Text)
+	q.Text = ""
+
+	// 音乐库中已有的分类取值，按类型、语言、地区的顺序匹配
+	categories := []string{database.CategoryGenre, database.CategoryLanguage, database.CategoryCountry}
+	values := make(map[string][]database.CategoryCount, len(categories))
+	if len(words) > 0 {
+		for _, category := range categories {
+			counts, err := h.songRepo.GetCategoryCounts(category)
+			if err != nil {
+				return q, nil, err
+			}
+			values[category] = counts
+		}
+	}
+
+	var unknown []string
+	for _, word := range words {
+		if from, to, ok := parseDecade(word); ok {
+			q.YearFrom, q.YearTo = from, to
+			continue
+		}
+		if category, value, ok := matchCategory(categories, values, word); ok {
+			q, _ = mergeStationFilter(q, category, value)
+			continue
+		}
+		unknown = append(unknown, word)
+	}
+	return q, unknown, nil
+}
+
+// matchCategory 在已有分类取值中查找与 word 相同（不区分大小写）的一项
+func matchCategory(categories []string, values map[string][]database.CategoryCount, word string) (string, string, bool) {
+	for _, category := range categories {
+		for _, count := range values[category] {
+			if strings.EqualFold(count.Value, word) {
+				return category, count.Value, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// sendUnknownCategory 提示无法识别的分类
+func (h *BotHandler) sendUnknownCategory(chatID int64, tr *i18n.Localizer, unknown []string) error {
+	return h.sendHTML(chatID, tr.T("radio.unknown_category", i18n.Params{
+		"Words": html.EscapeString(strings.Join(unknown, " ")),
+	}))
+}
+
+// stationFilterFor 根据单个分类取值构造筛选条件
+func stationFilterFor(category, value string) (database.SearchQuery, bool) {
+	return mergeStationFilter(database.SearchQuery{}, category, value)
+}
+
+// mergeStationFilter 将分类取值合并到筛选条件
+func mergeStationFilter(q database.SearchQuery, category, value string) (database.SearchQuery, bool) {
+	switch category {
+	case database.CategoryGenre:
+		q.Genre = value
+	case database.CategoryLanguage:
+		q.Language = value
+	case database.CategoryCountry:
+		q.Country = strings.ToUpper(value)
+	case database.CategoryDecade:
+		decade, err := strconv.Atoi(value)
+		if err != nil {
+			return q, false
+		}
+		q.YearFrom, q.YearTo = decade, decade+9
+	default:
+		return q, false
+	}
+	return q, true
+}
+
+// parseDecade 解析年代或年份：2000s、90s、90年代、2000年代、2008
+func parseDecade(word string) (int, int, bool) {
+	word = strings.ToLower(word)
+	isDecade := false
+	for _, suffix := range []string{"年代", "s"} {
+		if strings.HasSuffix(word, suffix) {
+			word = strings.TrimSuffix(word, suffix)
+			isDecade = true
+			break
+		}
+	}
+
+	n, err := strconv.Atoi(word)
+	if err != nil {
+		return 0, 0, false
+	}
+	if !isDecade {
+		if n >= 1000 && n <= 9999 {
+			return n, n, true
+		}
+		return 0, 0, false
+	}
+
+	// 两位数年代：90s → 1990s，10s → 2010s
+	if n < 100 {
+		if n < 30 {
+			n += 2000
+		} else {
+			n += 1900
+		}
+	}
+	if n < 1000 || n%10 != 0 {
+		return 0, 0, false
+	}
+	return n, n + 9, true
+}
+
+// stationLabel 电台名称，如 "🎸 摇滚 · 🌐 日语 · 📅 2000s"
+func stationLabel(tr *i18n.Localizer, q database.SearchQuery) string {
+	var parts []string
+	if q.Genre != "" {
+		parts = append(parts, "🎸 "+q.Genre)
+	}
+	if q.Language != "" {
+		parts = append(parts, "🌐 "+q.Language)
+	}
+	if q.Country != "" {
+		parts = append(parts, categoryValueText(database.CategoryCountry, q.Country))
+	}
+	if q.Artist != "" {
+		parts = append(parts, "🎤 "+q.Artist)
+	}
+	if q.YearFrom > 0 {
+		switch {
+		case q.YearFrom == q.YearTo:
+			parts = append(parts, fmt.Sprintf("📅 %d", q.YearFrom))
+		case q.YearFrom%10 == 0 && q.YearTo == q.YearFrom+9:
+			parts = append(parts, fmt.Sprintf("📅 %ds", q.YearFrom))
+		default:
+			parts = append(parts, fmt.Sprintf("📅 %d-%d", q.YearFrom, q.YearTo))
+		}
+	}
+	if len(parts) == 0 {
+		return tr.T("radio.all_songs")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// categoryValueText 分类取值的显示文本
+func categoryValueText(category, value string) string {
+	switch category {
+	case database.CategoryCountry:
+		return model.CountryEmoji(value) + " " + value
+	case database.CategoryDecade:
+		return value + "s"
+	}
+	return value
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+const (
+	// recommendPageSize 推荐页面每页显示的歌曲数
+	recommendPageSize = 10
+	// recommendTotal 每次计算的推荐总数，"换一批"在其中翻页
+	recommendTotal = 50
+)
+
+// recommendButton "猜你喜欢"按钮
+func recommendButton(tr *i18n.Localizer) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(tr.T("recommend.button"), "rec_0")
+}
+
+// cmdRecommend 个性化推荐命令
+func (h *BotHandler) cmdRecommend(message *tgbotapi.Message, user *model.User) error {
+	text, markup, err := h.buildRecommendView(user, 0)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// callbackRecommend 推荐翻页回调：rec_<页码>
+// rec_0 来自歌曲列表等消息上的"猜你喜欢"按钮，发送新消息；"换一批"的页码从 1 开始递增，原地刷新
+func (h *BotHandler) callbackRecommend(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	if query.Message == nil {
+		return h.answerCallback(query, tr.T("recommend.private_only"), true)
+	}
+
+	page := callbackPage(strings.Split(query.Data, "_"), 1)
+	text, markup, err := h.buildRecommendView(user, page)
+	if err != nil {
+		return h.answerCallback(query, tr.T("recommend.failed"), true)
+	}
+
+	if page == 0 {
+		msg := tgbotapi.NewMessage(query.Message.Chat.ID, text)
+		msg.ParseMode = "HTML"
+		if markup != nil {
+			msg.ReplyMarkup = *markup
+		}
+		h.bot.Send(msg)
+		return h.answerCallback(query, "", false)
+	}
+
+	var edit tgbotapi.EditMessageTextConfig
+	if markup != nil {
+		edit = tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, *markup)
+	} else {
+		edit = tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	}
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+	return h.answerCallback(query, "", false)
+}
+
+// buildRecommendView 构建推荐页面，page 超出范围时从第一批重新开始
+func (h *BotHandler) buildRecommendView(user *model.User, page int) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	recommendations, err := h.recommendService.Recommend(user.ID, recommendTotal)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tr := h.tr(user)
+	if len(recommendations) == 0 {
+		return tr.T("recommend.title") + "\n\n" + tr.T("recommend.empty"), nil, nil
+	}
+
+	totalPages := (len(recommendations) + recommendPageSize - 1) / recommendPageSize
+	page %= totalPages
+	start := page * recommendPageSize
+	end := start + recommendPageSize
+	if end > len(recommendations) {
+		end = len(recommendations)
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("recommend.title") + "\n")
+	text.WriteString(tr.T("recommend.batch", i18n.Params{"Page": page + 1, "Pages": totalPages}) + "\n\n")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, rec := range recommendations[start:end] {
+		song := rec.Song
+		reason := tr.T(rec.Reason, rec.ReasonParams)
+		text.WriteString(fmt.Sprintf("%d. %s <b>%s</b> - %s\n    <i>%s</i>\n", i+1, song.GetCountryEmoji(),
+			html.EscapeString(song.Title), html.EscapeString(song.Artist), html.EscapeString(reason)))
+
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%d. %s", i+1, truncateString(song.Title, 20)),
+			fmt.Sprintf("play_%d", song.ID),
+		))
+		if len(row) == 2 || i == end-start-1 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+
+	if totalPages > 1 {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("recommend.more"), fmt.Sprintf("rec_%d", page+1)),
+		))
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	return text.String(), &markup, nil
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"html"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+	"gorm.io/gorm"
+)
+
+// cmdPromote 提升用户角色：/promote 用户 [admin|uploader]，不指定角色时提升一级
+func (h *BotHandler) cmdPromote(message *tgbotapi.Message, user *model.User) error {
+	return h.changeRole(message, user, []string{model.RoleAdmin, model.RoleUploader}, func(target *model.User) string {
+		if target.HasRole(model.RoleUploader) {
+			return model.RoleAdmin
+		}
+		return model.RoleUploader
+	})
+}
+
+// cmdDemote 降低用户角色：/demote 用户 [uploader|listener]，不指定角色时降为听众
+func (h *BotHandler) cmdDemote(message *tgbotapi.Message, user *model.User) error {
+	return h.changeRole(message, user, []string{model.RoleUploader, model.RoleListener}, func(*model.User) string {
+		return model.RoleListener
+	})
+}
+
+// cmdBan 封禁用户：/ban 用户
+func (h *BotHandler) cmdBan(message *tgbotapi.Message, user *model.User) error {
+	return h.changeRole(message, user, nil, func(*model.User) string {
+		return model.RoleBanned
+	})
+}
+
+// cmdUnban 解除封禁：/unban 用户，恢复为新用户的默认角色
+func (h *BotHandler) cmdUnban(message *tgbotapi.Message, user *model.User) error {
+	return h.changeRole(message, user, nil, func(target *model.User) string {
+		if !target.IsBanned() {
+			return target.Role
+		}
+		return h.botConfig.DefaultRole
+	})
+}
+
+// changeRole 解析 "用户 [角色]" 参数并修改角色；allowed 为可以显式指定的角色，
+// 没有指定时由 fallback 根据用户当前角色决定
+func (h *BotHandler) changeRole(message *tgbotapi.Message, actor *model.User, allowed []string, fallback func(target *model.User) string) error {
+	tr := h.tr(actor)
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 || len(args) > 2 || (len(args) == 2 && len(allowed) == 0) {
+		return h.sendRoleUsage(message.Chat.ID, tr)
+	}
+
+	target, err := h.findUserByRef(args[0])
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return h.sendHTML(message.Chat.ID, tr.T("roles.user_not_found", i18n.Params{"User": html.EscapeString(args[0])}))
+	}
+	if err != nil {
+		return err
+	}
+	if target.Role == model.RoleOwner || target.TelegramID == h.adminID {
+		return h.sendHTML(message.Chat.ID, tr.T("roles.owner_immutable"))
+	}
+
+	role := fallback(target)
+	if len(args) == 2 {
+		role = strings.ToLower(args[1])
+		if !slices.Contains(allowed, role) {
+			return h.sendHTML(message.Chat.ID, tr.T("roles.invalid_role", i18n.Params{"Roles": strings.Join(allowed, ", ")}))
+		}
+	}
+
+	params := i18n.Params{
+		"Name": html.EscapeString(target.GetFullName()),
+		"Role": tr.T("roles.name." + role),
+	}
+	if target.Role == role {
+		return h.sendHTML(message.Chat.ID, tr.T("roles.unchanged", params))
+	}
+	if err := h.userRepo.UpdateRole(target.ID, role); err != nil {
+		return err
+	}
+	target.Role = role
+
+	if err := h.updateStaffMenu(target); err != nil {
+		log.Printf("更新用户 %d 的命令菜单失败: %v", target.TelegramID, err)
+	}
+	if !target.IsBanned() {
+		// 对方可能已停用 Bot，通知失败不影响结果
+		h.sendHTML(target.TelegramID, h.tr(target).T("roles.changed", i18n.Params{
+			"Role": h.tr(target).T("roles.name." + role),
+		}))
+	}
+	return h.sendHTML(message.Chat.ID, tr.T("roles.updated", params))
+}
+
+// sendRoleUsage 发送角色命令说明和当前的管理员、封禁用户列表
+func (h *BotHandler) sendRoleUsage(chatID int64, tr *i18n.Localizer) error {
+	users, err := h.userRepo.FindByRoles(model.RoleOwner, model.RoleAdmin, model.RoleBanned)
+	if err != nil {
+		return err
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("roles.usage"))
+	text.WriteString("\n\n")
+	if len(users) == 0 {
+		text.WriteString(tr.T("roles.staff_empty"))
+		return h.sendHTML(chatID, text.String())
+	}
+	text.WriteString(tr.T("roles.staff_title"))
+	for _, u := range users {
+		text.WriteString("\n")
+		text.WriteString(tr.T("roles.staff_item", i18n.Params{
+			"Name": html.EscapeString(u.GetFullName()),
+			"ID":   u.TelegramID,
+			"Role": tr.T("roles.name." + u.Role),
+		}))
+	}
+	return h.sendHTML(chatID, text.String())
+}
+
+// findUserByRef 按 @用户名 或 Telegram ID 查找用户
+func (h *BotHandler) findUserByRef(ref string) (*model.User, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return h.userRepo.FindByTelegramID(id)
+	}
+	return h.userRepo.FindByUsername(strings.TrimPrefix(ref, "@"))
+}
+
+// cmdWebToken 生成 Web 后台登录密码，旧密码随之失效
+func (h *BotHandler) cmdWebToken(message *tgbotapi.Message, user *model.User) error {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(buf)
+	if err := h.userRepo.UpdateWebToken(user.ID, hashWebToken(token)); err != nil {
+		return err
+	}
+	return h.sendHTML(message.Chat.ID, h.tr(user).T("roles.webtoken", i18n.Params{
+		"ID":    user.TelegramID,
+		"Token": token,
+	}))
+}
+
+// hashWebToken 数据库中只保存 Web 登录密码的 SHA-256
+func hashWebToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/config"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/internal/router"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+const (
+	// rateLimitWindow 限流窗口，bot.rate_limit 为每个窗口内允许的消息和按钮数
+	rateLimitWindow = time.Minute
+	// lastSeenInterval 最后活跃时间的更新间隔，避免每条消息都写一次数据库
+	lastSeenInterval = time.Hour
+)
+
+// HandleUpdate 处理一条 Telegram 更新，处理失败时由日志中间件记录
+func (h *BotHandler) HandleUpdate(update tgbotapi.Update) {
+	h.router.Handle(update)
+}
+
+// Metrics 各路由的处理次数和耗时统计
+func (h *BotHandler) Metrics() *router.Metrics {
+	return h.metrics
+}
+
+// newRouter 注册所有路由
+//
+//	根路由：日志、耗时统计、panic 恢复；inline 查询不需要用户
+//	└─ 用户分组：限流、加载用户、拦截已封禁用户和未获准的用户（白名单、邀请制）
+//	   ├─ 回调（按前缀）
+//	   ├─ 私聊：命令表中的命令、歌词上传、搜索
+//	   └─ 群组：发给本 Bot 的命令、@提及和回复
+func (h *BotHandler) newRouter() *router.Router {
+	r := router.New()
+	r.Use(router.Logger(h.logger), h.metrics.Middleware(), router.Recover())
+	r.Inline(func(c *router.Context) error {
+		return h.handleInlineQuery(c.InlineQuery)
+	})
+
+	users := r.Group(nil)
+	users.Use(
+		router.RateLimit(h.botConfig.RateLimit, rateLimitWindow, h.onRateLimited),
+		router.LoadUser(h.loadUser),
+		router.Require(h.notBanned, h.onBanned),
+		router.Require(h.isMember, h.onNotMember),
+	)
+	users.ChosenInline(func(c *router.Context) error {
+		return h.handleChosenInlineResult(c.ChosenInlineResult, c.User)
+	})
+
+	h.registerCallbacks(users.Group(router.IsCallback))
+	h.registerPrivateRoutes(users.Group(router.PrivateChat))
+	h.registerGroupRoutes(users.Group(h.isGroupRequest))
+
+	return r
+}
+
+// registerCallbacks 注册按钮回调，回调数据格式均为 <前缀>_<参数>
+func (h *BotHandler) registerCallbacks(r *router.Router) {
+	callbacks := map[string]func(*tgbotapi.CallbackQuery, *model.User) error{
+		"play_":  h.callbackPlay,
+		"rd_":    h.handleRadioCallback,
+		"rec_":   h.callbackRecommend,
+		"qu_":    h.handleQueueCallback,
+		"lang_":  h.callbackLanguage,
+		"set_":   h.handleSettingsCallback,
+		"ch_":    h.handleChartCallback,
+		"lyr_":   h.callbackLyrics,
+		"lyrx_":  h.callbackLyricsExport,
+		"share_": h.callbackShareSong,
+		"ms_":    h.handleStatsCallback,
+		"rcp_":   h.handleStatsCallback,
+
+		searchPagePrefix: h.callbackSearchPage,
+	}
+	for _, prefix := range browseCallbackPrefixes {
+		callbacks[prefix+"_"] = h.handleBrowseCallback
+	}
+	for _, prefix := range playlistCallbackPrefixes {
+		callbacks[prefix+"_"] = h.handlePlaylistCallback
+	}
+
+	for prefix, fn := range callbacks {
+		r.Callback(prefix, onCallback(fn))
+	}
+	r.Callback("fav_", onCallback(func(query *tgbotapi.CallbackQuery, user *model.User) error {
+		return h.callbackFavorite(query, user, true)
+	}))
+	r.Callback("unfav_", onCallback(func(query *tgbotapi.CallbackQuery, user *model.User) error {
+		return h.callbackFavorite(query, user, false)
+	}))
+	r.Callback("bc_", onCallback(h.handleBroadcastCallback), h.requireRole(model.RoleAdmin))
+
+	r.NotFound(func(c *router.Context) error {
+		return h.answerCallback(c.CallbackQuery, h.tr(c.User).T("common.unknown_action"), true)
+	})
+}
+
+// registerPrivateRoutes 注册私聊路由，命令见 commands.go
+func (h *BotHandler) registerPrivateRoutes(r *router.Router) {
+	for _, cmd := range botCommands {
+		if cmd.private == nil {
+			continue
+		}
+		var middleware []router.Middleware
+		if cmd.role != "" {
+			middleware = append(middleware, h.requireRole(cmd.role))
+		}
+		handler := onMessage(h, cmd.private)
+		for _, name := range commandNames(cmd) {
+			r.Command(name, handler, middleware...)
+		}
+	}
+
+	r.Message("unknown_command", router.IsCommand, onMessage(h, (*BotHandler).cmdUnknown))
+	r.Message("lyrics_upload", func(c *router.Context) bool {
+		return isLyricsFile(c.Message.Document)
+	}, onMessage(h, (*BotHandler).handleLyricsUpload), h.requireRole(model.RoleAdmin))
+	r.Message("search", router.Any, onMessage(h, (*BotHandler).handleSearch))
+}
+
+// registerGroupRoutes 注册群组路由，群内不回复未知命令，避免刷屏
+func (h *BotHandler) registerGroupRoutes(r *router.Router) {
+	for _, cmd := range botCommands {
+		if cmd.group == nil {
+			continue
+		}
+		handler := h.withGroupSetting(cmd.group)
+		for _, name := range commandNames(cmd) {
+			r.Command(name, handler)
+		}
+	}
+
+	r.Message("group_mention", func(c *router.Context) bool {
+		return !c.Message.IsCommand()
+	}, h.withGroupSetting(func(h *BotHandler, message *tgbotapi.Message, user *model.User, setting *model.GroupSetting) error {
+		return h.handleGroupQuery(message, user, setting, h.stripMention(message.Text))
+	}))
+}
+
+// loadUser 获取或创建用户，新用户的界面语言取自 Telegram 客户端
+// 开放模式下新用户直接获准使用，之后切换为白名单或邀请制时不受影响
+// 所有者由 bot.admin_id 决定，修改配置后原所有者降为管理员
+func (h *BotHandler) loadUser(from *tgbotapi.User) (*model.User, error) {
+	role := h.botConfig.DefaultRole
+	if from.ID == h.adminID {
+		role = model.RoleOwner
+	}
+	user, err := h.userRepo.FindOrCreate(
+		from.ID,
+		from.UserName,
+		from.FirstName,
+		from.LastName,
+		h.locales.Match(from.LanguageCode),
+		role,
+		h.botConfig.Access == config.AccessOpen,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		// 公告发送失败时会标记为不可接收，用户重新使用 Bot 说明又能收到消息了
+		if err := h.userRepo.UpdateActive(user.ID, true); err != nil {
+			return nil, err
+		}
+		user.IsActive = true
+	}
+	if time.Since(user.LastSeen) > lastSeenInterval {
+		// 公告按最后活跃时间筛选接收人，发消息、点按钮都算活跃
+		if err := h.userRepo.UpdateLastSeen(user.ID); err != nil {
+			return nil, err
+		}
+		user.LastSeen = time.Now()
+	}
+
+	switch {
+	case from.ID == h.adminID && user.Role != model.RoleOwner:
+		role = model.RoleOwner
+	case from.ID != h.adminID && user.Role == model.RoleOwner:
+		role = model.RoleAdmin
+	default:
+		return user, nil
+	}
+	if err := h.userRepo.UpdateRole(user.ID, role); err != nil {
+		return nil, fmt.Errorf("同步所有者角色失败: %w", err)
+	}
+	user.Role = role
+	return user, nil
+}
+
+// notBanned 用户未被封禁
+func (h *BotHandler) notBanned(c *router.Context) bool {
+	return !c.User.IsBanned()
+}
+
+// onBanned 提示已封禁用户，群组中不回复，避免刷屏
+func (h *BotHandler) onBanned(c *router.Context) error {
+	text := h.tr(c.User).T("common.banned")
+	switch {
+	case c.CallbackQuery != nil:
+		return h.answerCallback(c.CallbackQuery, text, true)
+	case c.Message != nil && c.Message.Chat.IsPrivate():
+		return h.sendHTML(c.Message.Chat.ID, text)
+	}
+	return nil
+}
+
+// requireRole 只允许不低于指定角色的用户使用的路由
+func (h *BotHandler) requireRole(role string) router.Middleware {
+	return router.Require(func(c *router.Context) bool {
+		return c.User.HasRole(role)
+	}, func(c *router.Context) error {
+		tr := h.tr(c.User)
+		text := tr.T("common.forbidden", i18n.Params{"Role": tr.T("roles.name." + role)})
+		if c.CallbackQuery != nil {
+			return h.answerCallback(c.CallbackQuery, text, true)
+		}
+		return h.sendHTML(c.Message.Chat.ID, text)
+	})
+}
+
+// onRateLimited 提示用户操作太频繁，此时还没有加载用户，按客户端语言显示
+func (h *BotHandler) onRateLimited(c *router.Context) error {
+	text := h.locales.Localizer(h.locales.Match(c.From().LanguageCode)).T("common.rate_limited")
+	if c.CallbackQuery != nil {
+		return h.answerCallback(c.CallbackQuery, text, true)
+	}
+	return h.sendHTML(c.Message.Chat.ID, text)
+}
+
+// isGroupRequest 群组中发给本 Bot 的消息：/cmd、/cmd@本Bot、@提及或回复 Bot
+// 普通聊天一律忽略，因此在 @BotFather 开启隐私模式时也能正常工作
+func (h *BotHandler) isGroupRequest(c *router.Context) bool {
+	if !h.groupConfig.Enabled || !router.GroupChat(c) {
+		return false
+	}
+	message := c.Message
+	if message.From == nil || message.From.IsBot {
+		return false
+	}
+	if message.IsCommand() {
+		// 忽略发给其他 Bot 的命令，如 /song@OtherBot
+		return h.isCommandForMe(message)
+	}
+	return h.isMentioned(message)
+}
+
+// withGroupSetting 加载群组设置后调用群组处理函数
+func (h *BotHandler) withGroupSetting(fn func(h *BotHandler, message *tgbotapi.Message, user *model.User, setting *model.GroupSetting) error) router.HandlerFunc {
+	return func(c *router.Context) error {
+		setting, err := h.getGroupSetting(c.Message.Chat)
+		if err != nil {
+			return fmt.Errorf("获取群组设置失败: %w", err)
+		}
+		return fn(h, c.Message, c.User, setting)
+	}
+}
+
+// onMessage 把消息处理方法包装为路由处理函数
+func onMessage(h *BotHandler, fn func(h *BotHandler, message *tgbotapi.Message, user *model.User) error) router.HandlerFunc {
+	return func(c *router.Context) error {
+		return fn(h, c.Message, c.User)
+	}
+}
+
+// onCallback 把回调处理函数包装为路由处理函数
+func onCallback(fn func(query *tgbotapi.CallbackQuery, user *model.User) error) router.HandlerFunc {
+	return func(c *router.Context) error {
+		return fn(c.CallbackQuery, c.User)
+	}
+}
+
+// commandNames 命令名及其别名
+func commandNames(cmd botCommand) []string {
+	return append([]string{cmd.name}, cmd.aliases...)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/config"
+	"github.com/user/fish-music/internal/router"
+)
+
+// newTestHandler 只包含路由注册需要的字段，没有数据库，
+// 一旦有更新走到加载用户或处理函数，Recover 会把空指针 panic 转为错误
+func newTestHandler() *BotHandler {
+	h := &BotHandler{
+		bot:         &tgbotapi.BotAPI{Self: tgbotapi.User{ID: 1, UserName: "FishMusicBot", IsBot: true}},
+		botConfig:   &config.BotConfig{},
+		groupConfig: &config.GroupConfig{Enabled: true},
+		logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		metrics:     router.NewMetrics(),
+	}
+	h.router = h.newRouter()
+	return h
+}
+
+func groupMessage(text string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: 42},
+		Chat: &tgbotapi.Chat{ID: -100, Type: "supergroup"},
+		Text: text,
+	}}
+}
+
+func TestGroupChatterIsIgnored(t *testing.T) {
+	h := newTestHandler()
+
+	command := groupMessage("/song@OtherBot yesterday")
+	command.Message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/song@OtherBot")}}
+	fromBot := groupMessage("@FishMusicBot hi")
+	fromBot.Message.From.IsBot = true
+
+	for name, update := range map[string]tgbotapi.Update{
+		"普通聊天":         groupMessage("今天吃什么"),
+		"发给其他 Bot 的命令": command,
+		"其他 Bot 的消息":   fromBot,
+	} {
+		if err := h.router.Handle(update); err != nil {
+			t.Errorf("%s: 不应被处理，但返回 %v", name, err)
+		}
+	}
+	if stats := h.metrics.Snapshot(); len(stats) != 0 {
+		t.Errorf("被忽略的消息不应计入统计: %+v", stats)
+	}
+}
+
+func TestGroupDisabled(t *testing.T) {
+	h := newTestHandler()
+	h.groupConfig.Enabled = false
+
+	update := groupMessage("/song yesterday")
+	update.Message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/song")}}
+	if err := h.router.Handle(update); err != nil {
+		t.Errorf("关闭群组功能后不应处理群消息，但返回 %v", err)
+	}
+}
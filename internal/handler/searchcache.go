@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// searchCacheTTL 搜索结果可以翻页的时间，过期后需要重新搜索
+	searchCacheTTL = 24 * time.Hour
+	// searchCacheMaxSize 最多保存的搜索数，超过时先清理过期记录，仍然超过则删除最早的一条
+	searchCacheMaxSize = 10000
+)
+
+// searchCache 保存搜索结果消息对应的完整关键词
+// 翻页按钮的回调数据只有页码，关键词按消息查找，不受 64 字节回调数据的限制
+// 只保存在内存中，Bot 重启后旧消息的翻页按钮需要重新搜索
+type searchCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	now     func() time.Time
+	entries map[searchCacheKey]searchCacheEntry
+}
+
+type searchCacheKey struct {
+	chatID    int64
+	messageID int
+}
+
+type searchCacheEntry struct {
+	keyword string
+	expires time.Time
+}
+
+func newSearchCache() *searchCache {
+	return &searchCache{
+		ttl:     searchCacheTTL,
+		maxSize: searchCacheMaxSize,
+		now:     time.Now,
+		entries: make(map[searchCacheKey]searchCacheEntry),
+	}
+}
+
+// put 记录搜索结果消息的关键词
+func (c *searchCache) put(chatID int64, messageID int, keyword string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	key := searchCacheKey{chatID: chatID, messageID: messageID}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxSize {
+		c.cleanup(now)
+	}
+	c.entries[key] = searchCacheEntry{keyword: keyword, expires: now.Add(c.ttl)}
+}
+
+// get 查找搜索结果消息的关键词，不存在或已过期时返回 false
+func (c *searchCache) get(chatID int64, messageID int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := searchCacheKey{chatID: chatID, messageID: messageID}
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.keyword, true
+}
+
+// cleanup 删除过期记录，没有过期记录时删除最早过期的一条
+func (c *searchCache) cleanup(now time.Time) {
+	var (
+		oldest    searchCacheKey
+		oldestExp time.Time
+	)
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestExp.IsZero() || entry.expires.Before(oldestExp) {
+			oldest, oldestExp = key, entry.expires
+		}
+	}
+	if len(c.entries) >= c.maxSize {
+		delete(c.entries, oldest)
+	}
+}
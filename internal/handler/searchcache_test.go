@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchCache(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	c := newSearchCache()
+	c.now = func() time.Time { return now }
+
+	// 超过回调数据长度的关键词原样保存
+	long := strings.Repeat("周杰伦的歌", 10)
+	c.put(1, 100, long)
+	if got, ok := c.get(1, 100); !ok || got != long {
+		t.Fatalf("get = %q, %v，期望完整关键词", got, ok)
+	}
+	if _, ok := c.get(2, 100); ok {
+		t.Error("其他聊天中相同消息 ID 不应命中")
+	}
+
+	now = now.Add(searchCacheTTL)
+	if _, ok := c.get(1, 100); ok {
+		t.Error("过期后不应命中")
+	}
+}
+
+func TestSearchCacheEviction(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	c := newSearchCache()
+	c.now = func() time.Time { return now }
+	c.maxSize = 3
+
+	for i := 1; i <= 3; i++ {
+		c.put(1, i, "kw")
+		now = now.Add(time.Minute)
+	}
+
+	// 已满且没有过期记录时删除最早的一条
+	c.put(1, 4, "kw")
+	if _, ok := c.get(1, 1); ok {
+		t.Error("最早的记录应被删除")
+	}
+	for _, id := range []int{2, 3, 4} {
+		if _, ok := c.get(1, id); !ok {
+			t.Errorf("消息 %d 不应被删除", id)
+		}
+	}
+
+	// 已满时先清理所有过期记录
+	now = now.Add(searchCacheTTL)
+	c.put(1, 5, "kw")
+	if len(c.entries) != 1 {
+		t.Errorf("清理后剩余 %d 条，期望 1", len(c.entries))
+	}
+}
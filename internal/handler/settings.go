@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"html"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// userSettings 获取用户设置，读取失败时使用默认设置
+func (h *BotHandler) userSettings(user *model.User) *model.UserSetting {
+	settings, err := h.settingsRepo.Get(user.ID)
+	if err != nil {
+		log.Printf("获取用户设置失败: %v", err)
+		return model.DefaultUserSetting(user.ID)
+	}
+	return settings
+}
+
+// cmdSettings 设置命令：/settings 显示设置面板，/settings random <条件|off> 设置默认随机条件
+func (h *BotHandler) cmdSettings(message *tgbotapi.Message, user *model.User) error {
+	tr := h.tr(user)
+	sub, arg, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	arg = strings.TrimSpace(arg)
+
+	if strings.EqualFold(sub, "random") && arg != "" {
+		settings := h.userSettings(user)
+		if strings.EqualFold(arg, "off") {
+			settings.RandomFilter = ""
+			if err := h.settingsRepo.Save(settings); err != nil {
+				return h.sendHTML(message.Chat.ID, tr.T("settings.failed"))
+			}
+			return h.sendHTML(message.Chat.ID, tr.T("settings.random_cleared"))
+		}
+
+		q, unknown, err := h.parseStationFilter(arg)
+		if err != nil {
+			return err
+		}
+		if len(unknown) > 0 {
+			return h.sendUnknownCategory(message.Chat.ID, tr, unknown)
+		}
+		settings.RandomFilter = arg
+		if err := h.settingsRepo.Save(settings); err != nil {
+			return h.sendHTML(message.Chat.ID, tr.T("settings.failed"))
+		}
+		return h.sendHTML(message.Chat.ID, tr.T("settings.random_set", i18n.Params{"Filter": html.EscapeString(stationLabel(tr, q))}))
+	}
+
+	text, markup := h.buildSettingsPanel(user, h.userSettings(user))
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+// handleSettingsCallback 处理设置面板回调
+//
+//	set_show：显示设置面板
+//	set_lang：选择界面语言
+//	set_limit：切换搜索结果数量
+//	set_lyrics / set_history / set_newsongs / set_announce：开关选项
+//	set_rndclr：清除默认随机条件
+//	set_histclr[_yes]：清空播放历史（先确认）
+func (h *BotHandler) handleSettingsCallback(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	if query.Message == nil {
+		return h.answerCallback(query, tr.T("settings.private_only"), true)
+	}
+
+	settings := h.userSettings(user)
+	action := strings.TrimPrefix(query.Data, "set_")
+	notice := ""
+
+	switch action {
+	case "show":
+	case "lang":
+		text, markup := h.buildLanguagePicker(user)
+		h.editSettingsMessage(query, text, markup)
+		return h.answerCallback(query, "", false)
+	case "histclr":
+		text := tr.T("settings.clear_history_confirm")
+		markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("settings.btn.confirm_clear"), "set_histclr_yes"),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("settings.btn.back"), "set_show"),
+		))
+		h.editSettingsMessage(query, text, markup)
+		return h.answerCallback(query, "", false)
+	case "histclr_yes":
+		count, err := h.historyRepo.ClearByUser(user.ID)
+		if err != nil {
+			return h.answerCallback(query, tr.T("settings.failed"), true)
+		}
+		notice = tr.N("settings.history_cleared", count)
+	default:
+		switch action {
+		case "limit":
+			settings.SearchLimit = settings.NextSearchLimit()
+		case "lyrics":
+			settings.ShowLyrics = !settings.ShowLyrics
+		case "history":
+			settings.RecordHistory = !settings.RecordHistory
+		case "newsongs":
+			settings.NotifyNewSongs = !settings.NotifyNewSongs
+		case "announce":
+			settings.NotifyAnnouncements = !settings.NotifyAnnouncements
+		case "rndclr":
+			settings.RandomFilter = ""
+		default:
+			return h.answerCallback(query, tr.T("common.unknown_action"), true)
+		}
+		if err := h.settingsRepo.Save(settings); err != nil {
+			log.Printf("保存用户设置失败: %v", err)
+			return h.answerCallback(query, tr.T("settings.failed"), true)
+		}
+		notice = tr.T("settings.saved")
+	}
+
+	text, markup := h.buildSettingsPanel(user, settings)
+	h.editSettingsMessage(query, text, markup)
+	return h.answerCallback(query, notice, false)
+}
+
+// editSettingsMessage 原地更新设置面板
+func (h *BotHandler) editSettingsMessage(query *tgbotapi.CallbackQuery, text string, markup tgbotapi.InlineKeyboardMarkup) {
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup)
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+}
+
+// buildSettingsPanel 构建设置面板
+func (h *BotHandler) buildSettingsPanel(user *model.User, settings *model.UserSetting) (string, tgbotapi.InlineKeyboardMarkup) {
+	tr := h.tr(user)
+	toggle := func(key string, on bool, data string) tgbotapi.InlineKeyboardButton {
+		mark := "❌"
+		if on {
+			mark = "✅"
+		}
+		return tgbotapi.NewInlineKeyboardButtonData(tr.T(key)+" "+mark, data)
+	}
+
+	randomFilter := tr.T("settings.random_none")
+	if settings.RandomFilter != "" {
+		randomFilter = "<b>" + html.EscapeString(settings.RandomFilter) + "</b>"
+	}
+
+	lines := []string{
+		tr.T("settings.title"),
+		"",
+		tr.T("settings.language", i18n.Params{"Name": h.languageName(tr.Lang())}),
+		tr.N("settings.search_limit", int64(settings.SearchLimit)),
+		tr.T("settings.lyrics", i18n.Params{"State": onOffText(tr, settings.ShowLyrics)}),
+		tr.T("settings.random", i18n.Params{"Filter": randomFilter}),
+		tr.T("settings.history", i18n.Params{"State": onOffText(tr, settings.RecordHistory)}),
+		tr.T("settings.notify_new_songs", i18n.Params{"State": onOffText(tr, settings.NotifyNewSongs)}),
+		tr.T("settings.notify_announcements", i18n.Params{"State": onOffText(tr, settings.NotifyAnnouncements)}),
+		"",
+		tr.T("settings.random_hint"),
+	}
+
+	keyboard := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("settings.btn.language"), "set_lang"),
+			tgbotapi.NewInlineKeyboardButtonData(tr.N("settings.btn.search_limit", int64(settings.NextSearchLimit())), "set_limit"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			toggle("settings.btn.lyrics", settings.ShowLyrics, "set_lyrics"),
+			toggle("settings.btn.history", settings.RecordHistory, "set_history"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			toggle("settings.btn.notify_new_songs", settings.NotifyNewSongs, "set_newsongs"),
+			toggle("settings.btn.notify_announcements", settings.NotifyAnnouncements, "set_announce"),
+		),
+	}
+
+	var extra []tgbotapi.InlineKeyboardButton
+	if settings.RandomFilter != "" {
+		extra = append(extra, tgbotapi.NewInlineKeyboardButtonData(tr.T("settings.btn.clear_random"), "set_rndclr"))
+	}
+	extra = append(extra, tgbotapi.NewInlineKeyboardButtonData(tr.T("settings.btn.clear_history"), "set_histclr"))
+	keyboard = append(keyboard, extra)
+
+	return strings.Join(lines, "\n"), tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// callbackShareSong 生成歌曲分享链接
+// share_<歌曲ID>，链接为 t.me/<bot>?start=s_<分享令牌>，打开时可知道分享者
+// 令牌是随机生成的，不能通过遍历链接获取其他人的分享
+func (h *BotHandler) callbackShareSong(query *tgbotapi.CallbackQuery, user *model.User) error {
+	tr := h.tr(user)
+	songID, err := strconv.ParseUint(strings.TrimPrefix(query.Data, "share_"), 10, 32)
+	if err != nil {
+		return h.answerCallback(query, tr.T("song.invalid_id"), true)
+	}
+
+	song, err := h.getSongByID(uint(songID))
+	if err != nil {
+		return h.answerCallback(query, tr.T("song.not_found"), true)
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		return h.answerCallback(query, tr.T("share.create_failed"), true)
+	}
+	share, err := h.shareRepo.FindOrCreate(song.ID, user.ID, token)
+	if err != nil {
+		return h.answerCallback(query, tr.T("share.create_failed"), true)
+	}
+
+	// 内联消息没有所在聊天，改为私聊发送
+	chatID := query.From.ID
+	if query.Message != nil {
+		chatID = query.Message.Chat.ID
+	}
+
+	text := tr.T("share.link", i18n.Params{
+		"Artist": html.EscapeString(song.Artist),
+		"Title":  html.EscapeString(song.Title),
+		"Link":   h.startLink("s_" + share.Token),
+	})
+	if err := h.sendHTML(chatID, text); err != nil {
+		return h.answerCallback(query, tr.T("share.send_failed"), true)
+	}
+
+	return h.answerCallback(query, tr.T("share.created"), false)
+}
+
+// startSharedSong 通过分享链接打开歌曲
+func (h *BotHandler) startSharedSong(message *tgbotapi.Message, user *model.User, token string) error {
+	tr := h.tr(user)
+	if token == "" {
+		return h.sendHTML(message.Chat.ID, tr.T("share.invalid"))
+	}
+
+	share, err := h.shareRepo.FindByToken(token)
+	if err != nil || share.Song == nil {
+		return h.sendHTML(message.Chat.ID, tr.T("share.not_found"))
+	}
+
+	// 分享者自己打开不计入统计
+	if share.UserID != user.ID {
+		if err := h.shareRepo.IncrementOpens(share.ID); err != nil {
+			log.Printf("记录分享打开次数失败: %v", err)
+		}
+	}
+
+	if share.User != nil {
+		if err := h.sendHTML(message.Chat.ID, tr.T("share.shared_by", i18n.Params{
+			"Name": html.EscapeString(sharerName(share.User)),
+		})); err != nil {
+			return err
+		}
+	}
+
+	return h.sendSong(message.Chat.ID, share.Song, user)
+}
+
+// sharerName 分享者显示名，有用户名时优先显示 @用户名
+func sharerName(user *model.User) string {
+	if user.Username != "" {
+		return "@" + user.Username
+	}
+	return user.GetFullName()
+}
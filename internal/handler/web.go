@@ -1,66 +1,137 @@
 package handler
 
 import (
+	"crypto/subtle"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/user/fish-music/internal/database"
 	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
 )
 
+// webLanguageKey 登录用户界面语言在 gin.Context 中的键
+const webLanguageKey = "language"
+
 // WebHandler Web 处理器
 type WebHandler struct {
-	username  string
-	password  string
-	songRepo  *database.SongRepository
+	username   string
+	password   string
+	userRepo   *database.UserRepository
+	songRepo   *database.SongRepository
+	artistRepo *database.ArtistRepository
+	lyricRepo  *database.LyricRepository
+	locales    *i18n.Bundle
 }
 
 // NewWebHandler 创建 Web 处理器
 func NewWebHandler(
 	username, password string,
+	userRepo *database.UserRepository,
 	songRepo *database.SongRepository,
+	artistRepo *database.ArtistRepository,
+	lyricRepo *database.LyricRepository,
+	locales *i18n.Bundle,
 ) *WebHandler {
 	return &WebHandler{
-		username: username,
-		password: password,
-		songRepo: songRepo,
+		username:   username,
+		password:   password,
+		userRepo:   userRepo,
+		songRepo:   songRepo,
+		artistRepo: artistRepo,
+		lyricRepo:  lyricRepo,
+		locales:    locales,
 	}
 }
 
 // RegisterRoutes 注册路由
+// 后台需要管理员及以上角色，删除歌曲和合并歌手等无法撤销的操作只允许所有者
 func (h *WebHandler) RegisterRoutes(router *gin.Engine) {
-	// 公开路由
-	router.GET("/", h.basicAuth(h.handleIndex))
-	router.GET("/api/stats", h.basicAuth(h.apiStats))
+	admin := h.requireRole(model.RoleAdmin)
+	owner := h.requireRole(model.RoleOwner)
+
+	// 首页和统计
+	router.GET("/", admin(h.handleIndex))
+	router.GET("/api/stats", admin(h.apiStats))
 
 	// 歌曲管理
-	router.GET("/api/songs", h.basicAuth(h.apiListSongs))
-	router.GET("/api/songs/:id", h.basicAuth(h.apiGetSong))
-	router.GET("/api/songs/missing", h.basicAuth(h.apiMissingSongs))
-	router.POST("/api/songs/:id/reprocess", h.basicAuth(h.apiReprocessSong))
-	router.PUT("/api/songs/:id", h.basicAuth(h.apiUpdateSong))
-	router.DELETE("/api/songs/:id", h.basicAuth(h.apiDeleteSong))
-}
-
-// basicAuth Basic Auth 中间件
-func (h *WebHandler) basicAuth(handler gin.HandlerFunc) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		username, password, ok := c.Request.BasicAuth()
-		if !ok || username != h.username || password != h.password {
-			c.Header("WWW-Authenticate", `Basic realm="Restricted"`)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			c.Abort()
-			return
+	router.GET("/api/songs", admin(h.apiListSongs))
+	router.GET("/api/songs/:id", admin(h.apiGetSong))
+	router.GET("/api/songs/missing", admin(h.apiMissingSongs))
+	router.POST("/api/songs/:id/reprocess", admin(h.apiReprocessSong))
+	router.PUT("/api/songs/:id", admin(h.apiUpdateSong))
+	router.DELETE("/api/songs/:id", owner(h.apiDeleteSong))
+	router.GET("/api/songs/:id/lyrics", admin(h.apiGetLyrics))
+
+	// 歌手管理
+	router.GET("/api/artists", admin(h.apiListArtists))
+	router.GET("/api/artists/duplicates", admin(h.apiDuplicateArtists))
+	router.GET("/api/artists/:id", admin(h.apiGetArtist))
+	router.POST("/api/artists/:id/merge", owner(h.apiMergeArtist))
+	router.POST("/api/artists/:id/aliases", admin(h.apiAddArtistAlias))
+	router.DELETE("/api/artists/:id/aliases/:aliasId", admin(h.apiDeleteArtistAlias))
+}
+
+// requireRole Basic Auth 中间件，只允许不低于指定角色的用户访问
+func (h *WebHandler) requireRole(role string) func(gin.HandlerFunc) gin.HandlerFunc {
+	return func(handler gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			current, lang, ok := h.authenticate(c.Request)
+			if !ok {
+				c.Header("WWW-Authenticate", `Basic realm="Restricted"`)
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				c.Abort()
+				return
+			}
+			if !model.RoleAtLeast(current, role) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden", "role": current, "required": role})
+				c.Abort()
+				return
+			}
+			c.Set(webLanguageKey, lang)
+			handler(c)
 		}
-		handler(c)
 	}
 }
 
+// authenticate 校验 Basic Auth，返回登录用户的角色和界面语言
+// 配置文件中的账号视为所有者，使用默认语言；Bot 用户以 Telegram ID 和 /webtoken 生成的密码登录，角色和语言实时读取
+func (h *WebHandler) authenticate(r *http.Request) (string, string, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok || password == "" {
+		return "", "", false
+	}
+	if subtle.ConstantTimeCompare([]byte(username), []byte(h.username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(h.password)) == 1 {
+		return model.RoleOwner, "", true
+	}
+
+	telegramID, err := strconv.ParseInt(username, 10, 64)
+	if err != nil {
+		return "", "", false
+	}
+	user, err := h.userRepo.FindByTelegramID(telegramID)
+	if err != nil || user.WebToken == "" {
+		return "", "", false
+	}
+	if subtle.ConstantTimeCompare([]byte(hashWebToken(password)), []byte(user.WebToken)) != 1 {
+		return "", "", false
+	}
+	return user.Role, user.Language, true
+}
+
+// tr 当前登录用户的本地化器
+func (h *WebHandler) tr(c *gin.Context) *i18n.Localizer {
+	return h.locales.Localizer(c.GetString(webLanguageKey))
+}
+
 // handleIndex 首页
 func (h *WebHandler) handleIndex(c *gin.Context) {
 	c.HTML(http.StatusOK, "index.html", gin.H{
-		"title": "Fish Music 管理后台",
+		"title": h.tr(c).T("web.title"),
 	})
 }
 
@@ -117,13 +188,13 @@ func (h *WebHandler) apiGetSong(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的歌曲ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_song_id")})
 		return
 	}
 
 	var song model.Song
-	if err := database.DB.Where("id = ?", id).First(&song).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "歌曲不存在"})
+	if err := database.DB.Preload("Credits.Artist").Where("id = ?", id).First(&song).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": h.tr(c).T("web.song_not_found")})
 		return
 	}
 
@@ -149,7 +220,7 @@ func (h *WebHandler) apiReprocessSong(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的歌曲ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_song_id")})
 		return
 	}
 
@@ -157,7 +228,7 @@ func (h *WebHandler) apiReprocessSong(c *gin.Context) {
 	// 这里需要调用 MusicService.ReprocessMissingSong
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "重新处理任务已提交",
+		"message": h.tr(c).T("web.reprocess_submitted"),
 		"id":      id,
 	})
 }
@@ -167,7 +238,7 @@ func (h *WebHandler) apiUpdateSong(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的歌曲ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_song_id")})
 		return
 	}
 
@@ -212,13 +283,69 @@ func (h *WebHandler) apiUpdateSong(c *gin.Context) {
 			}
 		}
 
+		// 标题或歌手变化时重新生成拼音 / 罗马字搜索键
+		if input.Title != "" || input.Artist != "" {
+			var song model.Song
+			if err := database.DB.Where("id = ?", id).First(&song).Error; err == nil {
+				if input.Title != "" {
+					song.Title = input.Title
+				}
+				if input.Artist != "" {
+					song.Artist = input.Artist
+				}
+				song.UpdateSearchKeys()
+				updates["search_romanized"] = song.SearchRomanized
+				updates["search_initials"] = song.SearchInitials
+			}
+		}
+
 		if err := database.DB.Model(&model.Song{}).Where("id = ?", id).Updates(updates).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+
+		// 歌手或专辑变化时重新生成署名
+		if input.Title != "" || input.Artist != "" || input.Album != "" {
+			var song model.Song
+			if err := database.DB.Where("id = ?", id).First(&song).Error; err == nil {
+				if err := h.songRepo.SyncCredits(&song); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					return
+				}
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": h.tr(c).T("web.updated")})
+}
+
+// apiGetLyrics 获取歌曲歌词，format=lrc 时下载 LRC 文件
+func (h *WebHandler) apiGetLyrics(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_song_id")})
+		return
+	}
+
+	var song model.Song
+	if err := database.DB.Where("id = ?", id).First(&song).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": h.tr(c).T("web.song_not_found")})
+		return
+	}
+	lyric, err := h.lyricRepo.FindBySong(song.ID)
+	if err != nil || !lyric.Found() {
+		c.JSON(http.StatusNotFound, gin.H{"error": h.tr(c).T("web.no_lyrics")})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "更新成功"})
+	if c.Query("format") == "lrc" {
+		filename := fmt.Sprintf("%s - %s.lrc", song.Artist, song.Title)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(filename)))
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(lyric.LRC(&song)))
+		return
+	}
+
+	c.JSON(http.StatusOK, lyric)
 }
 
 // apiDeleteSong 删除歌曲
@@ -226,7 +353,7 @@ func (h *WebHandler) apiDeleteSong(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的歌曲ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_song_id")})
 		return
 	}
 
@@ -235,5 +362,139 @@ func (h *WebHandler) apiDeleteSong(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+	c.JSON(http.StatusOK, gin.H{"message": h.tr(c).T("web.deleted")})
+}
+
+// apiListArtists 歌手列表 API
+func (h *WebHandler) apiListArtists(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+
+	artists, total, err := h.artistRepo.List(c.Query("q"), (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"artists": artists,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// apiDuplicateArtists 疑似重复的歌手 API
+func (h *WebHandler) apiDuplicateArtists(c *gin.Context) {
+	groups, err := h.artistRepo.GetDuplicates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"groups": groups,
+		"count":  len(groups),
+	})
+}
+
+// apiGetArtist 获取歌手信息（含别名和专辑）
+func (h *WebHandler) apiGetArtist(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_artist_id")})
+		return
+	}
+
+	artist, err := h.artistRepo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": h.tr(c).T("web.artist_not_found")})
+		return
+	}
+	albums, err := h.artistRepo.GetAlbums(artist.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"artist": artist,
+		"albums": albums,
+	})
+}
+
+// apiMergeArtist 将歌手合并到另一位歌手
+func (h *WebHandler) apiMergeArtist(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_artist_id")})
+		return
+	}
+
+	var input struct {
+		Into uint `json:"into" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.artistRepo.Merge(uint(id), input.Into); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": h.tr(c).T("web.merged")})
+}
+
+// apiAddArtistAlias 添加歌手别名
+func (h *WebHandler) apiAddArtistAlias(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_artist_id")})
+		return
+	}
+
+	var input struct {
+		Alias string `json:"alias" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.artistRepo.FindByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": h.tr(c).T("web.artist_not_found")})
+		return
+	}
+	if err := h.artistRepo.AddAlias(uint(id), input.Alias); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": h.tr(c).T("web.added")})
+}
+
+// apiDeleteArtistAlias 删除歌手别名
+func (h *WebHandler) apiDeleteArtistAlias(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_artist_id")})
+		return
+	}
+	aliasID, err := strconv.ParseUint(c.Param("aliasId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.tr(c).T("web.invalid_alias_id")})
+		return
+	}
+
+	if err := h.artistRepo.RemoveAlias(uint(id), uint(aliasID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": h.tr(c).T("web.deleted")})
 }
@@ -0,0 +1,26 @@
+// Package locales Bot 界面的多语言消息目录
+//
+// 每种语言一个 YAML 文件，编译时嵌入二进制；新增语言只需添加同名文件，
+// 缺少的键会回退到简体中文。
+package locales
+
+import (
+	"embed"
+
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// DefaultLanguage 默认语言，也是其他语言缺少翻译时的回退语言
+const DefaultLanguage = "zh-CN"
+
+//go:embed *.yaml
+var files embed.FS
+
+// Load 加载所有语言的消息目录
+func Load() (*i18n.Bundle, error) {
+	bundle := i18n.NewBundle(DefaultLanguage)
+	if err := bundle.LoadFS(files, "."); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"time"
+
+	"github.com/user/fish-music/pkg/romanize"
+	"gorm.io/gorm"
+)
+
+// 歌手署名角色
+const (
+	ArtistRoleMain = "main" // 主唱
+	ArtistRoleFeat = "feat" // 合作歌手（feat.）
+)
+
+// Artist 歌手模型
+// 同一歌手的不同写法（"周杰伦"、"Jay Chou"、"周杰倫"）通过别名归到同一条记录
+type Artist struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:255;not null;uniqueIndex" json:"name"`
+	SortKey   string    `gorm:"size:255;index" json:"sort_key"` // 拼音 / 罗马字排序键，由 BeforeSave 生成
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 统计（查询时填充，不落库）
+	SongCount int64 `gorm:"->;-:migration" json:"song_count"`
+
+	// 关联
+	Aliases []ArtistAlias `gorm:"foreignKey:ArtistID" json:"aliases,omitempty"`
+}
+
+// TableName 指定表名
+func (Artist) TableName() string {
+	return "artists"
+}
+
+// BeforeSave 保存前生成排序键
+func (a *Artist) BeforeSave(tx *gorm.DB) error {
+	a.SortKey, _ = romanize.Keys(a.Name)
+	return nil
+}
+
+// ArtistAlias 歌手别名模型，Alias 存小写形式，匹配时不区分大小写
+type ArtistAlias struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ArtistID  uint      `gorm:"not null;index" json:"artist_id"`
+	Alias     string    `gorm:"size:255;not null;uniqueIndex" json:"alias"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (ArtistAlias) TableName() string {
+	return "artist_aliases"
+}
+
+// Album 专辑模型，同一歌手下专辑名唯一
+type Album struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Title     string    `gorm:"size:255;not null;uniqueIndex:idx_album_artist_title" json:"title"`
+	ArtistID  uint      `gorm:"not null;uniqueIndex:idx_album_artist_title" json:"artist_id"` // 专辑主歌手
+	Year      int       `json:"year"`
+	CoverURL  string    `gorm:"size:512" json:"cover_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 统计（查询时填充，不落库）
+	SongCount int64 `gorm:"->;-:migration" json:"song_count"`
+
+	// 关联
+	Artist *Artist `gorm:"foreignKey:ArtistID" json:"artist,omitempty"`
+}
+
+// TableName 指定表名
+func (Album) TableName() string {
+	return "albums"
+}
+
+// SongArtist 歌曲署名（歌曲与歌手的多对多关联）
+type SongArtist struct {
+	SongID   uint   `gorm:"primaryKey" json:"song_id"`
+	ArtistID uint   `gorm:"primaryKey;index" json:"artist_id"`
+	Role     string `gorm:"primaryKey;size:20" json:"role"`
+	Position int    `gorm:"default:0" json:"position"` // 同一角色内的署名顺序
+
+	// 关联
+	Artist *Artist `gorm:"foreignKey:ArtistID" json:"artist,omitempty"`
+}
+
+// TableName 指定表名
+func (SongArtist) TableName() string {
+	return "song_artists"
+}
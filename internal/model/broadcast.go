@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+)
+
+// 公告状态
+const (
+	BroadcastDraft     = "draft"     // 等待确认
+	BroadcastSending   = "sending"   // 发送中，Bot 重启后继续
+	BroadcastDone      = "done"      // 已发送完毕
+	BroadcastCancelled = "cancelled" // 已取消
+)
+
+// Broadcast 管理员公告模型
+// 按用户 ID 从小到大发送，LastUserID 记录进度，Bot 重启后从中断处继续
+type Broadcast struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	CreatedBy uint   `gorm:"not null;index" json:"created_by"` // 创建者用户 ID
+	ChatID    int64  `gorm:"not null" json:"chat_id"`          // 接收预览和发送报告的聊天
+	Status    string `gorm:"size:20;default:draft;index" json:"status"`
+
+	// 内容：回复某条消息发起时复制该消息，否则发送 Text（HTML）
+	Text            string `gorm:"type:text" json:"text"`
+	SourceChatID    int64  `json:"source_chat_id"`
+	SourceMessageID int    `json:"source_message_id"`
+
+	// 发送对象，创建时确定，重启后保持不变
+	ActiveSince *time.Time `json:"active_since"`                      // 只发给此后活跃过的用户，为空表示所有用户
+	Language    string     `gorm:"size:10" json:"language"`           // 只发给该界面语言的用户，为空表示所有语言
+	MembersOnly bool       `gorm:"default:false" json:"members_only"` // 只发给获准使用的用户（白名单、邀请制）
+
+	// 进度
+	Total      int64 `gorm:"default:0" json:"total"`        // 创建时统计的接收人数
+	Sent       int64 `gorm:"default:0" json:"sent"`         // 发送成功
+	Failed     int64 `gorm:"default:0" json:"failed"`       // 发送失败
+	Blocked    int64 `gorm:"default:0" json:"blocked"`      // 已屏蔽 Bot 或注销的用户
+	LastUserID uint  `gorm:"default:0" json:"last_user_id"` // 最后处理的用户 ID
+
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// 关联
+	Creator *User `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+}
+
+// TableName 指定表名
+func (Broadcast) TableName() string {
+	return "broadcasts"
+}
+
+// IsCopy 是否通过复制消息发送
+func (b *Broadcast) IsCopy() bool {
+	return b.SourceMessageID != 0
+}
+
+// Processed 已处理的接收人数
+func (b *Broadcast) Processed() int64 {
+	return b.Sent + b.Failed + b.Blocked
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// 群组下载权限
+const (
+	GroupDownloadAll    = "all"    // 所有成员可触发下载
+	GroupDownloadAdmins = "admins" // 仅群管理员可触发下载
+	GroupDownloadOff    = "off"    // 禁止在群内下载
+)
+
+// GroupSetting 群组设置模型
+type GroupSetting struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ChatID         int64     `gorm:"uniqueIndex;not null" json:"chat_id"`           // Telegram 群组 ID
+	Title          string    `gorm:"size:255" json:"title"`                         // 群组名称
+	DownloadPolicy string    `gorm:"size:20;default:admins" json:"download_policy"` // 下载权限: all, admins, off
+	CleanupAfter   int       `gorm:"default:0" json:"cleanup_after"`                // Bot 消息自动清理时间（秒），0 表示不清理
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (GroupSetting) TableName() string {
+	return "group_settings"
+}
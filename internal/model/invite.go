@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+)
+
+// InviteCode 邀请码模型
+// 邀请制下新用户通过 /start <邀请码> 或 t.me/<bot>?start=<邀请码> 加入
+type InviteCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Code      string     `gorm:"size:32;uniqueIndex;not null" json:"code"`
+	CreatedBy uint       `gorm:"not null;index" json:"created_by"`   // 创建者用户 ID
+	MaxUses   int        `gorm:"not null;default:1" json:"max_uses"` // 最多使用次数
+	Uses      int        `gorm:"not null;default:0" json:"uses"`     // 已使用次数
+	ExpiresAt *time.Time `json:"expires_at"`                         // 过期时间，为空表示不过期
+	Revoked   bool       `gorm:"default:false" json:"revoked"`       // 是否已作废
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// 关联
+	Creator *User `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+}
+
+// TableName 指定表名
+func (InviteCode) TableName() string {
+	return "invite_codes"
+}
+
+// IsExpired 是否已过期
+func (c *InviteCode) IsExpired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// IsUsedUp 使用次数是否已满
+func (c *InviteCode) IsUsedUp() bool {
+	return c.Uses >= c.MaxUses
+}
+
+// RemainingUses 剩余可用次数
+func (c *InviteCode) RemainingUses() int {
+	if c.IsUsedUp() {
+		return 0
+	}
+	return c.MaxUses - c.Uses
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/user/fish-music/pkg/lrc"
+)
+
+// 歌词来源
+const (
+	LyricSourceNetease = "netease" // 搜索 API
+	LyricSourceUpload  = "upload"  // 管理员上传的 .lrc 文件
+	LyricSourceNone    = "none"    // 已查询过但没有找到歌词
+)
+
+// SongLyric 歌曲歌词模型
+// 按行存储带时间轴的歌词，方便导出为 .lrc 或嵌入音频标签；
+// 纯文本同时写入 Song.Lyrics 供全文搜索使用
+type SongLyric struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	SongID    uint       `gorm:"not null;uniqueIndex" json:"song_id"`
+	Source    string     `gorm:"size:20;not null" json:"source"`
+	Synced    bool       `gorm:"default:false" json:"synced"` // 是否带时间轴
+	Lines     LyricLines `gorm:"type:jsonb" json:"lines"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SongLyric) TableName() string {
+	return "song_lyrics"
+}
+
+// Found 是否有歌词内容
+func (l *SongLyric) Found() bool {
+	return l.Source != LyricSourceNone && len(l.Lines) > 0
+}
+
+// LRC 导出为 LRC 格式
+func (l *SongLyric) LRC(song *Song) string {
+	lyrics := &lrc.Lyrics{
+		Meta: map[string]string{
+			"ti": song.Title,
+			"ar": song.Artist,
+		},
+		Lines: l.Lines,
+	}
+	if song.Album != "" {
+		lyrics.Meta["al"] = song.Album
+	}
+	return lyrics.String()
+}
+
+// LyricLines 歌词行，以 JSON 存储
+type LyricLines []lrc.Line
+
+// Value 实现 driver.Valuer
+func (l LyricLines) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner
+func (l *LyricLines) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return fmt.Errorf("无法解析歌词数据: %T", value)
+	}
+	return json.Unmarshal(data, l)
+}
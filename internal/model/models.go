@@ -3,7 +3,23 @@ package model
 // Models 所有模型列表（用于自动迁移）
 var Models = []interface{}{
 	&Song{},
+	&SongLyric{},
+	&Artist{},
+	&ArtistAlias{},
+	&Album{},
+	&SongArtist{},
 	&User{},
+	&UserSetting{},
+	&InviteCode{},
+	&Broadcast{},
 	&Favorite{},
 	&History{},
+	&GroupSetting{},
+	&Playlist{},
+	&PlaylistItem{},
+	&PlaylistCollaborator{},
+	&SongShare{},
+	&PlayQueue{},
+	&QueueItem{},
+	&QueuePlayed{},
 }
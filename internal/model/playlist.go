@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+)
+
+// Playlist 用户歌单模型
+type Playlist struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 分享
+	ShareToken      string `gorm:"size:32;index" json:"share_token"`      // 分享令牌，空表示未分享
+	IsCollaborative bool   `gorm:"default:false" json:"is_collaborative"` // 是否允许协作者添加歌曲
+
+	// 统计（查询时填充，不落库）
+	SongCount int64 `gorm:"->;-:migration" json:"song_count"`
+
+	// 关联
+	User  *User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Items []PlaylistItem `gorm:"foreignKey:PlaylistID" json:"items,omitempty"`
+}
+
+// TableName 指定表名
+func (Playlist) TableName() string {
+	return "playlists"
+}
+
+// IsShared 是否已开启分享链接
+func (p *Playlist) IsShared() bool {
+	return p.ShareToken != ""
+}
+
+// PlaylistCollaborator 歌单协作者模型（通过分享链接加入协作歌单的用户）
+type PlaylistCollaborator struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PlaylistID uint      `gorm:"not null;uniqueIndex:idx_playlist_collaborator" json:"playlist_id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_playlist_collaborator;index" json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (PlaylistCollaborator) TableName() string {
+	return "playlist_collaborators"
+}
+
+// PlaylistItem 歌单条目模型
+type PlaylistItem struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PlaylistID uint      `gorm:"not null;uniqueIndex:idx_playlist_song;index:idx_playlist_position" json:"playlist_id"`
+	SongID     uint      `gorm:"not null;uniqueIndex:idx_playlist_song" json:"song_id"`
+	Position   int       `gorm:"not null;index:idx_playlist_position" json:"position"` // 歌单内顺序，从 1 开始，同一歌单内唯一
+	CreatedAt  time.Time `json:"created_at"`
+
+	// 关联
+	Song *Song `gorm:"foreignKey:SongID" json:"song,omitempty"`
+}
+
+// TableName 指定表名
+func (PlaylistItem) TableName() string {
+	return "playlist_items"
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+)
+
+// 循环模式
+const (
+	RepeatOff = "off" // 不循环
+	RepeatOne = "one" // 单曲循环
+	RepeatAll = "all" // 列表循环
+)
+
+// 自动填充来源
+const (
+	QueueSourceLibrary   = "library"   // 全库随机
+	QueueSourceFavorites = "favorites" // 我的收藏
+	QueueSourcePlaylist  = "playlist"  // 歌单，SourceRef 为歌单 ID
+	QueueSourceArtist    = "artist"    // 歌手，SourceRef 为歌手 ID
+	QueueSourceAlbum     = "album"     // 专辑，SourceRef 为专辑 ID
+	QueueSourceStation   = "station"   // 电台，SourceRef 为筛选条件（搜索语法，如 "genre:摇滚 year:2000-2009"）
+)
+
+// PlayQueue 用户播放队列模型，每个用户一个队列，保存在数据库中，重启后不丢失
+type PlayQueue struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"uniqueIndex;not null" json:"user_id"`
+	Current    int       `gorm:"default:0" json:"current"`               // 当前播放位置，0 表示尚未开始
+	Shuffle    bool      `gorm:"default:false" json:"shuffle"`           // 随机播放
+	RepeatMode string    `gorm:"size:10;default:off" json:"repeat_mode"` // 循环模式
+	Source     string    `gorm:"size:20;default:library" json:"source"`  // 队列播完后的自动填充来源
+	SourceRef  string    `gorm:"size:255" json:"source_ref"`             // 来源参数：歌单 ID、歌手 ID、专辑 ID 或电台筛选条件
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (PlayQueue) TableName() string {
+	return "play_queues"
+}
+
+// NextRepeatMode 按 不循环 → 列表循环 → 单曲循环 的顺序切换
+func (q *PlayQueue) NextRepeatMode() string {
+	switch q.RepeatMode {
+	case RepeatAll:
+		return RepeatOne
+	case RepeatOne:
+		return RepeatOff
+	default:
+		return RepeatAll
+	}
+}
+
+// QueueItem 播放队列条目模型，Position 从 1 开始，同一用户内唯一
+type QueueItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index:idx_queue_position" json:"user_id"`
+	SongID    uint      `gorm:"not null" json:"song_id"`
+	Position  int       `gorm:"not null;index:idx_queue_position" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// 关联
+	Song *Song `gorm:"foreignKey:SongID" json:"song,omitempty"`
+}
+
+// TableName 指定表名
+func (QueueItem) TableName() string {
+	return "play_queue_items"
+}
+
+// QueuePlayed 已从队列中清理掉的已播放歌曲
+// 队列只保留最近的播放条目，自动填充时同时排除这里的歌曲，来源中的歌曲全部播完前不会重复；切换来源或清空队列时重置
+type QueuePlayed struct {
+	UserID uint `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	SongID uint `gorm:"primaryKey;autoIncrement:false" json:"song_id"`
+}
+
+// TableName 指定表名
+func (QueuePlayed) TableName() string {
+	return "play_queue_played"
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+)
+
+// 搜索结果每页数量可选值
+var SearchLimitOptions = []int{5, 10, 20}
+
+// UserSetting 用户偏好设置模型
+// 界面语言保存在 users.language，这里只保存其他偏好
+// 布尔字段不设 gorm 默认值，否则保存 false 时会被数据库默认值覆盖
+type UserSetting struct {
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	UserID              uint      `gorm:"uniqueIndex;not null" json:"user_id"`
+	SearchLimit         int       `json:"search_limit"`                  // 搜索结果每页数量
+	ShowLyrics          bool      `json:"show_lyrics"`                   // 歌曲卡片是否显示歌词按钮
+	RandomFilter        string    `gorm:"size:255" json:"random_filter"` // /random 不带参数时的默认筛选条件，如 "日语 2000s"
+	RecordHistory       bool      `json:"record_history"`                // 是否记录播放历史
+	NotifyNewSongs      bool      `json:"notify_new_songs"`              // 收藏过的歌手有新歌入库时通知
+	NotifyAnnouncements bool      `json:"notify_announcements"`          // 接收管理员公告
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserSetting) TableName() string {
+	return "user_settings"
+}
+
+// DefaultUserSetting 用户还没有修改过设置时使用的默认值
+func DefaultUserSetting(userID uint) *UserSetting {
+	return &UserSetting{
+		UserID:              userID,
+		SearchLimit:         10,
+		ShowLyrics:          true,
+		RecordHistory:       true,
+		NotifyAnnouncements: true,
+	}
+}
+
+// NextSearchLimit 循环切换到下一个搜索结果数量
+func (s *UserSetting) NextSearchLimit() int {
+	for i, limit := range SearchLimitOptions {
+		if limit == s.SearchLimit {
+			return SearchLimitOptions[(i+1)%len(SearchLimitOptions)]
+		}
+	}
+	return SearchLimitOptions[0]
+}
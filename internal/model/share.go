@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// SongShare 歌曲分享记录模型
+// 每个用户分享同一首歌只生成一条记录，分享链接为 t.me/<bot>?start=s_<Token>
+type SongShare struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Token     string    `gorm:"size:32;uniqueIndex" json:"token"` // 随机分享令牌，链接中不暴露自增 ID
+	SongID    uint      `gorm:"not null;uniqueIndex:idx_song_share_user" json:"song_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_song_share_user;index" json:"user_id"` // 分享者
+	OpenCount int64     `gorm:"default:0" json:"open_count"`                                   // 链接被打开次数
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 关联
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Song *Song `gorm:"foreignKey:SongID" json:"song,omitempty"`
+}
+
+// TableName 指定表名
+func (SongShare) TableName() string {
+	return "song_shares"
+}
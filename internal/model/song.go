@@ -3,6 +3,9 @@ package model
 import (
 	"fmt"
 	"time"
+
+	"github.com/user/fish-music/pkg/romanize"
+	"gorm.io/gorm"
 )
 
 // Song 歌曲模型
@@ -27,6 +30,14 @@ type Song struct {
 	Genre       string    `gorm:"size:50" json:"genre"`                                    // 歌曲类型
 	Language    string    `gorm:"size:50" json:"language"`                                  // 歌曲语言
 
+	// 规范化的专辑和歌手署名，由 SongRepository.SyncCredits 根据 Artist / Album 字段生成
+	AlbumID *uint        `gorm:"index" json:"album_id"`
+	Credits []SongArtist `gorm:"foreignKey:SongID" json:"credits,omitempty"`
+
+	// 搜索键（拼音 / 罗马字），由 UpdateSearchKeys 生成
+	SearchRomanized string `gorm:"size:512" json:"-"` // 完整拼音，如 "daoxiang zhoujielun"
+	SearchInitials  string `gorm:"size:255" json:"-"` // 首字母，如 "dx zjl"
+
 	// 状态
 	IsMissing   bool      `gorm:"default:false" json:"is_missing"`                        // 是否需要补档
 	Status      string    `gorm:"size:20;default:active" json:"status"`                    // 状态: active, missing, processing
@@ -41,6 +52,20 @@ func (Song) TableName() string {
 	return "songs"
 }
 
+// BeforeSave 保存前自动生成搜索键
+func (s *Song) BeforeSave(tx *gorm.DB) error {
+	s.UpdateSearchKeys()
+	return nil
+}
+
+// UpdateSearchKeys 根据标题和歌手生成拼音 / 罗马字搜索键
+func (s *Song) UpdateSearchKeys() {
+	titleFull, titleInitials := romanize.Keys(s.Title)
+	artistFull, artistInitials := romanize.Keys(s.Artist)
+	s.SearchRomanized = titleFull + " " + artistFull
+	s.SearchInitials = titleInitials + " " + artistInitials
+}
+
 // SongMetadata 歌曲元数据结构（用于 JSON 序列化）
 type SongMetadata struct {
 	CountryCode string `json:"country_code"`
@@ -49,40 +74,46 @@ type SongMetadata struct {
 	Lyrics      string `json:"lyrics"`
 }
 
-// GetCountryEmoji 获取国家 Emoji
-func (s *Song) GetCountryEmoji() string {
-	emojiMap := map[string]string{
-		"CN": "🇨🇳",
-		"JP": "🇯🇵",
-		"US": "🇺🇸",
-		"UK": "🇬🇧",
-		"KR": "🇰🇷",
-		"DE": "🇩🇪",
-		"FR": "🇫🇷",
-		"IT": "🇮🇹",
-		"ES": "🇪🇸",
-		"RU": "🇷🇺",
-		"CA": "🇨🇦",
-		"AU": "🇦🇺",
-		"BR": "🇧🇷",
-		"MX": "🇲🇽",
-		"IN": "🇮🇳",
-		"TW": "🇹🇼",
-		"HK": "🇭🇰",
-		"SG": "🇸🇬",
-		"MY": "🇲🇾",
-		"TH": "🇹🇭",
-		"VN": "🇻🇳",
-		"ID": "🇮🇩",
-		"PH": "🇵🇭",
-	}
+// countryEmojis 国家代码对应的旗帜 Emoji
+var countryEmojis = map[string]string{
+	"CN": "🇨🇳",
+	"JP": "🇯🇵",
+	"US": "🇺🇸",
+	"UK": "🇬🇧",
+	"KR": "🇰🇷",
+	"DE": "🇩🇪",
+	"FR": "🇫🇷",
+	"IT": "🇮🇹",
+	"ES": "🇪🇸",
+	"RU": "🇷🇺",
+	"CA": "🇨🇦",
+	"AU": "🇦🇺",
+	"BR": "🇧🇷",
+	"MX": "🇲🇽",
+	"IN": "🇮🇳",
+	"TW": "🇹🇼",
+	"HK": "🇭🇰",
+	"SG": "🇸🇬",
+	"MY": "🇲🇾",
+	"TH": "🇹🇭",
+	"VN": "🇻🇳",
+	"ID": "🇮🇩",
+	"PH": "🇵🇭",
+}
 
-	if emoji, ok := emojiMap[s.CountryCode]; ok {
+// CountryEmoji 根据国家代码获取旗帜 Emoji，未知时返回 🌍
+func CountryEmoji(code string) string {
+	if emoji, ok := countryEmojis[code]; ok {
 		return emoji
 	}
 	return "🌍"
 }
 
+// GetCountryEmoji 获取国家 Emoji
+func (s *Song) GetCountryEmoji() string {
+	return CountryEmoji(s.CountryCode)
+}
+
 // GetYearText 获取年份文本（中文格式）
 func (s *Song) GetYearText() string {
 	if s.Year > 0 {
@@ -5,6 +5,39 @@ import (
 	"time"
 )
 
+// 用户角色，权限从高到低；每个角色拥有低于它的角色的全部权限
+const (
+	RoleOwner    = "owner"    // 所有者，即 bot.admin_id，可以管理其他用户的角色
+	RoleAdmin    = "admin"    // 管理员：Cookies、Web 后台
+	RoleUploader = "uploader" // 上传者：发送链接下载、上传歌词
+	RoleListener = "listener" // 听众：搜索和播放已有歌曲
+	RoleBanned   = "banned"   // 已封禁，Bot 不再响应
+)
+
+// roleRank 角色等级，数值越大权限越高
+var roleRank = map[string]int{
+	RoleBanned:   0,
+	RoleListener: 1,
+	RoleUploader: 2,
+	RoleAdmin:    3,
+	RoleOwner:    4,
+}
+
+// IsValidRole 是否为已知角色
+func IsValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RoleAtLeast 角色 role 是否不低于 min，未知角色视为听众
+func RoleAtLeast(role, min string) bool {
+	rank, ok := roleRank[role]
+	if !ok {
+		rank = roleRank[RoleListener]
+	}
+	return rank >= roleRank[min]
+}
+
 // User 用户模型
 type User struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
@@ -12,9 +45,12 @@ type User struct {
 	Username  string    `gorm:"size:255" json:"username"`                  // Telegram 用户名
 	FirstName string    `gorm:"size:255" json:"first_name"`                // 名字
 	LastName  string    `gorm:"size:255" json:"last_name"`                 // 姓氏
-	Language  string    `gorm:"size:10;default:zh" json:"language"`        // 语言设置
-	IsAdmin   bool      `gorm:"default:false" json:"is_admin"`             // 是否管理员
+	Language  string    `gorm:"size:10;default:zh" json:"language"`        // 界面语言，如 zh-CN、zh-TW、en
+	Role      string    `gorm:"size:20;default:listener;index" json:"role"` // 角色: owner, admin, uploader, listener, banned
+	WebToken  string    `gorm:"size:64" json:"-"`                          // Web 后台登录令牌的 SHA-256，为空表示未开通
 	IsActive  bool      `gorm:"default:true" json:"is_active"`             // 是否激活
+	Member    bool      `gorm:"default:false" json:"member"`               // 白名单或邀请制下是否已获准使用
+	InvitedBy *uint     `json:"invited_by,omitempty"`                      // 邀请人用户 ID
 	LastSeen  time.Time `json:"last_seen"`                                 // 最后活跃时间
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -42,3 +78,13 @@ func (u *User) GetFullName() string {
 	}
 	return fmt.Sprintf("User_%d", u.TelegramID)
 }
+
+// HasRole 用户角色是否不低于 role
+func (u *User) HasRole(role string) bool {
+	return RoleAtLeast(u.Role, role)
+}
+
+// IsBanned 是否已被封禁
+func (u *User) IsBanned() bool {
+	return u.Role == RoleBanned
+}
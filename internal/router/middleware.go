@@ -0,0 +1,248 @@
+package router
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+)
+
+// Recover 把处理函数中的 panic 转为错误，避免单条更新拖垮整个 Bot
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					err = fmt.Errorf("处理 %s 时发生 panic: %v\n%s", c.Route, p, debug.Stack())
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// Logger 结构化记录每条更新的处理结果，成功为 Debug 级别，失败为 Error 级别
+func Logger(logger *slog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			start := time.Now()
+			err := next(c)
+
+			attrs := []any{
+				slog.Int("update_id", c.Update.UpdateID),
+				slog.String("route", c.Route),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if from := c.From(); from != nil {
+				attrs = append(attrs, slog.Int64("user_id", from.ID))
+			}
+			if chat := c.Chat(); chat != nil {
+				attrs = append(attrs, slog.Int64("chat_id", chat.ID))
+			}
+			if err != nil {
+				logger.Error("处理更新失败", append(attrs, slog.Any("error", err))...)
+			} else {
+				logger.Debug("处理更新", attrs...)
+			}
+			return err
+		}
+	}
+}
+
+// UserLoader 根据 Telegram 用户获取或创建数据库用户
+type UserLoader func(from *tgbotapi.User) (*model.User, error)
+
+// LoadUser 加载发送者对应的用户，填充 Context.User
+func LoadUser(load UserLoader) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			from := c.From()
+			if from == nil {
+				return next(c)
+			}
+			user, err := load(from)
+			if err != nil {
+				return fmt.Errorf("获取用户失败: %w", err)
+			}
+			c.User = user
+			return next(c)
+		}
+	}
+}
+
+// Require 只有 allow 返回 true 时才继续处理，否则交给 deny，用于管理员等权限检查
+func Require(allow Matcher, deny HandlerFunc) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if !allow(c) {
+				return deny(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RateLimit 限制每个用户在 window 内最多处理 limit 条消息和回调，limit <= 0 表示不限制
+// 超出限制时只在第一次调用 onLimited 提示用户，之后的更新直接丢弃；
+// inline 查询随输入频繁触发，不计入限流
+func RateLimit(limit int, window time.Duration, onLimited HandlerFunc) Middleware {
+	if limit <= 0 {
+		return func(next HandlerFunc) HandlerFunc { return next }
+	}
+	limiter := newLimiter(limit, window)
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			from := c.From()
+			if from == nil || (c.Message == nil && c.CallbackQuery == nil) {
+				return next(c)
+			}
+			allowed, notify := limiter.allow(from.ID)
+			if allowed {
+				return next(c)
+			}
+			if notify && onLimited != nil {
+				return onLimited(c)
+			}
+			return nil
+		}
+	}
+}
+
+// limiter 固定窗口计数限流
+type limiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	now     func() time.Time
+	windows map[int64]*limitWindow
+}
+
+type limitWindow struct {
+	start time.Time
+	count int
+}
+
+// limiterCleanupSize 记录的用户数超过此值时清理已过期的窗口
+const limiterCleanupSize = 10000
+
+func newLimiter(limit int, window time.Duration) *limiter {
+	return &limiter{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		windows: make(map[int64]*limitWindow),
+	}
+}
+
+// allow 记录一次请求，返回是否允许，以及是否为本窗口内第一次被拒绝
+func (l *limiter) allow(key int64) (allowed, firstDenied bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		if len(l.windows) >= limiterCleanupSize {
+			l.cleanup(now)
+		}
+		w = &limitWindow{start: now}
+		l.windows[key] = w
+	}
+
+	w.count++
+	if w.count <= l.limit {
+		return true, false
+	}
+	return false, w.count == l.limit+1
+}
+
+// cleanup 删除已过期的窗口
+func (l *limiter) cleanup(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// RouteStats 单个路由的处理统计
+type RouteStats struct {
+	Route  string
+	Count  int64
+	Errors int64
+	Total  time.Duration
+	Max    time.Duration
+}
+
+// Average 平均处理时间
+func (s RouteStats) Average() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Metrics 按路由统计处理次数、错误数和耗时
+type Metrics struct {
+	mu     sync.Mutex
+	routes map[string]*RouteStats
+}
+
+// NewMetrics 创建统计
+func NewMetrics() *Metrics {
+	return &Metrics{routes: make(map[string]*RouteStats)}
+}
+
+// Middleware 记录处理耗时的中间件
+func (m *Metrics) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			start := time.Now()
+			err := next(c)
+			m.observe(c.Route, time.Since(start), err != nil)
+			return err
+		}
+	}
+}
+
+func (m *Metrics) observe(route string, duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.routes[route]
+	if !ok {
+		stats = &RouteStats{Route: route}
+		m.routes[route] = stats
+	}
+	stats.Count++
+	if failed {
+		stats.Errors++
+	}
+	stats.Total += duration
+	if duration > stats.Max {
+		stats.Max = duration
+	}
+}
+
+// Snapshot 当前统计的副本，按处理次数从多到少排序
+func (m *Metrics) Snapshot() []RouteStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]RouteStats, 0, len(m.routes))
+	for _, stats := range m.routes {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Route < result[j].Route
+	})
+	return result
+}
@@ -0,0 +1,216 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+)
+
+func TestRecover(t *testing.T) {
+	r := New()
+	r.Use(Recover())
+	r.Command("boom", func(c *Context) error { panic("oops") })
+
+	err := r.Handle(textUpdate("private", "/boom"))
+	if err == nil || !strings.Contains(err.Error(), "oops") || !strings.Contains(err.Error(), "/boom") {
+		t.Errorf("Recover 返回 %v，期望包含 panic 内容和路由名", err)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	r := New()
+	r.Use(Logger(logger))
+	r.Command("ok", func(c *Context) error { return nil })
+	r.Command("fail", func(c *Context) error { return errors.New("db down") })
+
+	r.Handle(textUpdate("private", "/ok"))
+	r.Handle(textUpdate("private", "/fail"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("期望 2 条日志，实际 %d 条:\n%s", len(lines), buf.String())
+	}
+	for _, want := range []string{"level=DEBUG", "route=/ok", "user_id=42", "chat_id=42"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("成功日志缺少 %q: %s", want, lines[0])
+		}
+	}
+	for _, want := range []string{"level=ERROR", "route=/fail", `error="db down"`} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("失败日志缺少 %q: %s", want, lines[1])
+		}
+	}
+}
+
+func TestLoadUser(t *testing.T) {
+	var loaded *model.User
+	r := New()
+	r.Use(LoadUser(func(from *tgbotapi.User) (*model.User, error) {
+		return &model.User{ID: 1, TelegramID: from.ID}, nil
+	}))
+	r.Command("start", func(c *Context) error {
+		loaded = c.User
+		return nil
+	})
+
+	if err := r.Handle(textUpdate("private", "/start")); err != nil {
+		t.Fatalf("Handle 返回错误: %v", err)
+	}
+	if loaded == nil || loaded.TelegramID != 42 {
+		t.Errorf("Context.User = %+v，期望 TelegramID 42", loaded)
+	}
+}
+
+func TestLoadUserError(t *testing.T) {
+	called := false
+	r := New()
+	r.Use(LoadUser(func(from *tgbotapi.User) (*model.User, error) {
+		return nil, errors.New("db down")
+	}))
+	r.Command("start", func(c *Context) error {
+		called = true
+		return nil
+	})
+
+	err := r.Handle(textUpdate("private", "/start"))
+	if err == nil || !strings.Contains(err.Error(), "db down") {
+		t.Errorf("Handle 返回 %v，期望加载用户失败的错误", err)
+	}
+	if called {
+		t.Error("加载用户失败时不应调用处理函数")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	var got []string
+	isAdmin := func(c *Context) bool { return c.From().ID == 1 }
+	r := New()
+	r.Command("cookies", record(&got, "cookies"), Require(isAdmin, record(&got, "denied")))
+
+	r.Handle(textUpdate("private", "/cookies"))
+
+	admin := textUpdate("private", "/cookies")
+	admin.Message.From.ID = 1
+	r.Handle(admin)
+
+	want := []string{"denied", "cookies"}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("结果 = %v，期望 %v", got, want)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	handled, limited := 0, 0
+	r := New()
+	r.Use(RateLimit(2, time.Minute, func(c *Context) error {
+		limited++
+		return nil
+	}))
+	r.Message("search", Any, func(c *Context) error {
+		handled++
+		return nil
+	})
+	r.Inline(func(c *Context) error {
+		handled++
+		return nil
+	})
+
+	for i := 0; i < 5; i++ {
+		r.Handle(textUpdate("private", "hello"))
+	}
+	if handled != 2 || limited != 1 {
+		t.Errorf("处理 %d 条、提示 %d 次，期望处理 2 条、只提示 1 次", handled, limited)
+	}
+
+	// 其他用户不受影响
+	other := textUpdate("private", "hello")
+	other.Message.From.ID = 7
+	r.Handle(other)
+	if handled != 3 {
+		t.Errorf("其他用户的消息应被处理，处理数 = %d", handled)
+	}
+
+	// inline 查询不计入限流
+	r.Handle(tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: &tgbotapi.User{ID: 42}}})
+	if handled != 4 {
+		t.Errorf("inline 查询应不受限流，处理数 = %d", handled)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	handled := 0
+	r := New()
+	r.Use(RateLimit(0, time.Minute, nil))
+	r.Message("search", Any, func(c *Context) error {
+		handled++
+		return nil
+	})
+
+	for i := 0; i < 100; i++ {
+		r.Handle(textUpdate("private", "hello"))
+	}
+	if handled != 100 {
+		t.Errorf("limit 为 0 时不应限流，处理数 = %d", handled)
+	}
+}
+
+func TestLimiterWindow(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	l := newLimiter(1, time.Minute)
+	l.now = func() time.Time { return now }
+
+	steps := []struct {
+		advance     time.Duration
+		allowed     bool
+		firstDenied bool
+	}{
+		{0, true, false},
+		{10 * time.Second, false, true},
+		{10 * time.Second, false, false},
+		{40 * time.Second, true, false}, // 进入新窗口
+		{0, false, true},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		allowed, firstDenied := l.allow(42)
+		if allowed != step.allowed || firstDenied != step.firstDenied {
+			t.Errorf("第 %d 次: allow = (%v, %v)，期望 (%v, %v)", i+1, allowed, firstDenied, step.allowed, step.firstDenied)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	r := New()
+	r.Use(metrics.Middleware())
+	r.Command("ok", func(c *Context) error { return nil })
+	r.Command("fail", func(c *Context) error { return errors.New("boom") })
+
+	for i := 0; i < 3; i++ {
+		r.Handle(textUpdate("private", "/ok"))
+	}
+	r.Handle(textUpdate("private", "/fail"))
+
+	snapshot := metrics.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("期望 2 个路由的统计，实际 %d 个", len(snapshot))
+	}
+	if snapshot[0].Route != "/ok" || snapshot[0].Count != 3 || snapshot[0].Errors != 0 {
+		t.Errorf("/ok 统计 = %+v", snapshot[0])
+	}
+	if snapshot[1].Route != "/fail" || snapshot[1].Count != 1 || snapshot[1].Errors != 1 {
+		t.Errorf("/fail 统计 = %+v", snapshot[1])
+	}
+	if snapshot[0].Max < snapshot[0].Average() {
+		t.Errorf("最长耗时 %v 不应小于平均耗时 %v", snapshot[0].Max, snapshot[0].Average())
+	}
+}
@@ -0,0 +1,265 @@
+// Package router 把 Telegram 更新分发到命令、回调和消息处理函数，并支持中间件
+package router
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+)
+
+// Context 单条更新的处理上下文
+type Context struct {
+	Update             tgbotapi.Update
+	Message            *tgbotapi.Message
+	CallbackQuery      *tgbotapi.CallbackQuery
+	InlineQuery        *tgbotapi.InlineQuery
+	ChosenInlineResult *tgbotapi.ChosenInlineResult
+
+	// Route 匹配到的路由名，如 /start、cb:play_，用于日志和统计
+	Route string
+	// User 发送者对应的用户，由 LoadUser 中间件填充
+	User *model.User
+}
+
+// NewContext 根据更新创建上下文
+func NewContext(update tgbotapi.Update) *Context {
+	return &Context{
+		Update:             update,
+		Message:            update.Message,
+		CallbackQuery:      update.CallbackQuery,
+		InlineQuery:        update.InlineQuery,
+		ChosenInlineResult: update.ChosenInlineResult,
+	}
+}
+
+// From 更新的发送者，频道消息等没有发送者时返回 nil
+func (c *Context) From() *tgbotapi.User {
+	switch {
+	case c.Message != nil:
+		return c.Message.From
+	case c.CallbackQuery != nil:
+		return c.CallbackQuery.From
+	case c.InlineQuery != nil:
+		return c.InlineQuery.From
+	case c.ChosenInlineResult != nil:
+		return c.ChosenInlineResult.From
+	}
+	return nil
+}
+
+// Chat 更新所在的聊天，inline 查询等没有聊天时返回 nil
+func (c *Context) Chat() *tgbotapi.Chat {
+	switch {
+	case c.Message != nil:
+		return c.Message.Chat
+	case c.CallbackQuery != nil && c.CallbackQuery.Message != nil:
+		return c.CallbackQuery.Message.Chat
+	}
+	return nil
+}
+
+// HandlerFunc 更新处理函数
+type HandlerFunc func(c *Context) error
+
+// Middleware 中间件，包装处理函数
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Matcher 判断更新是否交给某个消息路由或分组处理
+type Matcher func(c *Context) bool
+
+// PrivateChat 私聊消息
+func PrivateChat(c *Context) bool {
+	return c.Message != nil && c.Message.Chat != nil && c.Message.Chat.IsPrivate()
+}
+
+// GroupChat 群组和超级群组消息
+func GroupChat(c *Context) bool {
+	return c.Message != nil && c.Message.Chat != nil && (c.Message.Chat.IsGroup() || c.Message.Chat.IsSuperGroup())
+}
+
+// IsCommand 命令消息
+func IsCommand(c *Context) bool {
+	return c.Message != nil && c.Message.IsCommand()
+}
+
+// IsCallback 回调查询
+func IsCallback(c *Context) bool {
+	return c.CallbackQuery != nil
+}
+
+// Any 匹配所有更新
+func Any(c *Context) bool {
+	return true
+}
+
+type route struct {
+	name    string
+	handler HandlerFunc
+}
+
+type callbackRoute struct {
+	prefix string
+	route
+}
+
+type messageRoute struct {
+	match Matcher
+	route
+}
+
+// Router 更新路由器
+// 匹配顺序：命令 → 回调前缀（最长前缀优先）→ inline → 消息路由（按注册顺序）→ 子分组（按注册顺序）→ NotFound。
+// 没有匹配到任何路由时不执行中间件，直接忽略这条更新
+type Router struct {
+	match        Matcher
+	middleware   []Middleware
+	commands     map[string]route
+	callbacks    []callbackRoute
+	messages     []messageRoute
+	inline       *route
+	chosenInline *route
+	notFound     *route
+	groups       []*Router
+}
+
+// New 创建路由器
+func New() *Router {
+	return &Router{commands: make(map[string]route)}
+}
+
+// Use 添加中间件，先添加的在外层
+func (r *Router) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Group 创建子分组，只处理 match 返回 true 的更新，match 为 nil 时匹配所有更新
+// 子分组先执行父路由器的中间件，再执行自己的中间件
+func (r *Router) Group(match Matcher) *Router {
+	if match == nil {
+		match = Any
+	}
+	group := New()
+	group.match = match
+	r.groups = append(r.groups, group)
+	return group
+}
+
+// Command 注册命令（不含 /），命令名不区分大小写，重复注册视为编程错误
+func (r *Router) Command(name string, handler HandlerFunc, middleware ...Middleware) {
+	name = strings.ToLower(name)
+	if _, ok := r.commands[name]; ok {
+		panic(fmt.Sprintf("命令重复注册: %s", name))
+	}
+	r.commands[name] = route{name: "/" + name, handler: chain(handler, middleware)}
+}
+
+// Callback 注册回调前缀，如 play_
+func (r *Router) Callback(prefix string, handler HandlerFunc, middleware ...Middleware) {
+	for _, cb := range r.callbacks {
+		if cb.prefix == prefix {
+			panic(fmt.Sprintf("回调前缀重复注册: %s", prefix))
+		}
+	}
+	r.callbacks = append(r.callbacks, callbackRoute{
+		prefix: prefix,
+		route:  route{name: "cb:" + prefix, handler: chain(handler, middleware)},
+	})
+	// 长前缀优先，匹配结果与注册顺序无关
+	sort.SliceStable(r.callbacks, func(i, j int) bool {
+		return len(r.callbacks[i].prefix) > len(r.callbacks[j].prefix)
+	})
+}
+
+// Message 注册消息路由，name 用于日志和统计
+func (r *Router) Message(name string, match Matcher, handler HandlerFunc, middleware ...Middleware) {
+	r.messages = append(r.messages, messageRoute{
+		match: match,
+		route: route{name: name, handler: chain(handler, middleware)},
+	})
+}
+
+// Inline 注册 inline 查询处理函数
+func (r *Router) Inline(handler HandlerFunc, middleware ...Middleware) {
+	r.inline = &route{name: "inline", handler: chain(handler, middleware)}
+}
+
+// ChosenInline 注册 inline 结果反馈处理函数
+func (r *Router) ChosenInline(handler HandlerFunc, middleware ...Middleware) {
+	r.chosenInline = &route{name: "chosen_inline", handler: chain(handler, middleware)}
+}
+
+// NotFound 本路由器及其子分组都没有匹配时的处理函数
+func (r *Router) NotFound(handler HandlerFunc) {
+	r.notFound = &route{name: "not_found", handler: handler}
+}
+
+// Handle 处理一条更新
+func (r *Router) Handle(update tgbotapi.Update) error {
+	c := NewContext(update)
+	handler := r.resolve(c)
+	if handler == nil {
+		return nil
+	}
+	return handler(c)
+}
+
+// resolve 查找处理函数并套上本路由器的中间件，没有匹配时返回 nil
+func (r *Router) resolve(c *Context) HandlerFunc {
+	if rt := r.find(c); rt != nil {
+		c.Route = rt.name
+		return chain(rt.handler, r.middleware)
+	}
+	for _, group := range r.groups {
+		if !group.match(c) {
+			continue
+		}
+		if handler := group.resolve(c); handler != nil {
+			return chain(handler, r.middleware)
+		}
+	}
+	if r.notFound != nil {
+		c.Route = r.notFound.name
+		return chain(r.notFound.handler, r.middleware)
+	}
+	return nil
+}
+
+// find 在本路由器注册的路由中查找
+func (r *Router) find(c *Context) *route {
+	switch {
+	case c.Message != nil && c.Message.IsCommand():
+		if rt, ok := r.commands[strings.ToLower(c.Message.Command())]; ok {
+			return &rt
+		}
+	case c.CallbackQuery != nil:
+		for i := range r.callbacks {
+			if strings.HasPrefix(c.CallbackQuery.Data, r.callbacks[i].prefix) {
+				return &r.callbacks[i].route
+			}
+		}
+	case c.InlineQuery != nil:
+		return r.inline
+	case c.ChosenInlineResult != nil:
+		return r.chosenInline
+	}
+
+	if c.Message != nil {
+		for i := range r.messages {
+			if r.messages[i].match(c) {
+				return &r.messages[i].route
+			}
+		}
+	}
+	return nil
+}
+
+// chain 按顺序套上中间件，第一个中间件在最外层
+func chain(handler HandlerFunc, middleware []Middleware) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,263 @@
+package router
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// textUpdate 构造一条文本消息更新，以 / 开头时标记为命令
+func textUpdate(chatType, text string) tgbotapi.Update {
+	message := &tgbotapi.Message{
+		MessageID: 1,
+		From:      &tgbotapi.User{ID: 42, LanguageCode: "en"},
+		Chat:      &tgbotapi.Chat{ID: 42, Type: chatType},
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		length, _, _ := strings.Cut(text, " ")
+		message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(length)}}
+	}
+	return tgbotapi.Update{UpdateID: 1, Message: message}
+}
+
+// callbackUpdate 构造一条回调查询更新
+func callbackUpdate(data string) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: 2,
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:   "cb",
+			From: &tgbotapi.User{ID: 42},
+			Data: data,
+			Message: &tgbotapi.Message{
+				MessageID: 1,
+				Chat:      &tgbotapi.Chat{ID: 42, Type: "private"},
+			},
+		},
+	}
+}
+
+// record 返回一个记录路由名的处理函数
+func record(got *[]string, name string) HandlerFunc {
+	return func(c *Context) error {
+		*got = append(*got, name)
+		return nil
+	}
+}
+
+func TestCommandDispatch(t *testing.T) {
+	var got []string
+	r := New()
+	r.Command("start", record(&got, "start"))
+	r.Command("favorites", record(&got, "favorites"))
+	r.Command("favs", record(&got, "favorites"))
+	r.Message("search", Any, record(&got, "search"))
+
+	for _, text := range []string{"/start", "/favs", "/Favorites@FishMusicBot", "hello"} {
+		if err := r.Handle(textUpdate("private", text)); err != nil {
+			t.Fatalf("Handle(%q) 返回错误: %v", text, err)
+		}
+	}
+
+	want := []string{"start", "favorites", "favorites", "search"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("分发结果 = %v，期望 %v", got, want)
+	}
+}
+
+func TestUnknownCommandFallsThroughToMessageRoutes(t *testing.T) {
+	var got []string
+	r := New()
+	r.Command("start", record(&got, "start"))
+	r.Message("unknown_command", IsCommand, record(&got, "unknown"))
+	r.Message("search", Any, record(&got, "search"))
+
+	r.Handle(textUpdate("private", "/nope"))
+	r.Handle(textUpdate("private", "nope"))
+
+	want := []string{"unknown", "search"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("分发结果 = %v，期望 %v", got, want)
+	}
+}
+
+func TestCallbackLongestPrefix(t *testing.T) {
+	var got []string
+	r := New()
+	r.Callback("pl", record(&got, "pl"))
+	r.Callback("pldel_", record(&got, "pldel"))
+	r.Callback("pldelok_", record(&got, "pldelok"))
+
+	for _, data := range []string{"pldelok_3", "pldel_3", "plv_3"} {
+		r.Handle(callbackUpdate(data))
+	}
+
+	want := []string{"pldelok", "pldel", "pl"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("分发结果 = %v，期望 %v", got, want)
+	}
+}
+
+func TestRouteName(t *testing.T) {
+	var route string
+	capture := func(c *Context) error {
+		route = c.Route
+		return nil
+	}
+	r := New()
+	r.Command("help", capture)
+	r.Callback("play_", capture)
+	r.Message("search", Any, capture)
+
+	cases := []struct {
+		update tgbotapi.Update
+		want   string
+	}{
+		{textUpdate("private", "/help"), "/help"},
+		{callbackUpdate("play_1"), "cb:play_"},
+		{textUpdate("private", "jay chou"), "search"},
+	}
+	for _, tc := range cases {
+		r.Handle(tc.update)
+		if route != tc.want {
+			t.Errorf("Route = %q，期望 %q", route, tc.want)
+		}
+	}
+}
+
+func TestGroupsAndMiddlewareOrder(t *testing.T) {
+	var got []string
+	mark := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) error {
+				got = append(got, name)
+				return next(c)
+			}
+		}
+	}
+
+	r := New()
+	r.Use(mark("root"))
+	private := r.Group(PrivateChat)
+	private.Use(mark("private"))
+	private.Command("start", record(&got, "private start"), mark("route"))
+	group := r.Group(GroupChat)
+	group.Use(mark("group"))
+	group.Command("start", record(&got, "group start"))
+
+	r.Handle(textUpdate("private", "/start"))
+	r.Handle(textUpdate("supergroup", "/start"))
+
+	want := []string{"root", "private", "route", "private start", "root", "group", "group start"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("执行顺序 = %v，期望 %v", got, want)
+	}
+}
+
+func TestUnmatchedUpdateSkipsMiddleware(t *testing.T) {
+	called := false
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			called = true
+			return next(c)
+		}
+	})
+	r.Group(PrivateChat).Command("start", func(c *Context) error { return nil })
+
+	// 群组消息、频道消息和空更新都没有对应的路由
+	for _, update := range []tgbotapi.Update{
+		textUpdate("group", "/start"),
+		{Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -1, Type: "channel"}, Text: "hi"}},
+		{},
+	} {
+		if err := r.Handle(update); err != nil {
+			t.Fatalf("Handle 返回错误: %v", err)
+		}
+	}
+	if called {
+		t.Error("没有匹配的更新不应执行中间件")
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	var got []string
+	r := New()
+	callbacks := r.Group(IsCallback)
+	callbacks.Callback("play_", record(&got, "play"))
+	callbacks.NotFound(record(&got, "unknown"))
+	r.Group(PrivateChat).Message("search", Any, record(&got, "search"))
+
+	r.Handle(callbackUpdate("nope_1"))
+	r.Handle(textUpdate("private", "hello"))
+
+	want := []string{"unknown", "search"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("分发结果 = %v，期望 %v", got, want)
+	}
+}
+
+func TestInlineRoutes(t *testing.T) {
+	var got []string
+	r := New()
+	r.Inline(record(&got, "inline"))
+	r.ChosenInline(record(&got, "chosen"))
+
+	r.Handle(tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{ID: "1", From: &tgbotapi.User{ID: 42}, Query: "abc"}})
+	r.Handle(tgbotapi.Update{ChosenInlineResult: &tgbotapi.ChosenInlineResult{ResultID: "7", From: &tgbotapi.User{ID: 42}}})
+
+	want := []string{"inline", "chosen"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("分发结果 = %v，期望 %v", got, want)
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	want := errors.New("boom")
+	r := New()
+	r.Command("start", func(c *Context) error { return want })
+
+	if err := r.Handle(textUpdate("private", "/start")); !errors.Is(err, want) {
+		t.Errorf("Handle 返回 %v，期望 %v", err, want)
+	}
+}
+
+func TestDuplicateRegistrationPanics(t *testing.T) {
+	assertPanics := func(name string, register func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: 重复注册应当 panic", name)
+			}
+		}()
+		register()
+	}
+
+	r := New()
+	r.Command("start", func(c *Context) error { return nil })
+	assertPanics("command", func() { r.Command("Start", func(c *Context) error { return nil }) })
+
+	r.Callback("play_", func(c *Context) error { return nil })
+	assertPanics("callback", func() { r.Callback("play_", func(c *Context) error { return nil }) })
+}
+
+func TestContextAccessors(t *testing.T) {
+	c := NewContext(callbackUpdate("play_1"))
+	if c.From() == nil || c.From().ID != 42 {
+		t.Errorf("From() = %v，期望用户 42", c.From())
+	}
+	if c.Chat() == nil || c.Chat().ID != 42 {
+		t.Errorf("Chat() = %v，期望聊天 42", c.Chat())
+	}
+
+	c = NewContext(tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: &tgbotapi.User{ID: 7}}})
+	if c.Chat() != nil {
+		t.Errorf("inline 查询的 Chat() 应为 nil，实际 %v", c.Chat())
+	}
+	if c.From().ID != 7 {
+		t.Errorf("From().ID = %d，期望 7", c.From().ID)
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+const (
+	// broadcastInterval 公告发送间隔，约 25 条/秒，低于 Telegram 的群发限制（约 30 条/秒），给正常回复留出余量
+	broadcastInterval = 40 * time.Millisecond
+	// broadcastBatchSize 每次从数据库读取的接收人数，每批结束时检查公告是否已被取消
+	broadcastBatchSize = 100
+	// broadcastMaxRetries 被 Telegram 限流（429）时同一用户最多重试次数
+	broadcastMaxRetries = 3
+	// broadcastRecheckInterval 定时检查发送中的公告，数据库出错后也会在此时重试
+	broadcastRecheckInterval = time.Minute
+)
+
+// deliveryResult 单条公告的发送结果
+type deliveryResult int
+
+const (
+	deliverySent    deliveryResult = iota // 发送成功
+	deliveryBlocked                       // 用户已屏蔽 Bot 或注销
+	deliveryFailed                        // 其他错误
+	deliveryRetry                         // 被限流，稍后重试
+)
+
+// BroadcastService 管理员公告服务
+// 确认后的公告由后台任务逐个发送，进度保存在数据库中，Bot 重启后继续发送
+type BroadcastService struct {
+	bot           *tgbotapi.BotAPI
+	broadcastRepo *database.BroadcastRepository
+	userRepo      *database.UserRepository
+	locales       *i18n.Bundle
+	wake          chan struct{}
+}
+
+// NewBroadcastService 创建公告服务
+func NewBroadcastService(bot *tgbotapi.BotAPI, broadcastRepo *database.BroadcastRepository, userRepo *database.UserRepository, locales *i18n.Bundle) *BroadcastService {
+	return &BroadcastService{
+		bot:           bot,
+		broadcastRepo: broadcastRepo,
+		userRepo:      userRepo,
+		locales:       locales,
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// Create 统计接收人数并保存公告草稿
+func (s *BroadcastService) Create(broadcast *model.Broadcast) error {
+	total, err := s.broadcastRepo.CountRecipients(broadcast)
+	if err != nil {
+		return err
+	}
+	broadcast.Total = total
+	broadcast.Status = model.BroadcastDraft
+	return s.broadcastRepo.Create(broadcast)
+}
+
+// Preview 把公告发送给创建者预览，HTML 格式有误时返回错误
+func (s *BroadcastService) Preview(broadcast *model.Broadcast) error {
+	return s.deliver(broadcast, broadcast.ChatID)
+}
+
+// Sending 发送中的公告
+func (s *BroadcastService) Sending() ([]model.Broadcast, error) {
+	return s.broadcastRepo.FindByStatus(model.BroadcastSending)
+}
+
+// Start 确认发送公告，公告不是草稿时返回 false
+func (s *BroadcastService) Start(id uint) (bool, error) {
+	started, err := s.broadcastRepo.Start(id)
+	if err != nil || !started {
+		return started, err
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return true, nil
+}
+
+// Cancel 取消草稿或停止发送中的公告，已结束时返回 false
+func (s *BroadcastService) Cancel(id uint) (bool, error) {
+	return s.broadcastRepo.Cancel(id)
+}
+
+// Run 发送公告的后台任务，启动时继续发送上次未完成的公告
+func (s *BroadcastService) Run(ctx context.Context) {
+	ticker := time.NewTicker(broadcastRecheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sendPending(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendPending 依次发送所有发送中的公告
+func (s *BroadcastService) sendPending(ctx context.Context) {
+	broadcasts, err := s.Sending()
+	if err != nil {
+		log.Printf("查询发送中的公告失败: %v", err)
+		return
+	}
+	for i := range broadcasts {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.send(ctx, &broadcasts[i]); err != nil {
+			log.Printf("发送公告 #%d 失败: %v", broadcasts[i].ID, err)
+		}
+	}
+}
+
+// send 从上次的进度开始发送公告，每条之后保存进度
+// ctx 结束时直接返回，公告保持发送中，下次启动时继续
+func (s *BroadcastService) send(ctx context.Context, broadcast *model.Broadcast) error {
+	if broadcast.LastUserID == 0 {
+		log.Printf("开始发送公告 #%d，共 %d 位用户", broadcast.ID, broadcast.Total)
+	} else {
+		log.Printf("继续发送公告 #%d，已处理 %d/%d", broadcast.ID, broadcast.Processed(), broadcast.Total)
+	}
+
+	ticker := time.NewTicker(broadcastInterval)
+	defer ticker.Stop()
+
+	for {
+		users, err := s.broadcastRepo.NextRecipients(broadcast, broadcastBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			finished, err := s.broadcastRepo.Finish(broadcast.ID)
+			if err != nil {
+				return err
+			}
+			if finished {
+				s.report(broadcast, "broadcast.report_done")
+			} else {
+				s.report(broadcast, "broadcast.report_cancelled")
+			}
+			return nil
+		}
+
+		for _, user := range users {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			switch s.deliverWithRetry(ctx, broadcast, user.TelegramID) {
+			case deliverySent:
+				broadcast.Sent++
+			case deliveryBlocked:
+				broadcast.Blocked++
+				if err := s.userRepo.UpdateActive(user.ID, false); err != nil {
+					log.Printf("标记用户 %d 为不可接收失败: %v", user.ID, err)
+				}
+			default:
+				broadcast.Failed++
+			}
+			broadcast.LastUserID = user.ID
+			if err := s.broadcastRepo.SaveProgress(broadcast); err != nil {
+				return err
+			}
+		}
+
+		// 每批结束时检查是否已被取消
+		current, err := s.broadcastRepo.FindByID(broadcast.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != model.BroadcastSending {
+			s.report(broadcast, "broadcast.report_cancelled")
+			return nil
+		}
+	}
+}
+
+// deliverWithRetry 发送给一位用户，被限流时按 Telegram 要求的时间等待后重试
+func (s *BroadcastService) deliverWithRetry(ctx context.Context, broadcast *model.Broadcast, chatID int64) deliveryResult {
+	for attempt := 0; ; attempt++ {
+		err := s.deliver(broadcast, chatID)
+		result, retryAfter := classifyDelivery(err)
+		if result != deliveryRetry {
+			if result == deliveryFailed {
+				log.Printf("发送公告 #%d 给 %d 失败: %v", broadcast.ID, chatID, err)
+			}
+			return result
+		}
+		if attempt >= broadcastMaxRetries {
+			log.Printf("发送公告 #%d 给 %d 多次被限流，跳过", broadcast.ID, chatID)
+			return deliveryFailed
+		}
+
+		select {
+		case <-ctx.Done():
+			return deliveryFailed
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// deliver 发送公告内容：复制原消息或发送 HTML 文本
+func (s *BroadcastService) deliver(broadcast *model.Broadcast, chatID int64) error {
+	if broadcast.IsCopy() {
+		_, err := s.bot.CopyMessage(tgbotapi.NewCopyMessage(chatID, broadcast.SourceChatID, broadcast.SourceMessageID))
+		return err
+	}
+	msg := tgbotapi.NewMessage(chatID, broadcast.Text)
+	msg.ParseMode = "HTML"
+	_, err := s.bot.Send(msg)
+	return err
+}
+
+// report 把发送报告发给公告的创建者
+func (s *BroadcastService) report(broadcast *model.Broadcast, key string) {
+	lang := ""
+	if broadcast.Creator != nil {
+		lang = broadcast.Creator.Language
+	}
+	tr := s.locales.Localizer(lang)
+
+	elapsed := time.Duration(0)
+	if broadcast.StartedAt != nil {
+		elapsed = time.Since(*broadcast.StartedAt).Round(time.Second)
+	}
+	text := tr.T(key, i18n.Params{"ID": broadcast.ID}) + "\n\n" + tr.T("broadcast.report", i18n.Params{
+		"Total":    broadcast.Total,
+		"Sent":     broadcast.Sent,
+		"Blocked":  broadcast.Blocked,
+		"Failed":   broadcast.Failed,
+		"Duration": elapsed.String(),
+	})
+
+	msg := tgbotapi.NewMessage(broadcast.ChatID, text)
+	msg.ParseMode = "HTML"
+	if _, err := s.bot.Send(msg); err != nil {
+		log.Printf("发送公告 #%d 的报告失败: %v", broadcast.ID, err)
+	}
+	log.Printf("公告 #%d 结束：成功 %d，屏蔽 %d，失败 %d", broadcast.ID, broadcast.Sent, broadcast.Blocked, broadcast.Failed)
+}
+
+// classifyDelivery 根据 Telegram 返回的错误判断发送结果
+// 403 表示用户屏蔽了 Bot 或已注销，"chat not found" 表示用户从未启动过 Bot，两者都不会再收到消息
+func classifyDelivery(err error) (deliveryResult, time.Duration) {
+	if err == nil {
+		return deliverySent, 0
+	}
+
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return deliveryFailed, 0
+	}
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests || apiErr.RetryAfter > 0:
+		retryAfter := time.Duration(apiErr.RetryAfter) * time.Second
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+		return deliveryRetry, retryAfter
+	case apiErr.Code == http.StatusForbidden:
+		return deliveryBlocked, 0
+	case apiErr.Code == http.StatusBadRequest && strings.Contains(strings.ToLower(apiErr.Message), "chat not found"):
+		return deliveryBlocked, 0
+	}
+	return deliveryFailed, 0
+}
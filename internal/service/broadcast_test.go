@@ -0,0 +1,35 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestClassifyDelivery(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		want  deliveryResult
+		retry time.Duration
+	}{
+		{"成功", nil, deliverySent, 0},
+		{"屏蔽 Bot", &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}, deliveryBlocked, 0},
+		{"用户已注销", &tgbotapi.Error{Code: 403, Message: "Forbidden: user is deactivated"}, deliveryBlocked, 0},
+		{"从未启动", &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}, deliveryBlocked, 0},
+		{"HTML 错误", &tgbotapi.Error{Code: 400, Message: "Bad Request: can't parse entities"}, deliveryFailed, 0},
+		{"限流", &tgbotapi.Error{Code: 429, Message: "Too Many Requests: retry after 5", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 5}}, deliveryRetry, 5 * time.Second},
+		{"限流无等待时间", &tgbotapi.Error{Code: 429, Message: "Too Many Requests"}, deliveryRetry, time.Second},
+		{"包装的错误", fmt.Errorf("send: %w", &tgbotapi.Error{Code: 403}), deliveryBlocked, 0},
+		{"网络错误", errors.New("connection reset by peer"), deliveryFailed, 0},
+	}
+	for _, tc := range cases {
+		got, retry := classifyDelivery(tc.err)
+		if got != tc.want || retry != tc.retry {
+			t.Errorf("%s: 结果 = %d，等待 %v；期望 %d，等待 %v", tc.name, got, retry, tc.want, tc.retry)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/user/fish-music/internal/database"
+)
+
+const (
+	// chartPageSize 排行榜每页条目数
+	chartPageSize = 10
+	// chartMaxEntries 排行榜最多显示的条目数
+	chartMaxEntries = 100
+)
+
+// ChartPage 排行榜的一页
+type ChartPage struct {
+	Chart       string
+	Entries     []database.ChartEntry
+	Page        int // 从 0 开始
+	TotalPages  int
+	Offset      int        // 本页第一条的排名 - 1
+	RefreshedAt *time.Time // 排行榜数据的刷新时间，新歌榜为 nil
+}
+
+// ChartService 全站排行榜服务
+// 播放排行在物化视图中预先聚合，由 Run 按固定间隔刷新
+type ChartService struct {
+	chartRepo *database.ChartRepository
+	interval  time.Duration
+}
+
+// NewChartService 创建排行榜服务，interval 为刷新间隔，0 表示不自动刷新
+func NewChartService(chartRepo *database.ChartRepository, interval time.Duration) *ChartService {
+	return &ChartService{
+		chartRepo: chartRepo,
+		interval:  interval,
+	}
+}
+
+// Run 启动后立即刷新一次排行榜，之后按间隔定时刷新，直到 ctx 结束
+func (s *ChartService) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.refresh()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refresh()
+		}
+	}
+}
+
+// refresh 刷新排行榜并记录耗时
+func (s *ChartService) refresh() {
+	start := time.Now()
+	if err := s.chartRepo.Refresh(); err != nil {
+		log.Printf("刷新排行榜失败: %v", err)
+		return
+	}
+	log.Printf("排行榜已刷新，耗时 %v", time.Since(start).Round(time.Millisecond))
+}
+
+// GetChart 获取排行榜的指定页，超出范围的页码会被修正
+func (s *ChartService) GetChart(chart string, page int) (*ChartPage, error) {
+	if page < 0 {
+		page = 0
+	}
+	result := &ChartPage{Chart: chart}
+
+	if chart == database.ChartNew {
+		songs, total, err := s.chartRepo.GetNewSongs(page*chartPageSize, chartPageSize)
+		if err != nil {
+			return nil, err
+		}
+		result.setPage(page, total)
+		if result.Page != page {
+			if songs, _, err = s.chartRepo.GetNewSongs(result.Offset, chartPageSize); err != nil {
+				return nil, err
+			}
+		}
+		for _, song := range songs {
+			result.Entries = append(result.Entries, database.ChartEntry{Song: song})
+		}
+		return result, nil
+	}
+
+	entries, total, err := s.chartRepo.GetChart(chart, page*chartPageSize, chartPageSize)
+	if err != nil {
+		return nil, err
+	}
+	result.setPage(page, total)
+	if result.Page != page {
+		if entries, _, err = s.chartRepo.GetChart(chart, result.Offset, chartPageSize); err != nil {
+			return nil, err
+		}
+	}
+	result.Entries = entries
+
+	if result.RefreshedAt, err = s.chartRepo.RefreshedAt(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// setPage 根据总条目数计算页数并修正页码
+func (p *ChartPage) setPage(page int, total int64) {
+	if total > chartMaxEntries {
+		total = chartMaxEntries
+	}
+	p.TotalPages = int((total + chartPageSize - 1) / chartPageSize)
+	if p.TotalPages == 0 {
+		p.TotalPages = 1
+	}
+	if page >= p.TotalPages {
+		page = p.TotalPages - 1
+	}
+	p.Page = page
+	p.Offset = page * chartPageSize
+}
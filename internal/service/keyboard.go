@@ -0,0 +1,36 @@
+package service
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// SongKeyboard 歌曲卡片上的操作按钮，Bot 发送歌曲和下载完成后发送歌曲时共用
+// 歌词按钮按用户设置显示；播放队列按个人维护，只在私聊中显示（私聊的 chat ID 为正数）
+func SongKeyboard(tr *i18n.Localizer, chatID int64, songID uint, settings *model.UserSetting, favorited bool) tgbotapi.InlineKeyboardMarkup {
+	favoriteBtn := tgbotapi.NewInlineKeyboardButtonData(tr.T("song.favorite"), fmt.Sprintf("fav_%d", songID))
+	if favorited {
+		favoriteBtn = tgbotapi.NewInlineKeyboardButtonData(tr.T("song.unfavorite"), fmt.Sprintf("unfav_%d", songID))
+	}
+	actions := tgbotapi.NewInlineKeyboardRow(
+		favoriteBtn,
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("song.add_to_playlist"), fmt.Sprintf("pladd_%d", songID)),
+		tgbotapi.NewInlineKeyboardButtonData(tr.T("song.share"), fmt.Sprintf("share_%d", songID)),
+	)
+	if settings.ShowLyrics {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData(tr.T("song.lyrics"), fmt.Sprintf("lyr_%d", songID)))
+	}
+
+	keyboard := [][]tgbotapi.InlineKeyboardButton{actions}
+	if chatID > 0 {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("song.queue_next"), "qu_next"),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("song.queue_skip"), "qu_skip"),
+			tgbotapi.NewInlineKeyboardButtonData(tr.T("song.queue_add"), fmt.Sprintf("qu_add_%d", songID)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
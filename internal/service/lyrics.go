@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/api"
+	"github.com/user/fish-music/pkg/lrc"
+	"gorm.io/gorm"
+)
+
+const (
+	// lyricsSearchLimit 搜索歌词时比对的候选歌曲数
+	lyricsSearchLimit = 5
+	// lyricsRetryInterval 没找到歌词的歌曲隔多久再重新查询
+	lyricsRetryInterval = 7 * 24 * time.Hour
+)
+
+// ErrNoLyrics 没有找到歌词
+var ErrNoLyrics = errors.New("没有找到歌词")
+
+// LyricsService 歌词服务
+// 歌词在第一次查看时才从搜索 API 获取并保存，管理员也可以上传 .lrc 文件覆盖
+type LyricsService struct {
+	lyricRepo *database.LyricRepository
+	musicAPI  *api.NeteaseAPI
+}
+
+// NewLyricsService 创建歌词服务
+func NewLyricsService(lyricRepo *database.LyricRepository, musicAPI *api.NeteaseAPI) *LyricsService {
+	return &LyricsService{
+		lyricRepo: lyricRepo,
+		musicAPI:  musicAPI,
+	}
+}
+
+// Get 获取歌曲歌词，本地没有时从搜索 API 获取
+// 没有歌词时返回 ErrNoLyrics
+func (s *LyricsService) Get(song *model.Song) (*model.SongLyric, error) {
+	lyric, err := s.lyricRepo.FindBySong(song.ID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if lyric != nil {
+		if lyric.Found() {
+			return lyric, nil
+		}
+		if time.Since(lyric.UpdatedAt) < lyricsRetryInterval {
+			return nil, ErrNoLyrics
+		}
+	}
+
+	lyric, err = s.fetch(song)
+	if err != nil {
+		return nil, err
+	}
+	if !lyric.Found() {
+		return nil, ErrNoLyrics
+	}
+	return lyric, nil
+}
+
+// Upload 保存管理员上传的歌词，覆盖已有歌词
+func (s *LyricsService) Upload(song *model.Song, content string) (*model.SongLyric, error) {
+	lyrics := lrc.Parse(content)
+	if lyrics.Empty() {
+		return nil, ErrNoLyrics
+	}
+
+	lyric := &model.SongLyric{
+		SongID: song.ID,
+		Source: model.LyricSourceUpload,
+		Synced: lyrics.Synced(),
+		Lines:  lyrics.Lines,
+	}
+	if err := s.lyricRepo.Save(lyric); err != nil {
+		return nil, err
+	}
+	return lyric, nil
+}
+
+// fetch 从搜索 API 获取歌词并保存，没找到时记录下来避免反复查询
+func (s *LyricsService) fetch(song *model.Song) (*model.SongLyric, error) {
+	results, err := s.musicAPI.Search(strings.TrimSpace(song.Artist+" "+song.Title), lyricsSearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("搜索歌词失败: %w", err)
+	}
+
+	lyric := &model.SongLyric{SongID: song.ID, Source: model.LyricSourceNone}
+	if match := matchSearchResult(song, results); match != nil {
+		content, err := s.musicAPI.GetLyric(match.ID)
+		if err != nil {
+			return nil, fmt.Errorf("获取歌词失败: %w", err)
+		}
+		if lyrics := lrc.Parse(content); !lyrics.Empty() {
+			lyric.Source = model.LyricSourceNetease
+			lyric.Synced = lyrics.Synced()
+			lyric.Lines = lyrics.Lines
+		}
+	}
+
+	if err := s.lyricRepo.Save(lyric); err != nil {
+		return nil, err
+	}
+	return lyric, nil
+}
+
+// matchSearchResult 从搜索结果中选出标题一致的歌曲，优先歌手也一致的
+func matchSearchResult(song *model.Song, results []api.SongInfo) *api.SongInfo {
+	var titleMatch *api.SongInfo
+	for i := range results {
+		result := &results[i]
+		if !strings.EqualFold(strings.TrimSpace(result.Name), strings.TrimSpace(song.Title)) {
+			continue
+		}
+		for _, artist := range result.Artists {
+			if strings.Contains(strings.ToLower(song.Artist), strings.ToLower(artist.Name)) {
+				return result
+			}
+		}
+		if titleMatch == nil {
+			titleMatch = result
+		}
+	}
+	return titleMatch
+}
@@ -41,7 +41,7 @@ func (s *MusicService) ProcessDownload(searchResult api.SongInfo) (*model.Song,
 // SearchMusic 搜索音乐
 func (s *MusicService) SearchMusic(keyword string) ([]*model.Song, []api.SongInfo, error) {
 	// 1. 先从数据库搜索
-	dbSongs, err := s.songRepo.Search(keyword, 10)
+	dbSongs, _, err := s.songRepo.Search(keyword, 0, 10)
 	if err == nil && len(dbSongs) > 0 {
 		return dbSongs, nil, nil
 	}
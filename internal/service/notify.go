@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+// notifyInterval 连续发送通知的间隔，避免触发 Telegram 的群发频率限制（约 30 条/秒）
+const notifyInterval = 50 * time.Millisecond
+
+// NotifyService 用户通知服务，只通知在 /settings 中开启了对应选项的用户
+type NotifyService struct {
+	bot          *tgbotapi.BotAPI
+	settingsRepo *database.UserSettingRepository
+	locales      *i18n.Bundle
+}
+
+// NewNotifyService 创建通知服务
+func NewNotifyService(bot *tgbotapi.BotAPI, settingsRepo *database.UserSettingRepository, locales *i18n.Bundle) *NotifyService {
+	return &NotifyService{
+		bot:          bot,
+		settingsRepo: settingsRepo,
+		locales:      locales,
+	}
+}
+
+// NotifyNewSong 新歌入库后通知收藏过同一歌手的用户，在后台发送
+// uploaderID 为添加这首歌的用户，不会收到通知
+func (s *NotifyService) NotifyNewSong(song *model.Song, uploaderID uint) {
+	users, err := s.settingsRepo.FindNewSongSubscribers(song.ID, uploaderID)
+	if err != nil {
+		log.Printf("查询新歌通知用户失败: %v", err)
+		return
+	}
+	if len(users) == 0 {
+		return
+	}
+
+	go func() {
+		for _, user := range users {
+			tr := s.locales.Localizer(user.Language)
+			msg := tgbotapi.NewMessage(user.TelegramID, tr.T("notify.new_song", i18n.Params{
+				"Title":  html.EscapeString(song.Title),
+				"Artist": html.EscapeString(song.Artist),
+			}))
+			msg.ParseMode = "HTML"
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(tr.T("notify.play"), fmt.Sprintf("play_%d", song.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(tr.T("notify.settings"), "set_show"),
+			))
+			if _, err := s.bot.Send(msg); err != nil {
+				log.Printf("发送新歌通知失败 (user %d): %v", user.ID, err)
+			}
+			time.Sleep(notifyInterval)
+		}
+	}()
+}
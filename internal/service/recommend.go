@@ -0,0 +1,352 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/user/fish-music/internal/database"
+	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
+)
+
+const (
+	// recommendSeedLimit 参与计算口味的最多歌曲数
+	recommendSeedLimit = 100
+	// recommendCandidateLimit 每种候选来源的最多歌曲数
+	recommendCandidateLimit = 200
+	// recommendRecentWindow 最近播放过的歌曲不推荐
+	recommendRecentWindow = 3 * 24 * time.Hour
+	// recommendPopularWindow 冷启动时统计热门歌曲的时间范围
+	recommendPopularWindow = 30 * 24 * time.Hour
+	// recommendCoListenWindow 共同收听只统计这段时间内的播放
+	recommendCoListenWindow = 90 * 24 * time.Hour
+	// recommendCoListenerLimit 共同收听最多参考的用户数（取最近听过种子歌曲的用户）
+	recommendCoListenerLimit = 200
+	// recommendMaxPerArtist 同一歌手最多推荐的歌曲数，避免结果被单个歌手占满
+	recommendMaxPerArtist = 2
+)
+
+// 各项得分权重
+const (
+	favoriteWeight = 3.0 // 收藏相当于多次播放
+	coListenWeight = 2.0 // 共同收听
+	artistWeight   = 1.5 // 歌手
+	genreWeight    = 1.0 // 类型
+	languageWeight = 0.8 // 语言
+	yearWeight     = 0.5 // 年代接近
+)
+
+// Recommendation 推荐结果
+type Recommendation struct {
+	Song         *model.Song
+	Score        float64
+	Reason       string      // 推荐理由的消息键，见消息目录 recommend.reason
+	ReasonParams i18n.Params // 推荐理由中的参数
+}
+
+// RecommendService 个性化推荐服务
+// 根据用户的播放历史和收藏计算口味（歌手、类型、语言、年代），
+// 结合"听过 X 的人也听过 Y"的共同收听数据为候选歌曲打分
+type RecommendService struct {
+	songRepo      *database.SongRepository
+	recommendRepo *database.RecommendRepository
+}
+
+// NewRecommendService 创建推荐服务
+func NewRecommendService(
+	songRepo *database.SongRepository,
+	recommendRepo *database.RecommendRepository,
+) *RecommendService {
+	return &RecommendService{
+		songRepo:      songRepo,
+		recommendRepo: recommendRepo,
+	}
+}
+
+// tasteProfile 用户口味，各维度的权重已归一化到 0~1
+type tasteProfile struct {
+	artists   map[string]float64
+	genres    map[string]float64
+	languages map[string]float64
+	avgYear   float64
+}
+
+// Recommend 为用户生成推荐列表，按得分从高到低排列
+func (s *RecommendService) Recommend(userID uint, limit int) ([]*Recommendation, error) {
+	seeds, err := s.getSeeds(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := s.getExcluded(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 冷启动：没有任何历史和收藏时推荐近期热门
+	if len(seeds) == 0 {
+		return s.recommendPopular(exclude, limit)
+	}
+
+	seedIDs := make([]uint, 0, len(seeds))
+	for id := range seeds {
+		seedIDs = append(seedIDs, id)
+	}
+	seedSongs, err := s.songRepo.FindByIDs(seedIDs)
+	if err != nil {
+		return nil, err
+	}
+	profile := buildTasteProfile(seedSongs, seeds)
+
+	// 候选一：共同收听
+	coScores, err := s.recommendRepo.GetCoListened(userID, seedIDs,
+		time.Now().Add(-recommendCoListenWindow), recommendCoListenerLimit, recommendCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	coListen := make(map[uint]float64, len(coScores))
+	var maxCo float64
+	for _, score := range coScores {
+		coListen[score.SongID] = score.Score
+		maxCo = math.Max(maxCo, score.Score)
+	}
+
+	// 候选二：口味相近的歌手、类型、语言
+	candidates, err := s.recommendRepo.GetByMetadata(
+		topKeys(profile.artists, 5),
+		topKeys(profile.genres, 3),
+		topKeys(profile.languages, 2),
+		recommendCandidateLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []uint
+	seen := make(map[uint]bool, len(candidates))
+	for _, song := range candidates {
+		seen[song.ID] = true
+	}
+	for id := range coListen {
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	coSongs, err := s.songRepo.FindByIDs(missing)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, coSongs...)
+
+	var results []*Recommendation
+	for _, song := range candidates {
+		if exclude[song.ID] || song.IsMissing || song.Status != "active" {
+			continue
+		}
+		var co float64
+		if maxCo > 0 {
+			co = coListen[song.ID] / maxCo
+		}
+		results = append(results, scoreSong(song, profile, co))
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return diversify(results, limit), nil
+}
+
+// getSeeds 获取用户口味的种子歌曲及权重：播放次数取对数，收藏额外加权
+func (s *RecommendService) getSeeds(userID uint) (map[uint]float64, error) {
+	seeds := make(map[uint]float64)
+
+	plays, err := s.recommendRepo.GetPlayCounts(userID, recommendSeedLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, play := range plays {
+		seeds[play.SongID] += 1 + math.Log(play.Score)
+	}
+
+	favorites, err := s.recommendRepo.GetFavoriteIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range favorites {
+		seeds[id] += favoriteWeight
+	}
+
+	return seeds, nil
+}
+
+// getExcluded 不参与推荐的歌曲：最近播放过的和已收藏的
+func (s *RecommendService) getExcluded(userID uint) (map[uint]bool, error) {
+	exclude := make(map[uint]bool)
+
+	recent, err := s.recommendRepo.GetPlayedSince(userID, time.Now().Add(-recommendRecentWindow))
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range recent {
+		exclude[id] = true
+	}
+
+	favorites, err := s.recommendRepo.GetFavoriteIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range favorites {
+		exclude[id] = true
+	}
+
+	return exclude, nil
+}
+
+// recommendPopular 推荐近期热门歌曲，不足时用随机歌曲补足
+func (s *RecommendService) recommendPopular(exclude map[uint]bool, limit int) ([]*Recommendation, error) {
+	popular, err := s.recommendRepo.GetPopular(time.Now().Add(-recommendPopularWindow), recommendCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(popular))
+	for _, score := range popular {
+		ids = append(ids, score.SongID)
+	}
+	songs, err := s.songRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Song, len(songs))
+	for _, song := range songs {
+		byID[song.ID] = song
+	}
+
+	var results []*Recommendation
+	for _, score := range popular {
+		song := byID[score.SongID]
+		if song == nil || exclude[song.ID] || song.IsMissing {
+			continue
+		}
+		results = append(results, &Recommendation{Song: song, Score: score.Score, Reason: "recommend.reason.popular"})
+	}
+
+	if len(results) < limit {
+		random, err := s.songRepo.GetRandomSongs(limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, song := range random {
+			if exclude[song.ID] || byID[song.ID] != nil {
+				continue
+			}
+			results = append(results, &Recommendation{Song: song, Reason: "recommend.reason.random"})
+		}
+	}
+
+	return diversify(results, limit), nil
+}
+
+// buildTasteProfile 根据种子歌曲计算用户口味
+func buildTasteProfile(songs []*model.Song, weights map[uint]float64) tasteProfile {
+	profile := tasteProfile{
+		artists:   make(map[string]float64),
+		genres:    make(map[string]float64),
+		languages: make(map[string]float64),
+	}
+
+	var total, yearTotal, yearWeightSum float64
+	for _, song := range songs {
+		weight := weights[song.ID]
+		total += weight
+		if song.Artist != "" {
+			profile.artists[song.Artist] += weight
+		}
+		if song.Genre != "" {
+			profile.genres[song.Genre] += weight
+		}
+		if song.Language != "" {
+			profile.languages[song.Language] += weight
+		}
+		if song.Year > 0 {
+			yearTotal += float64(song.Year) * weight
+			yearWeightSum += weight
+		}
+	}
+
+	if total > 0 {
+		for _, m := range []map[string]float64{profile.artists, profile.genres, profile.languages} {
+			for key := range m {
+				m[key] /= total
+			}
+		}
+	}
+	if yearWeightSum > 0 {
+		profile.avgYear = yearTotal / yearWeightSum
+	}
+	return profile
+}
+
+// scoreSong 为候选歌曲打分，推荐理由取贡献最大的一项
+func scoreSong(song *model.Song, profile tasteProfile, coListen float64) *Recommendation {
+	type part struct {
+		score  float64
+		reason string
+		params i18n.Params
+	}
+	parts := []part{
+		{coListenWeight * coListen, "recommend.reason.co_listen", nil},
+		{artistWeight * profile.artists[song.Artist], "recommend.reason.artist", i18n.Params{"Artist": song.Artist}},
+		{genreWeight * profile.genres[song.Genre], "recommend.reason.genre", i18n.Params{"Genre": song.Genre}},
+		{languageWeight * profile.languages[song.Language], "recommend.reason.language", i18n.Params{"Language": song.Language}},
+	}
+	if song.Year > 0 && profile.avgYear > 0 {
+		similarity := math.Max(0, 1-math.Abs(float64(song.Year)-profile.avgYear)/10)
+		parts = append(parts, part{yearWeight * similarity, "recommend.reason.decade", i18n.Params{"Decade": song.Year / 10 * 10}})
+	}
+
+	result := &Recommendation{Song: song}
+	var best float64
+	for _, p := range parts {
+		result.Score += p.score
+		if p.score > best {
+			best = p.score
+			result.Reason = p.reason
+			result.ReasonParams = p.params
+		}
+	}
+	return result
+}
+
+// diversify 按顺序选取结果，同一歌手最多 recommendMaxPerArtist 首
+func diversify(results []*Recommendation, limit int) []*Recommendation {
+	picked := make([]*Recommendation, 0, limit)
+	perArtist := make(map[string]int)
+	for _, result := range results {
+		if len(picked) >= limit {
+			break
+		}
+		if perArtist[result.Song.Artist] >= recommendMaxPerArtist {
+			continue
+		}
+		perArtist[result.Song.Artist]++
+		picked = append(picked, result)
+	}
+	return picked
+}
+
+// topKeys 按权重从高到低返回前 n 个键
+func topKeys(weights map[string]float64, n int) []string {
+	keys := make([]string, 0, len(weights))
+	for key := range weights {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return weights[keys[i]] > weights[keys[j]]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
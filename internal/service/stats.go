@@ -0,0 +1,150 @@
+package service
+
+import (
+	"time"
+
+	"github.com/user/fish-music/internal/database"
+)
+
+// statsTopLimit 排行榜显示的条目数
+const statsTopLimit = 5
+
+// ListeningStats 个人收听统计
+type ListeningStats struct {
+	database.ListeningSummary
+	TopSongs      []database.StatCount
+	TopArtists    []database.StatCount
+	TopGenres     []database.StatCount
+	TopLanguages  []database.StatCount
+	ActiveDays    int // 有播放记录的天数
+	CurrentStreak int // 截至今天（或昨天）的连续收听天数
+	LongestStreak int // 最长连续收听天数
+}
+
+// YearRecap 年度回顾
+type YearRecap struct {
+	Year int
+	ListeningStats
+	NewSongs      int64     // 今年第一次听到的歌曲数
+	TopMonth      int       // 听得最多的月份
+	TopMonthPlays int64     // 该月播放次数
+	BusiestDay    time.Time // 听得最多的一天
+	BusiestPlays  int64     // 该天播放次数
+}
+
+// StatsService 个人收听统计服务
+type StatsService struct {
+	statsRepo *database.StatsRepository
+}
+
+// NewStatsService 创建个人收听统计服务
+func NewStatsService(statsRepo *database.StatsRepository) *StatsService {
+	return &StatsService{statsRepo: statsRepo}
+}
+
+// GetStats 获取用户在 [from, to) 范围内的收听统计，from / to 为零值表示不限
+func (s *StatsService) GetStats(userID uint, from, to time.Time) (*ListeningStats, error) {
+	summary, err := s.statsRepo.GetSummary(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats := &ListeningStats{ListeningSummary: *summary}
+	if stats.Plays == 0 {
+		return stats, nil
+	}
+
+	tops := []struct {
+		dimension string
+		target    *[]database.StatCount
+	}{
+		{database.StatSong, &stats.TopSongs},
+		{database.StatArtist, &stats.TopArtists},
+		{database.StatGenre, &stats.TopGenres},
+		{database.StatLanguage, &stats.TopLanguages},
+	}
+	for _, top := range tops {
+		counts, err := s.statsRepo.GetTop(userID, top.dimension, from, to, statsTopLimit)
+		if err != nil {
+			return nil, err
+		}
+		*top.target = counts
+	}
+
+	days, err := s.statsRepo.GetActiveDays(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats.ActiveDays = len(days)
+	stats.CurrentStreak, stats.LongestStreak = computeStreaks(days, time.Now())
+	return stats, nil
+}
+
+// GetYearRecap 获取用户的年度回顾
+func (s *StatsService) GetYearRecap(userID uint, year int) (*YearRecap, error) {
+	from := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(1, 0, 0)
+
+	stats, err := s.GetStats(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	recap := &YearRecap{Year: year, ListeningStats: *stats}
+	if stats.Plays == 0 {
+		return recap, nil
+	}
+
+	if recap.NewSongs, err = s.statsRepo.CountNewSongs(userID, from, to); err != nil {
+		return nil, err
+	}
+
+	months, err := s.statsRepo.GetMonthlyPlays(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, month := range months {
+		if month.Plays > recap.TopMonthPlays {
+			recap.TopMonth, recap.TopMonthPlays = month.Period, month.Plays
+		}
+	}
+
+	if recap.BusiestDay, recap.BusiestPlays, err = s.statsRepo.GetBusiestDay(userID, from, to); err != nil {
+		return nil, err
+	}
+	return recap, nil
+}
+
+// GetYears 获取用户有播放记录的年份
+func (s *StatsService) GetYears(userID uint) ([]int, error) {
+	return s.statsRepo.GetYears(userID)
+}
+
+// computeStreaks 根据升序排列的收听日期计算当前和最长连续天数
+// 今天还没听歌时，截至昨天的连续记录仍算作当前连续
+func computeStreaks(days []time.Time, now time.Time) (current, longest int) {
+	run := 0
+	var prev time.Time
+	for i, day := range days {
+		day = truncateDay(day)
+		if i > 0 && day.Equal(prev.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		prev = day
+	}
+
+	today := truncateDay(now)
+	if len(days) > 0 && (prev.Equal(today) || prev.Equal(today.AddDate(0, 0, -1))) {
+		current = run
+	}
+	return current, longest
+}
+
+// truncateDay 取本地时间的日期部分
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"html"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/user/fish-music/internal/database"
 	"github.com/user/fish-music/internal/model"
+	"github.com/user/fish-music/pkg/i18n"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -19,6 +21,9 @@ import (
 type YTDLPService struct {
 	bot        *tgbotapi.BotAPI
 	songRepo   *database.SongRepository
+	notifier   *NotifyService
+	settingsRepo *database.UserSettingRepository
+	locales    *i18n.Bundle
 	tempDir    string
 	maxSize    int64
 	cookiesFile string // YouTube cookies 文件路径（可选）
@@ -28,6 +33,9 @@ type YTDLPService struct {
 func NewYTDLPService(
 	bot *tgbotapi.BotAPI,
 	songRepo *database.SongRepository,
+	notifier *NotifyService,
+	settingsRepo *database.UserSettingRepository,
+	locales *i18n.Bundle,
 	tempDir string,
 	maxSize int,
 	cookiesFile string,
@@ -35,6 +43,9 @@ func NewYTDLPService(
 	return &YTDLPService{
 		bot:      bot,
 		songRepo: songRepo,
+		notifier: notifier,
+		settingsRepo: settingsRepo,
+		locales:  locales,
 		tempDir:  tempDir,
 		maxSize:  int64(maxSize) * 1024 * 1024,
 		cookiesFile: cookiesFile,
@@ -43,8 +54,10 @@ func NewYTDLPService(
 
 // DownloadAndSave 下载并保存音乐
 func (s *YTDLPService) DownloadAndSave(chatID int64, videoURL string, user *model.User) error {
+	tr := s.locales.Localizer(user.Language)
+
 	// 发送开始下载消息
-	statusMsg := tgbotapi.NewMessage(chatID, "⏳ 开始下载...\n\n这可能需要几分钟，请稍候...")
+	statusMsg := tgbotapi.NewMessage(chatID, tr.T("url.downloading"))
 	status, _ := s.bot.Send(statusMsg)
 
 	// 检查是否已存在
@@ -66,20 +79,12 @@ func (s *YTDLPService) DownloadAndSave(chatID int64, videoURL string, user *mode
 		   strings.Contains(errMsg, "cookies") ||
 		   strings.Contains(errMsg, "HTTP Error 429") {
 			// 提示配置 cookies
-			fallbackMsg := tgbotapi.NewMessage(chatID,
-				"⚠️ YouTube 检测到自动化请求，暂时无法直接下载\n\n"+
-				"🍪 <b>解决方案：</b>\n\n"+
-				"发送 <code>/cookies</code> 命令查看配置教程\n\n"+
-				"只需 3 步即可解决：\n"+
-				"1️⃣ 获取 Cookie（浏览器 F12）\n"+
-				"2️⃣ 发送 /cookies &lt;cookie值&gt;\n"+
-				"3️⃣ 重启服务\n\n"+
-				"💡 配置后即可正常下载 YouTube 音乐！")
+			fallbackMsg := tgbotapi.NewMessage(chatID, tr.T("url.bot_detected"))
 			fallbackMsg.ParseMode = "HTML"
 			s.bot.Send(fallbackMsg)
 		} else {
 			// 其他错误，显示原始错误信息
-			errorMsg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ 下载失败\n\n%s", err.Error()))
+			errorMsg := tgbotapi.NewMessage(chatID, tr.T("url.download_failed", i18n.Params{"Error": html.EscapeString(err.Error())}))
 			errorMsg.ParseMode = "HTML"
 			s.bot.Send(errorMsg)
 		}
@@ -88,7 +93,7 @@ func (s *YTDLPService) DownloadAndSave(chatID int64, videoURL string, user *mode
 	defer os.Remove(tempFile)
 
 	// 上传到 Telegram
-	fileID, fileSize, err := s.uploadToTelegram(chatID, tempFile, songInfo)
+	fileID, fileSize, err := s.uploadToTelegram(chatID, tr, tempFile, songInfo)
 	if err != nil {
 		s.bot.Request(tgbotapi.NewDeleteMessage(chatID, status.MessageID))
 		return fmt.Errorf("上传失败: %w", err)
@@ -111,6 +116,9 @@ func (s *YTDLPService) DownloadAndSave(chatID int64, videoURL string, user *mode
 		return fmt.Errorf("保存失败: %w", err)
 	}
 
+	// 通知收藏过该歌手的用户
+	s.notifier.NotifyNewSong(song, user.ID)
+
 	// 删除进度消息
 	s.bot.Request(tgbotapi.NewDeleteMessage(chatID, status.MessageID))
 
@@ -267,7 +275,7 @@ func (s *YTDLPService) getDuration(filePath string) (int, error) {
 }
 
 // uploadToTelegram 上传到 Telegram
-func (s *YTDLPService) uploadToTelegram(chatID int64, filePath string, songInfo *SongInfo) (string, int64, error) {
+func (s *YTDLPService) uploadToTelegram(chatID int64, tr *i18n.Localizer, filePath string, songInfo *SongInfo) (string, int64, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", 0, err
@@ -280,8 +288,11 @@ func (s *YTDLPService) uploadToTelegram(chatID int64, filePath string, songInfo
 	})
 	upload.Title = songInfo.Title
 	upload.Performer = songInfo.Artist
-	upload.Caption = fmt.Sprintf("🎵 %s - %s\n\n⏰ %d秒",
-		songInfo.Artist, songInfo.Title, songInfo.Duration)
+	upload.Caption = tr.T("url.upload_caption", i18n.Params{
+		"Artist":   songInfo.Artist,
+		"Title":    songInfo.Title,
+		"Duration": songInfo.Duration,
+	})
 
 	msg, err := s.bot.Send(upload)
 	if err != nil {
@@ -307,21 +318,26 @@ func (s *YTDLPService) sendDownloadedSong(chatID int64, song *model.Song, user *
 	caption.WriteString(fmt.Sprintf("\n\n%s %s", song.GetCountryEmoji(), song.GetYearText()))
 	audio.Caption = caption.String()
 
+	// 用户设置
+	settings, err := s.settingsRepo.Get(user.ID)
+	if err != nil {
+		settings = model.DefaultUserSetting(user.ID)
+	}
+
 	// 创建操作按钮
-	var keyboard [][]tgbotapi.InlineKeyboardButton
-	favoriteBtn := tgbotapi.NewInlineKeyboardButtonData("❤️ 收藏", fmt.Sprintf("fav_%d", song.ID))
-	keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{favoriteBtn})
-	audio.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	audio.ReplyMarkup = SongKeyboard(s.locales.Localizer(user.Language), chatID, song.ID, settings, false)
 
 	// 发送音频
-	_, err := s.bot.Send(audio)
+	_, err = s.bot.Send(audio)
 	if err != nil {
 		return err
 	}
 
-	// 记录历史
-	historyRepo := database.NewHistoryRepository()
-	historyRepo.Add(user.ID, song.ID)
+	// 记录历史（用户可以在设置中关闭）
+	if settings.RecordHistory {
+		historyRepo := database.NewHistoryRepository()
+		historyRepo.Add(user.ID, song.ID)
+	}
 
 	return nil
 }
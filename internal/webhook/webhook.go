@@ -0,0 +1,168 @@
+// Package webhook 通过 Telegram webhook 接收更新，作为长轮询的替代
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/config"
+)
+
+// SecretHeader Telegram 推送更新时携带 secret_token 的请求头
+const SecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxBodySize 单条更新的最大字节数，正常更新远小于这个值
+const maxBodySize = 1 << 20
+
+// NewHandler 创建接收更新的 HTTP 处理器
+// 校验 secret_token 后把更新交给 submit；submit 返回 false（如正在关闭）时回复 503，
+// Telegram 会稍后重试这条更新
+// 处理器可以单独监听，也可以挂到已有的 HTTP 服务上（如 gin.WrapH）
+func NewHandler(secretToken string, submit func(tgbotapi.Update) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(SecretHeader)), []byte(secretToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&update); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+
+		if !submit(update) {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// Server 独立监听的 webhook 服务
+// 启动时向 Telegram 注册 webhook，停止时删除，之后可以直接切回长轮询
+type Server struct {
+	bot    *tgbotapi.BotAPI
+	cfg    *config.WebhookConfig
+	submit func(tgbotapi.Update) bool
+	server *http.Server
+}
+
+// NewServer 创建 webhook 服务
+func NewServer(bot *tgbotapi.BotAPI, cfg *config.WebhookConfig, submit func(tgbotapi.Update) bool) *Server {
+	return &Server{bot: bot, cfg: cfg, submit: submit}
+}
+
+// Start 开始监听并注册 webhook
+// 先监听再注册，确保 Telegram 推送第一条更新时服务已就绪
+func (s *Server) Start() error {
+	secret := s.cfg.SecretToken
+	if secret == "" {
+		var err error
+		if secret, err = randomSecret(); err != nil {
+			return fmt.Errorf("生成 secret_token 失败: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", s.cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", s.cfg.Listen, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(s.cfg.Path(), NewHandler(secret, s.submit))
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	go func() {
+		var err error
+		if s.cfg.CertFile != "" {
+			err = s.server.ServeTLS(listener, s.cfg.CertFile, s.cfg.KeyFile)
+		} else {
+			// 由反向代理处理 HTTPS
+			err = s.server.Serve(listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("webhook 服务异常退出: %v", err)
+		}
+	}()
+
+	if err := s.setWebhook(secret); err != nil {
+		s.server.Close()
+		return err
+	}
+	log.Printf("webhook 已注册，监听 %s%s", s.cfg.Listen, s.cfg.Path())
+	return nil
+}
+
+// Stop 删除 webhook 并停止监听，等待正在进行的请求完成
+// 不丢弃 Telegram 服务端暂存的更新，下次启动时会继续推送
+func (s *Server) Stop(ctx context.Context) error {
+	if _, err := s.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("删除 webhook 失败: %v", err)
+	}
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
+}
+
+// setWebhook 向 Telegram 注册 webhook
+// tgbotapi 的 WebhookConfig 不支持 secret_token，这里直接调用接口
+func (s *Server) setWebhook(secret string) error {
+	params := tgbotapi.Params{
+		"url":          s.cfg.URL,
+		"secret_token": secret,
+	}
+	if s.cfg.MaxConnections > 0 {
+		params["max_connections"] = strconv.Itoa(s.cfg.MaxConnections)
+	}
+
+	var err error
+	if s.cfg.SelfSigned {
+		_, err = s.bot.UploadFiles("setWebhook", params, []tgbotapi.RequestFile{
+			{Name: "certificate", Data: tgbotapi.FilePath(s.cfg.CertFile)},
+		})
+	} else {
+		_, err = s.bot.MakeRequest("setWebhook", params)
+	}
+	if err != nil {
+		return fmt.Errorf("注册 webhook 失败: %w", err)
+	}
+	return nil
+}
+
+// randomSecret 生成随机 secret_token，只包含 Telegram 允许的字符
+func randomSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/fish-music/internal/config"
+)
+
+const testSecret = "s3cret_token-1"
+
+// messageUpdate Telegram 推送的一条私聊消息
+const messageUpdate = `{
+	"update_id": 10001,
+	"message": {
+		"message_id": 5,
+		"from": {"id": 42, "is_bot": false, "first_name": "Fish", "language_code": "zh-hans"},
+		"chat": {"id": 42, "type": "private", "first_name": "Fish"},
+		"date": 1792396800,
+		"text": "/search 晴天",
+		"entities": [{"type": "bot_command", "offset": 0, "length": 7}]
+	}
+}`
+
+// callbackUpdate Telegram 推送的一次按钮点击
+const callbackUpdate = `{
+	"update_id": 10002,
+	"callback_query": {
+		"id": "4382bfdwdsb323b2d9",
+		"from": {"id": 42, "is_bot": false, "first_name": "Fish"},
+		"message": {
+			"message_id": 6,
+			"chat": {"id": -100, "type": "supergroup", "title": "Music"},
+			"date": 1792396800,
+			"text": "结果"
+		},
+		"chat_instance": "-1",
+		"data": "song_17"
+	}
+}`
+
+// recorder 记录提交的更新
+type recorder struct {
+	updates []tgbotapi.Update
+	reject  bool
+}
+
+func (r *recorder) submit(update tgbotapi.Update) bool {
+	if r.reject {
+		return false
+	}
+	r.updates = append(r.updates, update)
+	return true
+}
+
+func post(h http.Handler, secret, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(SecretHeader, secret)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerSubmitsUpdates(t *testing.T) {
+	r := &recorder{}
+	h := NewHandler(testSecret, r.submit)
+
+	for _, body := range []string{messageUpdate, callbackUpdate} {
+		if rec := post(h, testSecret, body); rec.Code != http.StatusOK {
+			t.Fatalf("状态码 = %d，期望 200: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if len(r.updates) != 2 {
+		t.Fatalf("提交了 %d 条更新，期望 2 条", len(r.updates))
+	}
+
+	msg := r.updates[0]
+	if msg.UpdateID != 10001 || msg.Message == nil || !msg.Message.IsCommand() ||
+		msg.Message.Command() != "search" || msg.Message.CommandArguments() != "晴天" {
+		t.Errorf("消息更新解析错误: %+v", msg.Message)
+	}
+	cb := r.updates[1]
+	if cb.CallbackQuery == nil || cb.CallbackQuery.Data != "song_17" || cb.CallbackQuery.Message.Chat.ID != -100 {
+		t.Errorf("回调更新解析错误: %+v", cb.CallbackQuery)
+	}
+}
+
+func TestHandlerRejects(t *testing.T) {
+	cases := []struct {
+		name   string
+		method string
+		secret string
+		body   string
+		reject bool
+		want   int
+	}{
+		{"缺少密钥", http.MethodPost, "", messageUpdate, false, http.StatusUnauthorized},
+		{"密钥错误", http.MethodPost, "wrong", messageUpdate, false, http.StatusUnauthorized},
+		{"GET 请求", http.MethodGet, testSecret, "", false, http.StatusMethodNotAllowed},
+		{"非法 JSON", http.MethodPost, testSecret, `{"update_id":`, false, http.StatusBadRequest},
+		{"请求过大", http.MethodPost, testSecret, `{"update_id": 1, "x": "` + strings.Repeat("a", maxBodySize) + `"}`, false, http.StatusRequestEntityTooLarge},
+		{"正在关闭", http.MethodPost, testSecret, messageUpdate, true, http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		r := &recorder{reject: tc.reject}
+		h := NewHandler(testSecret, r.submit)
+
+		req := httptest.NewRequest(tc.method, "/telegram", strings.NewReader(tc.body))
+		if tc.secret != "" {
+			req.Header.Set(SecretHeader, tc.secret)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tc.want {
+			t.Errorf("%s: 状态码 = %d，期望 %d", tc.name, rec.Code, tc.want)
+		}
+		if len(r.updates) != 0 {
+			t.Errorf("%s: 不应提交更新", tc.name)
+		}
+	}
+}
+
+// fakeTelegram 模拟 Bot API，记录 setWebhook 和 deleteWebhook 调用
+type fakeTelegram struct {
+	mu      sync.Mutex
+	calls   []string
+	webhook map[string]string
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	f.mu.Lock()
+	f.calls = append(f.calls, method)
+	if method == "setWebhook" {
+		f.webhook = map[string]string{}
+		for k := range r.PostForm {
+			f.webhook[k] = r.PostForm.Get(k)
+		}
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if method == "getMe" {
+		w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Fish","username":"FishMusicBot"}}`))
+		return
+	}
+	w.Write([]byte(`{"ok":true,"result":true}`))
+}
+
+func TestServerRegistersWebhook(t *testing.T) {
+	fake := &fakeTelegram{}
+	api := httptest.NewServer(fake)
+	defer api.Close()
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("123:abc", api.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatalf("创建 BotAPI 失败: %v", err)
+	}
+
+	cfg := &config.WebhookConfig{
+		URL:            "https://music.example.com/telegram",
+		Listen:         "127.0.0.1:0",
+		SecretToken:    testSecret,
+		MaxConnections: 20,
+	}
+	s := NewServer(bot, cfg, (&recorder{}).submit)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start 返回错误: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop 返回错误: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	want := []string{"getMe", "setWebhook", "deleteWebhook"}
+	if strings.Join(fake.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("调用顺序 = %v，期望 %v", fake.calls, want)
+	}
+	if fake.webhook["url"] != cfg.URL || fake.webhook["secret_token"] != testSecret || fake.webhook["max_connections"] != "20" {
+		t.Errorf("setWebhook 参数 = %v", fake.webhook)
+	}
+}
+
+func TestRandomSecret(t *testing.T) {
+	secret, err := randomSecret()
+	if err != nil {
+		t.Fatalf("randomSecret 返回错误: %v", err)
+	}
+	cfg := config.WebhookConfig{URL: "https://example.com/", Listen: ":8443", SecretToken: secret}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("随机生成的 secret_token 不符合要求: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package credits
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Credit 从歌手 / 标题字符串中解析出的署名
+type Credit struct {
+	Name     string
+	Featured bool // 是否为 feat. 合作歌手
+}
+
+var (
+	// featPattern 匹配 feat. / ft. / featuring，之后的内容为合作歌手
+	featPattern = regexp.MustCompile(`(?i)[\(\[（【]?\s*\b(?:feat\.?|ft\.?|featuring)\s+`)
+	// titleFeatPattern 匹配标题中的 (feat. X) / [ft. X]
+	titleFeatPattern = regexp.MustCompile(`(?i)[\(\[（【]\s*(?:feat\.?|ft\.?|featuring)\s+([^\)\]）】]+)[\)\]）】]`)
+	// separatorPattern 多位歌手之间的分隔符
+	separatorPattern = regexp.MustCompile(`\s*(?:&|,|，|、|/|;|；|＆)\s*`)
+)
+
+// Parse 解析歌手字段和标题中的署名，如 "A & B feat. C" 解析为主唱 A、B 和合作歌手 C
+// 名称按出现顺序去重（不区分大小写）
+//
+// known 用于识别名称中本身带分隔符的歌手（如 "Simon & Garfunkel"）：
+// 整段名称为已知歌手时不再拆分，传 nil 表示总是拆分
+func Parse(artist, title string, known func(name string) bool) []Credit {
+	var result []Credit
+	seen := make(map[string]bool)
+	add := func(names []string, featured bool) {
+		for _, name := range names {
+			key := strings.ToLower(name)
+			if name == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, Credit{Name: name, Featured: featured})
+		}
+	}
+
+	main, feat := artist, ""
+	if loc := featPattern.FindStringIndex(artist); loc != nil {
+		main, feat = artist[:loc[0]], artist[loc[1]:]
+	}
+	split := func(s string) []string {
+		s = strings.TrimSpace(strings.Trim(strings.TrimSpace(s), "()[]（）【】"))
+		if known != nil && s != "" && known(s) {
+			return []string{s}
+		}
+		return SplitNames(s)
+	}
+
+	add(split(main), false)
+	add(split(feat), true)
+	for _, match := range titleFeatPattern.FindAllStringSubmatch(title, -1) {
+		add(split(match[1]), true)
+	}
+	return result
+}
+
+// SplitNames 按 &、逗号、顿号、斜杠等分隔符拆分多位歌手
+func SplitNames(s string) []string {
+	var names []string
+	for _, part := range separatorPattern.Split(s, -1) {
+		name := strings.Trim(strings.TrimSpace(part), "()[]（）【】")
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,324 @@
+// Package i18n 多语言消息目录
+//
+// 每种语言一个 YAML 文件，文件名即语言标签（如 zh-CN.yaml、en.yaml）。
+// 键可以按命名空间嵌套，值为 text/template 模板：
+//
+//	search:
+//	  title: "🔍 <b>Results</b>: {{.Keyword}}"
+//	  count:
+//	    one: "{{.Count}} song"
+//	    other: "{{.Count}} songs"
+//
+// 只包含复数类别（zero、one、two、few、many、other）的映射视为复数消息，
+// 通过 Localizer.N 按数量选择。
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Params 模板参数
+type Params map[string]interface{}
+
+// pluralCategories CLDR 复数类别
+var pluralCategories = map[string]bool{
+	"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true,
+}
+
+// message 一条消息，非复数消息只有 other
+type message map[string]*template.Template
+
+// Bundle 所有语言的消息目录
+type Bundle struct {
+	defaultLang string
+	messages    map[string]map[string]message // 语言 -> 键 -> 消息
+
+	mu      sync.Mutex
+	missing map[string]bool // 已记录过日志的缺失键
+}
+
+// NewBundle 创建消息目录，defaultLang 为找不到翻译时的回退语言
+func NewBundle(defaultLang string) *Bundle {
+	return &Bundle{
+		defaultLang: defaultLang,
+		messages:    make(map[string]map[string]message),
+		missing:     make(map[string]bool),
+	}
+}
+
+// LoadFS 加载目录下所有 .yaml / .yml 文件
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("读取语言目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("读取语言文件 %s 失败: %w", entry.Name(), err)
+		}
+		if err := b.AddMessages(strings.TrimSuffix(entry.Name(), ext), data); err != nil {
+			return err
+		}
+	}
+	if _, ok := b.messages[b.defaultLang]; !ok {
+		return fmt.Errorf("缺少默认语言 %s 的消息目录", b.defaultLang)
+	}
+	return nil
+}
+
+// AddMessages 解析 YAML 消息目录并合并到指定语言
+func (b *Bundle) AddMessages(lang string, data []byte) error {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("解析语言文件 %s 失败: %w", lang, err)
+	}
+
+	messages := b.messages[lang]
+	if messages == nil {
+		messages = make(map[string]message)
+		b.messages[lang] = messages
+	}
+	return flatten(lang, "", raw, messages)
+}
+
+// flatten 将嵌套的命名空间展开为以点分隔的键
+func flatten(lang, prefix string, raw map[string]interface{}, out map[string]message) error {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			tmpl, err := parseTemplate(lang, key, v)
+			if err != nil {
+				return err
+			}
+			out[key] = message{"other": tmpl}
+		case map[string]interface{}:
+			if !isPlural(v) {
+				if err := flatten(lang, key, v, out); err != nil {
+					return err
+				}
+				continue
+			}
+			msg := make(message, len(v))
+			for form, text := range v {
+				s, ok := text.(string)
+				if !ok {
+					return fmt.Errorf("%s: %s.%s 必须是字符串", lang, key, form)
+				}
+				tmpl, err := parseTemplate(lang, key+"."+form, s)
+				if err != nil {
+					return err
+				}
+				msg[form] = tmpl
+			}
+			if msg["other"] == nil {
+				return fmt.Errorf("%s: 复数消息 %s 缺少 other", lang, key)
+			}
+			out[key] = msg
+		default:
+			return fmt.Errorf("%s: %s 的值类型不支持", lang, key)
+		}
+	}
+	return nil
+}
+
+// isPlural 映射的键是否全部为复数类别
+func isPlural(m map[string]interface{}) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for key := range m {
+		if !pluralCategories[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTemplate 解析消息模板
+func parseTemplate(lang, key, text string) (*template.Template, error) {
+	tmpl, err := template.New(key).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: 消息 %s 模板错误: %w", lang, key, err)
+	}
+	return tmpl, nil
+}
+
+// DefaultLanguage 默认语言
+func (b *Bundle) DefaultLanguage() string {
+	return b.defaultLang
+}
+
+// Languages 已加载的语言，默认语言排在最前
+func (b *Bundle) Languages() []string {
+	langs := make([]string, 0, len(b.messages))
+	for lang := range b.messages {
+		if lang != b.defaultLang {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	return append([]string{b.defaultLang}, langs...)
+}
+
+// Match 将 Telegram 的 language_code（IETF 标签，如 en、zh-hans、pt-br）匹配到已加载的语言
+// 依次尝试完整匹配、中文简繁体映射和主语言匹配，都不匹配时返回默认语言
+func (b *Bundle) Match(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+	if code == "" {
+		return b.defaultLang
+	}
+
+	base, region, _ := strings.Cut(code, "-")
+	if base == "zh" {
+		switch {
+		case strings.HasPrefix(region, "hant"), region == "tw", region == "hk", region == "mo":
+			code = "zh-tw"
+		case strings.HasPrefix(region, "hans"), region == "cn", region == "sg":
+			code = "zh-cn"
+		}
+	}
+
+	langs := b.Languages()
+	for _, lang := range langs {
+		if strings.EqualFold(lang, code) {
+			return lang
+		}
+	}
+	// Languages 默认语言在前，同一主语言有多个地区时优先默认语言
+	for _, lang := range langs {
+		if langBase, _, _ := strings.Cut(strings.ToLower(lang), "-"); langBase == base {
+			return lang
+		}
+	}
+	return b.defaultLang
+}
+
+// Localizer 返回指定语言的翻译器，lang 可以是任意语言标签
+func (b *Bundle) Localizer(lang string) *Localizer {
+	return &Localizer{bundle: b, lang: b.Match(lang)}
+}
+
+// lookup 查找消息，当前语言没有时回退到默认语言
+func (b *Bundle) lookup(lang, key string) (message, string) {
+	if msg, ok := b.messages[lang][key]; ok {
+		return msg, lang
+	}
+	if msg, ok := b.messages[b.defaultLang][key]; ok {
+		b.logMissing(lang, key)
+		return msg, b.defaultLang
+	}
+	b.logMissing(b.defaultLang, key)
+	return nil, lang
+}
+
+// logMissing 每个缺失的键只记录一次日志
+func (b *Bundle) logMissing(lang, key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.missing[lang+":"+key] {
+		return
+	}
+	b.missing[lang+":"+key] = true
+	log.Printf("缺少翻译: %s %s", lang, key)
+}
+
+// Localizer 某种语言的翻译器
+type Localizer struct {
+	bundle *Bundle
+	lang   string
+}
+
+// Lang 翻译器的语言
+func (l *Localizer) Lang() string {
+	return l.lang
+}
+
+// T 获取消息，找不到时返回键名
+func (l *Localizer) T(key string, params ...Params) string {
+	return l.render(key, "other", mergeParams(params))
+}
+
+// N 按数量获取复数消息，模板中可以用 {{.Count}} 引用数量
+func (l *Localizer) N(key string, count int64, params ...Params) string {
+	data := mergeParams(params)
+	data["Count"] = count
+	return l.render(key, "", data)
+}
+
+// render 渲染消息，form 为空时按数量选择复数形式
+func (l *Localizer) render(key, form string, data Params) string {
+	msg, lang := l.bundle.lookup(l.lang, key)
+	if msg == nil {
+		return key
+	}
+
+	if form == "" {
+		form = pluralForm(lang, data["Count"].(int64))
+	}
+	tmpl := msg[form]
+	if tmpl == nil {
+		tmpl = msg["other"]
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Printf("渲染消息 %s 失败: %v", key, err)
+		return key
+	}
+	return buf.String()
+}
+
+// mergeParams 合并模板参数
+func mergeParams(params []Params) Params {
+	data := make(Params)
+	for _, p := range params {
+		for k, v := range p {
+			data[k] = v
+		}
+	}
+	return data
+}
+
+// pluralForm 按 CLDR 规则选择复数类别，未列出的语言（中文、日语等）没有复数变化
+func pluralForm(lang string, n int64) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	switch base {
+	case "en", "de", "nl", "sv", "it", "es", "pt":
+		if n == 1 {
+			return "one"
+		}
+	case "fr":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+	case "ru", "uk":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	}
+	return "other"
+}
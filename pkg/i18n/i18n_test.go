@@ -0,0 +1,176 @@
+package i18n
+
+import (
+	"os"
+	"sort"
+	"testing"
+)
+
+func newTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b := NewBundle("zh-CN")
+	catalogs := map[string]string{
+		"zh-CN": `
+greeting: "你好，{{.Name}}"
+only_default: "只有简体中文"
+songs: "{{.Count}} 首歌"
+`,
+		"zh-TW": `
+greeting: "你好，{{.Name}}"
+songs: "{{.Count}} 首歌"
+`,
+		"en": `
+greeting: "Hello, {{.Name}}"
+songs:
+  one: "{{.Count}} song"
+  other: "{{.Count}} songs"
+`,
+		"ja": `
+greeting: "こんにちは、{{.Name}}"
+songs:
+  one: "{{.Count}} 曲（one）"
+  other: "{{.Count}} 曲"
+`,
+	}
+	for lang, data := range catalogs {
+		if err := b.AddMessages(lang, []byte(data)); err != nil {
+			t.Fatalf("AddMessages(%s): %v", lang, err)
+		}
+	}
+	return b
+}
+
+func TestPlural(t *testing.T) {
+	b := newTestBundle(t)
+	cases := []struct {
+		lang  string
+		count int64
+		want  string
+	}{
+		// 中文没有复数变化
+		{"zh-CN", 1, "1 首歌"},
+		{"zh-CN", 2, "2 首歌"},
+		// 英语 1 为 one，其余（包括 0）为 other
+		{"en", 0, "0 songs"},
+		{"en", 1, "1 song"},
+		{"en", 2, "2 songs"},
+		{"en", 21, "21 songs"},
+		// 日语没有复数变化，即使目录中写了 one 也使用 other
+		{"ja", 1, "1 曲"},
+		{"ja", 5, "5 曲"},
+	}
+	for _, tc := range cases {
+		if got := b.Localizer(tc.lang).N("songs", tc.count); got != tc.want {
+			t.Errorf("%s N(songs, %d) = %q，期望 %q", tc.lang, tc.count, got, tc.want)
+		}
+	}
+}
+
+func TestPluralForm(t *testing.T) {
+	cases := []struct {
+		lang string
+		n    int64
+		want string
+	}{
+		{"zh-CN", 1, "other"},
+		{"ja", 1, "other"},
+		{"en", 1, "one"},
+		{"en-GB", 1, "one"},
+		{"en", 0, "other"},
+		{"fr", 0, "one"},
+		{"ru", 1, "one"},
+		{"ru", 3, "few"},
+		{"ru", 11, "many"},
+		{"ru", 22, "few"},
+	}
+	for _, tc := range cases {
+		if got := pluralForm(tc.lang, tc.n); got != tc.want {
+			t.Errorf("pluralForm(%q, %d) = %q，期望 %q", tc.lang, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestFallback(t *testing.T) {
+	b := newTestBundle(t)
+
+	en := b.Localizer("en")
+	if got := en.T("greeting", Params{"Name": "Ann"}); got != "Hello, Ann" {
+		t.Errorf("T(greeting) = %q", got)
+	}
+	// 当前语言缺少的键回退到默认语言
+	if got := en.T("only_default"); got != "只有简体中文" {
+		t.Errorf("缺少的键 = %q，期望回退到默认语言", got)
+	}
+	// 所有语言都没有的键返回键名
+	if got := en.T("no.such.key"); got != "no.such.key" {
+		t.Errorf("不存在的键 = %q，期望返回键名", got)
+	}
+	// 没有加载的语言使用默认语言
+	fr := b.Localizer("fr")
+	if fr.Lang() != "zh-CN" {
+		t.Errorf("Localizer(fr).Lang() = %q，期望 zh-CN", fr.Lang())
+	}
+	if got := fr.T("greeting", Params{"Name": "Ann"}); got != "你好，Ann" {
+		t.Errorf("fr T(greeting) = %q", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	b := newTestBundle(t)
+	cases := map[string]string{
+		"":           "zh-CN",
+		"en":         "en",
+		"en-US":      "en",
+		"EN_gb":      "en",
+		"ja":         "ja",
+		"zh":         "zh-CN",
+		"zh-hans":    "zh-CN",
+		"zh-Hans-SG": "zh-CN",
+		"zh-hant":    "zh-TW",
+		"zh-TW":      "zh-TW",
+		"zh-HK":      "zh-TW",
+		"pt-br":      "zh-CN",
+		"zh-TW ":     "zh-TW",
+	}
+	for code, want := range cases {
+		if got := b.Match(code); got != want {
+			t.Errorf("Match(%q) = %q，期望 %q", code, got, want)
+		}
+	}
+}
+
+// TestShippedCatalogs 发布的各语言消息目录键集合相同，避免新增的消息只写了一种语言
+func TestShippedCatalogs(t *testing.T) {
+	b := NewBundle("zh-CN")
+	if err := b.LoadFS(os.DirFS("../../internal/locales"), "."); err != nil {
+		t.Fatal(err)
+	}
+	langs := b.Languages()
+	if len(langs) < 2 {
+		t.Fatalf("只加载了 %v", langs)
+	}
+
+	base := b.messages[b.defaultLang]
+	for _, lang := range langs[1:] {
+		messages := b.messages[lang]
+		var missing, extra []string
+		for key := range base {
+			if _, ok := messages[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		for key := range messages {
+			if _, ok := base[key]; !ok {
+				extra = append(extra, key)
+			}
+		}
+		sort.Strings(missing)
+		sort.Strings(extra)
+		if len(missing) > 0 {
+			t.Errorf("%s 缺少: %v", lang, missing)
+		}
+		if len(extra) > 0 {
+			t.Errorf("%s 多出 %s 没有的键: %v", lang, b.defaultLang, extra)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package lrc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Line 一行歌词，Time 为 -1 表示没有时间轴
+type Line struct {
+	Time int64  `json:"t"` // 毫秒
+	Text string `json:"text"`
+}
+
+// Lyrics 解析后的歌词
+type Lyrics struct {
+	Meta  map[string]string // [ti:] [ar:] [al:] 等标签
+	Lines []Line
+}
+
+var (
+	// timeTagPattern 匹配时间标签 [mm:ss]、[mm:ss.xx]、[mm:ss:xx]、[mm:ss.xxx]
+	timeTagPattern = regexp.MustCompile(`\[(\d{1,3}):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+	// metaTagPattern 匹配元数据标签 [ti:标题]
+	metaTagPattern = regexp.MustCompile(`^\[([a-zA-Z#]+):(.*)\]$`)
+)
+
+// Parse 解析 LRC 歌词，也兼容没有时间轴的纯文本歌词
+// 一行多个时间标签（副歌重复）会展开成多行，结果按时间排序；[offset:] 会应用到所有时间
+func Parse(content string) *Lyrics {
+	lyrics := &Lyrics{Meta: make(map[string]string)}
+	content = strings.TrimPrefix(content, "\ufeff")
+
+	for _, raw := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		raw = strings.TrimSpace(raw)
+
+		tags := timeTagPattern.FindAllStringSubmatchIndex(raw, -1)
+		// 时间标签必须出现在行首
+		if len(tags) == 0 || tags[0][0] != 0 {
+			if m := metaTagPattern.FindStringSubmatch(raw); m != nil {
+				lyrics.Meta[strings.ToLower(m[1])] = strings.TrimSpace(m[2])
+				continue
+			}
+			lyrics.Lines = append(lyrics.Lines, Line{Time: -1, Text: raw})
+			continue
+		}
+
+		end := 0
+		var times []int64
+		for _, tag := range tags {
+			if tag[0] != end {
+				break
+			}
+			end = tag[1]
+			times = append(times, parseTimeTag(raw, tag))
+		}
+		text := strings.TrimSpace(raw[end:])
+		for _, t := range times {
+			lyrics.Lines = append(lyrics.Lines, Line{Time: t, Text: text})
+		}
+	}
+
+	if lyrics.Synced() {
+		offset, _ := strconv.ParseInt(lyrics.Meta["offset"], 10, 64)
+		synced := lyrics.Lines[:0]
+		for _, line := range lyrics.Lines {
+			// 同步歌词中混入的无时间轴行（通常是空行）丢弃
+			if line.Time < 0 {
+				continue
+			}
+			// offset 为正表示歌词提前显示
+			line.Time -= offset
+			if line.Time < 0 {
+				line.Time = 0
+			}
+			synced = append(synced, line)
+		}
+		sort.SliceStable(synced, func(i, j int) bool {
+			return synced[i].Time < synced[j].Time
+		})
+		lyrics.Lines = synced
+		delete(lyrics.Meta, "offset")
+	}
+
+	lyrics.Lines = trimBlankLines(lyrics.Lines)
+	return lyrics
+}
+
+// parseTimeTag 解析时间标签为毫秒
+func parseTimeTag(raw string, tag []int) int64 {
+	min, _ := strconv.ParseInt(raw[tag[2]:tag[3]], 10, 64)
+	sec, _ := strconv.ParseInt(raw[tag[4]:tag[5]], 10, 64)
+	ms := (min*60 + sec) * 1000
+	if tag[6] >= 0 {
+		frac := raw[tag[6]:tag[7]]
+		n, _ := strconv.ParseInt(frac, 10, 64)
+		// 按位数换算：.5 → 500ms，.50 → 500ms，.500 → 500ms
+		for i := len(frac); i < 3; i++ {
+			n *= 10
+		}
+		ms += n
+	}
+	return ms
+}
+
+// trimBlankLines 去掉首尾空行并合并连续空行
+func trimBlankLines(lines []Line) []Line {
+	var result []Line
+	for _, line := range lines {
+		if line.Text == "" && (len(result) == 0 || result[len(result)-1].Text == "") {
+			continue
+		}
+		result = append(result, line)
+	}
+	for len(result) > 0 && result[len(result)-1].Text == "" {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+// Synced 是否为带时间轴的同步歌词
+func (l *Lyrics) Synced() bool {
+	for _, line := range l.Lines {
+		if line.Time >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty 是否没有歌词内容
+func (l *Lyrics) Empty() bool {
+	for _, line := range l.Lines {
+		if line.Text != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Text 纯文本歌词（不含时间轴）
+func (l *Lyrics) Text() string {
+	return Text(l.Lines)
+}
+
+// String 导出为标准 LRC 格式
+func (l *Lyrics) String() string {
+	var b strings.Builder
+	keys := make([]string, 0, len(l.Meta))
+	for key := range l.Meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "[%s:%s]\n", key, l.Meta[key])
+	}
+	b.WriteString(Format(l.Lines))
+	return b.String()
+}
+
+// Text 将歌词行拼接为纯文本
+func Text(lines []Line) string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// Format 将歌词行格式化为 LRC，没有时间轴的行原样输出
+func Format(lines []Line) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if line.Time >= 0 {
+			b.WriteString(FormatTime(line.Time))
+		}
+		b.WriteString(line.Text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// FormatTime 格式化时间标签，如 [01:23.45]
+func FormatTime(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	return fmt.Sprintf("[%02d:%02d.%02d]", int(d.Minutes()), int(d.Seconds())%60, ms%1000/10)
+}
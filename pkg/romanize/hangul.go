@@ -0,0 +1,27 @@
+package romanize
+
+// Revised Romanization of Korean 音节组成部分
+var (
+	hangulInitials = []string{
+		"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s",
+		"ss", "", "j", "jj", "ch", "k", "t", "p", "h",
+	}
+	hangulMedials = []string{
+		"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa",
+		"wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i",
+	}
+	hangulFinals = []string{
+		"", "k", "k", "k", "n", "n", "n", "t", "l", "k",
+		"m", "l", "l", "l", "p", "l", "m", "p", "p", "t",
+		"t", "ng", "t", "t", "k", "t", "p", "t",
+	}
+)
+
+// hangulSyllable 将一个韩文音节转换为罗马字（不处理音变）
+func hangulSyllable(r rune) string {
+	index := int(r - 0xAC00)
+	initial := index / (21 * 28)
+	medial := (index % (21 * 28)) / 28
+	final := index % 28
+	return hangulInitials[initial] + hangulMedials[medial] + hangulFinals[final]
+}
@@ -0,0 +1,100 @@
+package romanize
+
+// kanaTable 平假名到 Hepburn 罗马字
+var kanaTable = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+// isKana 是否为平假名或片假名（含长音符）
+func isKana(r rune) bool {
+	return (r >= 0x3041 && r <= 0x3096) || (r >= 0x30A1 && r <= 0x30FA) || r == 'ー'
+}
+
+// toHiragana 片假名转平假名
+func toHiragana(r rune) rune {
+	if r >= 0x30A1 && r <= 0x30F6 {
+		return r - 0x60
+	}
+	return r
+}
+
+// isSmallY 是否为拗音用的小写 ゃゅょ
+func isSmallY(r rune) bool {
+	return r == 'ゃ' || r == 'ゅ' || r == 'ょ'
+}
+
+// kanaSyllables 将一段连续假名转换为罗马字音节
+func kanaSyllables(runes []rune) []string {
+	var result []string
+	geminate := false
+
+	for i := 0; i < len(runes); i++ {
+		r := toHiragana(runes[i])
+
+		switch r {
+		case 'っ':
+			// 促音：重复下一个音节的辅音
+			geminate = true
+			continue
+		case 'ー':
+			// 长音：重复上一个音节的元音
+			if n := len(result); n > 0 {
+				last := result[n-1]
+				result[n-1] = last + last[len(last)-1:]
+			}
+			continue
+		}
+
+		syllable, ok := kanaTable[r]
+		if !ok {
+			continue
+		}
+
+		// 拗音：きゃ → kya，しゃ → sha，ちゃ → cha，じゃ → ja
+		if i+1 < len(runes) && isSmallY(toHiragana(runes[i+1])) && len(syllable) > 1 && syllable[len(syllable)-1] == 'i' {
+			small := kanaTable[toHiragana(runes[i+1])]
+			switch syllable {
+			case "shi", "chi", "ji":
+				syllable = syllable[:len(syllable)-1] + small[1:]
+			default:
+				syllable = syllable[:len(syllable)-1] + small
+			}
+			i++
+		}
+
+		if geminate {
+			if syllable[0] == 'c' {
+				syllable = "t" + syllable
+			} else if syllable[0] != 'n' && !isVowel(syllable[0]) {
+				syllable = syllable[:1] + syllable
+			}
+			geminate = false
+		}
+
+		result = append(result, syllable)
+	}
+
+	return result
+}
+
+// isVowel 是否为元音字母
+func isVowel(b byte) bool {
+	return b == 'a' || b == 'i' || b == 'u' || b == 'e' || b == 'o'
+}
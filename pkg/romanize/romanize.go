@@ -0,0 +1,78 @@
+package romanize
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// pinyinArgs 拼音转换参数（无声调，不处理多音字）
+var pinyinArgs = pinyin.NewArgs()
+
+// Keys 生成搜索键
+// full 为完整拼音 / 罗马字（不含空格，如 "daoxiang"），
+// initials 为每个音节的首字母（如 "dx"）
+//
+// 汉字转拼音，日文假名转罗马字，韩文转 Revised Romanization，
+// 拉丁字母和数字转小写保留，其余字符忽略
+func Keys(text string) (full, initials string) {
+	var fullBuf, initBuf strings.Builder
+	syllables := Syllables(text)
+	for _, s := range syllables {
+		fullBuf.WriteString(s)
+		initBuf.WriteByte(s[0])
+	}
+	return fullBuf.String(), initBuf.String()
+}
+
+// Syllables 将文本拆分为小写的罗马化音节
+// 连续的拉丁字母或数字视为一个音节
+func Syllables(text string) []string {
+	var result []string
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 && py[0] != "" {
+				result = append(result, py[0])
+			}
+		case isKana(r):
+			// 连续假名整体转换，以便处理拗音和促音
+			j := i
+			for j < len(runes) && isKana(runes[j]) {
+				j++
+			}
+			result = append(result, kanaSyllables(runes[i:j])...)
+			i = j - 1
+		case r >= 0xAC00 && r <= 0xD7A3:
+			result = append(result, hangulSyllable(r))
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			j := i
+			for j < len(runes) && runes[j] < unicode.MaxASCII && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			result = append(result, strings.ToLower(string(runes[i:j])))
+			i = j - 1
+		}
+	}
+
+	return result
+}
+
+// Normalize 规范化用户输入的罗马化关键词：转小写并去掉非字母数字字符
+// 输入包含非 ASCII 字母时返回空字符串
+func Normalize(keyword string) string {
+	var b strings.Builder
+	for _, r := range keyword {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r):
+			return ""
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package romanize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	cases := []struct {
+		text     string
+		full     string
+		initials string
+	}{
+		// 汉字转拼音
+		{"周杰伦", "zhoujielun", "zjl"},
+		{"稻香", "daoxiang", "dx"},
+		// 拉丁字母和数字整体保留，转小写
+		{"Jay Chou 稻香", "jaychoudaoxiang", "jcdx"},
+		{"ABC-123", "abc123", "a1"},
+		// 韩文
+		{"방탄소년단", "bangtansonyeondan", "btsnd"},
+		{"안녕", "annyeong", "an"},
+		// 其他字符忽略
+		{"♪ ~ ♪", "", ""},
+	}
+	for _, tc := range cases {
+		full, initials := Keys(tc.text)
+		if full != tc.full || initials != tc.initials {
+			t.Errorf("Keys(%q) = %q, %q，期望 %q, %q", tc.text, full, initials, tc.full, tc.initials)
+		}
+	}
+}
+
+func TestKanaSyllables(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"さくら", "sa ku ra"},
+		{"サクラ", "sa ku ra"},
+		// 拗音
+		{"きゃ", "kya"},
+		{"しゃしん", "sha shi n"},
+		{"ちょこ", "cho ko"},
+		{"じゃあ", "ja a"},
+		{"きゃりーぱみゅぱみゅ", "kya rii pa myu pa myu"},
+		// 促音
+		{"がっこう", "ga kko u"},
+		{"ちょっと", "cho tto"},
+		{"キッチン", "ki tchi n"},
+		// 长音
+		{"カード", "kaa do"},
+		{"サッカー", "sa kkaa"},
+	}
+	for _, tc := range cases {
+		if got := strings.Join(Syllables(tc.text), " "); got != tc.want {
+			t.Errorf("Syllables(%q) = %q，期望 %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestHangulSyllable(t *testing.T) {
+	cases := map[rune]string{
+		'가': "ga",
+		'한': "han",
+		'글': "geul",
+		'아': "a",
+		'쌍': "ssang",
+		'뷁': "bwek", // 收音 ㄺ 按代表音读作 k
+	}
+	for r, want := range cases {
+		if got := hangulSyllable(r); got != want {
+			t.Errorf("hangulSyllable(%q) = %q，期望 %q", r, got, want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"Dao Xiang": "daoxiang",
+		"ZJL!":      "zjl",
+		"k-pop 2":   "kpop2",
+		"晴天":        "",
+		"dao香":      "",
+	}
+	for input, want := range cases {
+		if got := Normalize(input); got != want {
+			t.Errorf("Normalize(%q) = %q，期望 %q", input, got, want)
+		}
+	}
+}
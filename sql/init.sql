@@ -18,7 +18,10 @@ CREATE TABLE IF NOT EXISTS users (
     first_name VARCHAR(255),
     last_name VARCHAR(255),
     language VARCHAR(10) DEFAULT 'zh',
-    is_admin BOOLEAN DEFAULT FALSE,
+    role VARCHAR(20) NOT NULL DEFAULT 'listener',
+    web_token VARCHAR(64) NOT NULL DEFAULT '',
+    member BOOLEAN NOT NULL DEFAULT FALSE,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
     is_active BOOLEAN DEFAULT TRUE,
     last_seen TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -27,9 +30,35 @@ CREATE TABLE IF NOT EXISTS users (
 
 -- 创建索引
 CREATE INDEX IF NOT EXISTS idx_users_telegram_id ON users(telegram_id);
-CREATE INDEX IF NOT EXISTS idx_users_is_admin ON users(is_admin);
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
 CREATE INDEX IF NOT EXISTS idx_users_is_active ON users(is_active);
 
+-- 添加注释（与 migration_user_roles.sql 相同）
+COMMENT ON COLUMN users.role IS '角色: owner, admin, uploader, listener, banned';
+COMMENT ON COLUMN users.web_token IS 'Web 后台登录密码的 SHA-256，由 /webtoken 生成';
+COMMENT ON COLUMN users.member IS '白名单或邀请制下是否获准使用，开放模式下新用户自动获准';
+COMMENT ON COLUMN users.invited_by IS '邀请人用户 ID';
+
+-- ============================================
+-- 邀请码表（与 migration_invites.sql 相同）
+-- ============================================
+CREATE TABLE IF NOT EXISTS invite_codes (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) UNIQUE NOT NULL,
+    created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_invite_codes_created_by ON invite_codes(created_by);
+
+COMMENT ON COLUMN invite_codes.max_uses IS '最多使用次数';
+COMMENT ON COLUMN invite_codes.expires_at IS '过期时间，为空表示不过期';
+
 -- ============================================
 -- 歌曲表
 -- ============================================
@@ -51,6 +80,8 @@ CREATE TABLE IF NOT EXISTS songs (
     year INTEGER,
     cover_url VARCHAR(512),
     lyrics TEXT,
+    genre VARCHAR(50),
+    language VARCHAR(50),
 
     -- 状态
     is_missing BOOLEAN DEFAULT FALSE,
@@ -68,6 +99,34 @@ CREATE INDEX IF NOT EXISTS idx_songs_artist ON songs(artist);
 CREATE INDEX IF NOT EXISTS idx_songs_status ON songs(status);
 CREATE INDEX IF NOT EXISTS idx_songs_is_missing ON songs(is_missing);
 CREATE INDEX IF NOT EXISTS idx_songs_created_at ON songs(created_at);
+CREATE INDEX IF NOT EXISTS idx_songs_genre ON songs(genre);
+CREATE INDEX IF NOT EXISTS idx_songs_language ON songs(language);
+
+-- ============================================
+-- 全文检索与模糊搜索（与 migration_search_index.sql 相同）
+-- ============================================
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(artist, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(album, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(lyrics, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_songs_search_vector ON songs USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_songs_title_trgm ON songs USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_artist_trgm ON songs USING GIN (artist gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_album_trgm ON songs USING GIN (album gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_year ON songs(year);
+
+-- 拼音 / 罗马字搜索键（与 migration_romanized_search.sql 相同）
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS search_romanized VARCHAR(512);
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS search_initials VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_songs_search_romanized_trgm ON songs USING GIN (search_romanized gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_search_initials_trgm ON songs USING GIN (search_initials gin_trgm_ops);
 
 -- ============================================
 -- 收藏表
@@ -101,6 +160,13 @@ CREATE INDEX IF NOT EXISTS idx_history_user_id ON history(user_id);
 CREATE INDEX IF NOT EXISTS idx_history_song_id ON history(song_id);
 CREATE INDEX IF NOT EXISTS idx_history_user_created ON history(user_id, created_at DESC);
 
+-- 个人收听统计的覆盖索引（与 migration_listening_stats.sql 相同）
+CREATE INDEX IF NOT EXISTS idx_history_user_created_song
+    ON history(user_id, created_at) INCLUDE (song_id);
+
+-- 排行榜按时间范围扫描（与 migration_charts.sql 相同）
+CREATE INDEX IF NOT EXISTS idx_history_created_at ON history(created_at);
+
 -- ============================================
 -- 触发器: 自动更新 updated_at
 -- ============================================
@@ -125,6 +191,300 @@ CREATE TRIGGER update_songs_updated_at
     FOR EACH ROW
     EXECUTE FUNCTION update_updated_at_column();
 
+-- ============================================
+-- 歌单（与 migration_playlists.sql 相同）
+-- ============================================
+CREATE TABLE IF NOT EXISTS playlists (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    share_token VARCHAR(32) DEFAULT '',
+    is_collaborative BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_playlists_user_id ON playlists(user_id);
+
+-- 分享与协作（与 migration_playlist_sharing.sql 相同）
+CREATE UNIQUE INDEX IF NOT EXISTS idx_playlists_share_token ON playlists(share_token) WHERE share_token <> '';
+
+COMMENT ON COLUMN playlists.share_token IS '分享令牌，用于 t.me/<bot>?start=pl_<token> 链接，空表示未分享';
+COMMENT ON COLUMN playlists.is_collaborative IS '是否允许协作者添加歌曲';
+
+CREATE TABLE IF NOT EXISTS playlist_collaborators (
+    id SERIAL PRIMARY KEY,
+    playlist_id INTEGER NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(playlist_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_playlist_collaborators_user_id ON playlist_collaborators(user_id);
+
+CREATE TABLE IF NOT EXISTS playlist_items (
+    id SERIAL PRIMARY KEY,
+    playlist_id INTEGER NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(playlist_id, song_id),
+    -- 位置唯一，提交时检查：交换和前移位置时中间状态允许重复（与 migration_item_positions.sql 相同）
+    CONSTRAINT idx_playlist_position UNIQUE (playlist_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+DROP TRIGGER IF EXISTS update_playlists_updated_at ON playlists;
+CREATE TRIGGER update_playlists_updated_at
+    BEFORE UPDATE ON playlists
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================
+-- 歌曲分享（与 migration_song_shares.sql、migration_song_share_tokens.sql 相同）
+-- ============================================
+CREATE TABLE IF NOT EXISTS song_shares (
+    id SERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(32),
+    open_count BIGINT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(song_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_shares_user_id ON song_shares(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_shares_token ON song_shares(token);
+
+COMMENT ON TABLE song_shares IS '歌曲分享记录，分享链接为 t.me/<bot>?start=s_<token>';
+COMMENT ON COLUMN song_shares.token IS '随机分享令牌，分享链接为 t.me/<bot>?start=s_<token>';
+COMMENT ON COLUMN song_shares.open_count IS '分享链接被打开次数';
+
+-- ============================================
+-- 播放队列（与 migration_play_queue.sql 相同）
+-- ============================================
+CREATE TABLE IF NOT EXISTS play_queues (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    current INTEGER DEFAULT 0,
+    shuffle BOOLEAN DEFAULT FALSE,
+    repeat_mode VARCHAR(10) DEFAULT 'off',
+    source VARCHAR(20) DEFAULT 'library',
+    source_ref VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS play_queue_items (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- 位置唯一，提交时检查（与 migration_item_positions.sql 相同）
+    CONSTRAINT idx_queue_position UNIQUE (user_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+COMMENT ON COLUMN play_queues.current IS '当前播放位置，0 表示尚未开始';
+COMMENT ON COLUMN play_queues.repeat_mode IS '循环模式: off, one, all';
+COMMENT ON COLUMN play_queues.source IS '自动填充来源: library, favorites, playlist, artist, album, station';
+
+DROP TRIGGER IF EXISTS update_play_queues_updated_at ON play_queues;
+CREATE TRIGGER update_play_queues_updated_at
+    BEFORE UPDATE ON play_queues
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================
+-- 播放队列已播放记录（与 migration_queue_played.sql 相同）
+-- ============================================
+CREATE TABLE IF NOT EXISTS play_queue_played (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+
+    PRIMARY KEY (user_id, song_id)
+);
+
+COMMENT ON TABLE play_queue_played IS '播放队列中已播放并被清理的歌曲，自动填充时排除';
+
+-- ============================================
+-- 歌手和专辑（与 migration_artists.sql 相同）
+-- ============================================
+CREATE TABLE IF NOT EXISTS artists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    sort_key VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_artists_sort_key ON artists(sort_key);
+
+-- 歌手别名（存小写形式）
+CREATE TABLE IF NOT EXISTS artist_aliases (
+    id SERIAL PRIMARY KEY,
+    artist_id INTEGER NOT NULL REFERENCES artists(id) ON DELETE CASCADE,
+    alias VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_artist_aliases_artist_id ON artist_aliases(artist_id);
+
+-- 专辑（同一歌手下专辑名唯一）
+CREATE TABLE IF NOT EXISTS albums (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    artist_id INTEGER NOT NULL REFERENCES artists(id) ON DELETE CASCADE,
+    year INTEGER,
+    cover_url VARCHAR(512),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_album_artist_title UNIQUE (title, artist_id)
+);
+
+-- 歌曲署名
+CREATE TABLE IF NOT EXISTS song_artists (
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    artist_id INTEGER NOT NULL REFERENCES artists(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    position INTEGER DEFAULT 0,
+    PRIMARY KEY (song_id, artist_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_artists_artist_id ON song_artists(artist_id);
+
+-- 歌曲关联专辑
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS album_id INTEGER REFERENCES albums(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_songs_album_id ON songs(album_id);
+
+COMMENT ON COLUMN artists.sort_key IS '拼音 / 罗马字排序键';
+COMMENT ON COLUMN song_artists.role IS '署名角色: main, feat';
+
+DROP TRIGGER IF EXISTS update_artists_updated_at ON artists;
+CREATE TRIGGER update_artists_updated_at
+    BEFORE UPDATE ON artists
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_albums_updated_at ON albums;
+CREATE TRIGGER update_albums_updated_at
+    BEFORE UPDATE ON albums
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================
+-- 歌词（与 migration_lyrics.sql 相同）
+-- ============================================
+CREATE TABLE IF NOT EXISTS song_lyrics (
+    id SERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL UNIQUE REFERENCES songs(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    synced BOOLEAN DEFAULT FALSE,
+    lines JSONB DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN song_lyrics.source IS '歌词来源: netease, upload, none（已查询但没有找到）';
+COMMENT ON COLUMN song_lyrics.lines IS '歌词行 [{"t": 毫秒, "text": 歌词}]，t 为 -1 表示没有时间轴';
+
+DROP TRIGGER IF EXISTS update_song_lyrics_updated_at ON song_lyrics;
+CREATE TRIGGER update_song_lyrics_updated_at
+    BEFORE UPDATE ON song_lyrics
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================
+-- 用户设置（与 migration_user_settings.sql 相同，没有记录的用户使用默认设置）
+-- ============================================
+CREATE TABLE IF NOT EXISTS user_settings (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    search_limit INTEGER NOT NULL DEFAULT 10,
+    show_lyrics BOOLEAN NOT NULL DEFAULT TRUE,
+    random_filter VARCHAR(255) NOT NULL DEFAULT '',
+    record_history BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_new_songs BOOLEAN NOT NULL DEFAULT FALSE,
+    notify_announcements BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 新歌通知按订阅用户筛选
+CREATE INDEX IF NOT EXISTS idx_user_settings_notify_new_songs ON user_settings(user_id) WHERE notify_new_songs;
+
+COMMENT ON COLUMN user_settings.search_limit IS '搜索结果每页数量: 5, 10, 20';
+COMMENT ON COLUMN user_settings.random_filter IS '/random 不带参数时的默认筛选条件';
+COMMENT ON COLUMN user_settings.record_history IS '关闭后不再记录播放历史';
+COMMENT ON COLUMN user_settings.notify_new_songs IS '收藏过的歌手有新歌入库时通知';
+COMMENT ON COLUMN user_settings.notify_announcements IS '接收管理员公告';
+
+DROP TRIGGER IF EXISTS update_user_settings_updated_at ON user_settings;
+CREATE TRIGGER update_user_settings_updated_at
+    BEFORE UPDATE ON user_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================
+-- 管理员公告（与 migration_broadcasts.sql 相同）
+-- ============================================
+CREATE TABLE IF NOT EXISTS broadcasts (
+    id SERIAL PRIMARY KEY,
+    created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    text TEXT,
+    source_chat_id BIGINT NOT NULL DEFAULT 0,
+    source_message_id INTEGER NOT NULL DEFAULT 0,
+    active_since TIMESTAMP,
+    language VARCHAR(10),
+    members_only BOOLEAN NOT NULL DEFAULT FALSE,
+    total BIGINT NOT NULL DEFAULT 0,
+    sent BIGINT NOT NULL DEFAULT 0,
+    failed BIGINT NOT NULL DEFAULT 0,
+    blocked BIGINT NOT NULL DEFAULT 0,
+    last_user_id INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcasts_created_by ON broadcasts(created_by);
+CREATE INDEX IF NOT EXISTS idx_broadcasts_status ON broadcasts(status);
+
+COMMENT ON COLUMN broadcasts.status IS '状态：draft 等待确认、sending 发送中、done 已完成、cancelled 已取消';
+COMMENT ON COLUMN broadcasts.source_message_id IS '复制发送的原消息 ID，为 0 时发送 text';
+COMMENT ON COLUMN broadcasts.active_since IS '只发给此后活跃过的用户，为空表示所有用户';
+COMMENT ON COLUMN broadcasts.last_user_id IS '最后处理的用户 ID，重启后从此处继续发送';
+COMMENT ON COLUMN broadcasts.blocked IS '已屏蔽 Bot 的用户数，这些用户的 is_active 会被置为 false';
+
+-- ============================================
+-- 群组设置（与 migration_group_settings.sql 相同）
+-- ============================================
+CREATE TABLE IF NOT EXISTS group_settings (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT UNIQUE NOT NULL,
+    title VARCHAR(255),
+    download_policy VARCHAR(20) DEFAULT 'admins',
+    cleanup_after INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN group_settings.download_policy IS '下载权限: all, admins, off';
+COMMENT ON COLUMN group_settings.cleanup_after IS 'Bot 消息自动清理时间（秒），0 表示不清理';
+
+DROP TRIGGER IF EXISTS update_group_settings_updated_at ON group_settings;
+CREATE TRIGGER update_group_settings_updated_at
+    BEFORE UPDATE ON group_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
 -- ============================================
 -- 视图: 统计信息
 -- ============================================
@@ -153,12 +513,50 @@ GROUP BY s.id, s.title, s.artist, s.album
 ORDER BY play_count DESC
 LIMIT 100;
 
+-- ============================================
+-- 物化视图: 全站排行榜（与 migration_charts.sql 相同）
+-- ============================================
+-- 由 Bot 按 charts.refresh_interval 定时刷新
+--   plays_day    最近 24 小时播放次数
+--   plays_week   最近 7 天播放次数
+--   plays_prev   前一周（2~8 天前）播放次数，作为飙升榜的基线
+--   trending_score 最近 24 小时相对基线日均值的增幅，基线越小越容易上榜但会被开方抑制
+CREATE MATERIALIZED VIEW IF NOT EXISTS song_charts AS
+SELECT
+    plays.song_id,
+    plays.plays_total,
+    plays.plays_day,
+    plays.plays_week,
+    plays.plays_prev,
+    (plays.plays_day - plays.plays_prev / 7.0) / SQRT(plays.plays_prev / 7.0 + 1) AS trending_score,
+    NOW() AS refreshed_at
+FROM (
+    SELECT
+        song_id,
+        COUNT(*) AS plays_total,
+        COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '1 day') AS plays_day,
+        COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days') AS plays_week,
+        COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '8 days'
+                           AND created_at < NOW() - INTERVAL '1 day') AS plays_prev
+    FROM history
+    GROUP BY song_id
+) AS plays;
+
+-- REFRESH MATERIALIZED VIEW CONCURRENTLY 需要唯一索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_charts_song_id ON song_charts(song_id);
+CREATE INDEX IF NOT EXISTS idx_song_charts_day ON song_charts(plays_day DESC);
+CREATE INDEX IF NOT EXISTS idx_song_charts_week ON song_charts(plays_week DESC);
+CREATE INDEX IF NOT EXISTS idx_song_charts_total ON song_charts(plays_total DESC);
+CREATE INDEX IF NOT EXISTS idx_song_charts_trending ON song_charts(trending_score DESC);
+
+COMMENT ON MATERIALIZED VIEW song_charts IS '全站排行榜，执行 REFRESH MATERIALIZED VIEW CONCURRENTLY song_charts 刷新';
+
 -- ============================================
 -- 插入默认管理员（可选）
 -- ============================================
--- 注意: 需要手动设置正确的 telegram_id
--- INSERT INTO users (telegram_id, username, is_admin, is_active)
--- VALUES (123456789, 'admin', TRUE, TRUE)
+-- 注意: 需要手动设置正确的 telegram_id；bot.admin_id 对应的用户会在使用 Bot 时自动设为 owner
+-- INSERT INTO users (telegram_id, username, role, is_active)
+-- VALUES (123456789, 'admin', 'admin', TRUE)
 -- ON CONFLICT (telegram_id) DO NOTHING;
 
 -- ============================================
@@ -172,3 +570,6 @@ BEGIN
     WHERE created_at < CURRENT_TIMESTAMP - INTERVAL '90 days';
 END;
 $$ LANGUAGE plpgsql;
+
+-- 年度回顾需要完整的历史记录，不建议定期执行
+COMMENT ON FUNCTION clean_old_history() IS '清理 90 天前的历史记录（会影响个人统计和年度回顾）';
@@ -0,0 +1,82 @@
+-- Fish Music Database Migration
+-- 歌手和专辑规范化
+-- 版本: v1.9
+-- 创建日期: 2026-10-19
+-- 执行后运行 go run ./cmd/backfill -task artists 为现有歌曲生成署名
+
+-- ============================================
+-- 歌手表
+-- ============================================
+CREATE TABLE IF NOT EXISTS artists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    sort_key VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_artists_sort_key ON artists(sort_key);
+
+-- ============================================
+-- 歌手别名表（存小写形式）
+-- ============================================
+CREATE TABLE IF NOT EXISTS artist_aliases (
+    id SERIAL PRIMARY KEY,
+    artist_id INTEGER NOT NULL REFERENCES artists(id) ON DELETE CASCADE,
+    alias VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_artist_aliases_artist_id ON artist_aliases(artist_id);
+
+-- ============================================
+-- 专辑表（同一歌手下专辑名唯一）
+-- ============================================
+CREATE TABLE IF NOT EXISTS albums (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    artist_id INTEGER NOT NULL REFERENCES artists(id) ON DELETE CASCADE,
+    year INTEGER,
+    cover_url VARCHAR(512),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_album_artist_title UNIQUE (title, artist_id)
+);
+
+-- ============================================
+-- 歌曲署名表
+-- ============================================
+CREATE TABLE IF NOT EXISTS song_artists (
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    artist_id INTEGER NOT NULL REFERENCES artists(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    position INTEGER DEFAULT 0,
+    PRIMARY KEY (song_id, artist_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_artists_artist_id ON song_artists(artist_id);
+
+-- ============================================
+-- 歌曲关联专辑
+-- ============================================
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS album_id INTEGER REFERENCES albums(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_songs_album_id ON songs(album_id);
+
+-- 歌手 / 专辑队列的来源改为 ID，旧队列回退到全库随机
+UPDATE play_queues SET source = 'library', source_ref = '' WHERE source IN ('artist', 'album');
+
+-- 添加注释
+COMMENT ON COLUMN artists.sort_key IS '拼音 / 罗马字排序键';
+COMMENT ON COLUMN song_artists.role IS '署名角色: main, feat';
+
+DROP TRIGGER IF EXISTS update_artists_updated_at ON artists;
+CREATE TRIGGER update_artists_updated_at
+    BEFORE UPDATE ON artists
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_albums_updated_at ON albums;
+CREATE TRIGGER update_albums_updated_at
+    BEFORE UPDATE ON albums
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
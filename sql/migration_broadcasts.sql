@@ -0,0 +1,39 @@
+-- Fish Music Database Migration
+-- 管理员公告
+-- 版本: v2.6
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 公告表
+-- ============================================
+CREATE TABLE IF NOT EXISTS broadcasts (
+    id SERIAL PRIMARY KEY,
+    created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    text TEXT,
+    source_chat_id BIGINT NOT NULL DEFAULT 0,
+    source_message_id INTEGER NOT NULL DEFAULT 0,
+    active_since TIMESTAMP,
+    language VARCHAR(10),
+    members_only BOOLEAN NOT NULL DEFAULT FALSE,
+    total BIGINT NOT NULL DEFAULT 0,
+    sent BIGINT NOT NULL DEFAULT 0,
+    failed BIGINT NOT NULL DEFAULT 0,
+    blocked BIGINT NOT NULL DEFAULT 0,
+    last_user_id INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcasts_created_by ON broadcasts(created_by);
+CREATE INDEX IF NOT EXISTS idx_broadcasts_status ON broadcasts(status);
+
+-- 添加注释
+COMMENT ON COLUMN broadcasts.status IS '状态：draft 等待确认、sending 发送中、done 已完成、cancelled 已取消';
+COMMENT ON COLUMN broadcasts.source_message_id IS '复制发送的原消息 ID，为 0 时发送 text';
+COMMENT ON COLUMN broadcasts.active_since IS '只发给此后活跃过的用户，为空表示所有用户';
+COMMENT ON COLUMN broadcasts.last_user_id IS '最后处理的用户 ID，重启后从此处继续发送';
+COMMENT ON COLUMN broadcasts.blocked IS '已屏蔽 Bot 的用户数，这些用户的 is_active 会被置为 false';
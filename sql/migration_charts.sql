@@ -0,0 +1,42 @@
+-- Fish Music Database Migration
+-- 全站排行榜：热播榜、飙升榜
+-- 版本: v2.2
+-- 创建日期: 2026-10-19
+
+-- 排行榜物化视图：按歌曲预先聚合播放次数，由 Bot 按 charts.refresh_interval 定时刷新
+--   plays_day    最近 24 小时播放次数
+--   plays_week   最近 7 天播放次数
+--   plays_prev   前一周（2~8 天前）播放次数，作为飙升榜的基线
+--   trending_score 最近 24 小时相对基线日均值的增幅，基线越小越容易上榜但会被开方抑制
+CREATE MATERIALIZED VIEW IF NOT EXISTS song_charts AS
+SELECT
+    plays.song_id,
+    plays.plays_total,
+    plays.plays_day,
+    plays.plays_week,
+    plays.plays_prev,
+    (plays.plays_day - plays.plays_prev / 7.0) / SQRT(plays.plays_prev / 7.0 + 1) AS trending_score,
+    NOW() AS refreshed_at
+FROM (
+    SELECT
+        song_id,
+        COUNT(*) AS plays_total,
+        COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '1 day') AS plays_day,
+        COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days') AS plays_week,
+        COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '8 days'
+                           AND created_at < NOW() - INTERVAL '1 day') AS plays_prev
+    FROM history
+    GROUP BY song_id
+) AS plays;
+
+-- REFRESH MATERIALIZED VIEW CONCURRENTLY 需要唯一索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_charts_song_id ON song_charts(song_id);
+CREATE INDEX IF NOT EXISTS idx_song_charts_day ON song_charts(plays_day DESC);
+CREATE INDEX IF NOT EXISTS idx_song_charts_week ON song_charts(plays_week DESC);
+CREATE INDEX IF NOT EXISTS idx_song_charts_total ON song_charts(plays_total DESC);
+CREATE INDEX IF NOT EXISTS idx_song_charts_trending ON song_charts(trending_score DESC);
+
+-- 最近 24 小时 / 7 天的聚合按时间范围扫描 history
+CREATE INDEX IF NOT EXISTS idx_history_created_at ON history(created_at);
+
+COMMENT ON MATERIALIZED VIEW song_charts IS '全站排行榜，执行 REFRESH MATERIALIZED VIEW CONCURRENTLY song_charts 刷新';
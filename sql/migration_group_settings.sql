@@ -0,0 +1,27 @@
+-- Fish Music Database Migration
+-- 群组设置
+-- 版本: v1.4
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 群组设置表
+-- ============================================
+CREATE TABLE IF NOT EXISTS group_settings (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT UNIQUE NOT NULL,
+    title VARCHAR(255),
+    download_policy VARCHAR(20) DEFAULT 'admins',
+    cleanup_after INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 添加注释
+COMMENT ON COLUMN group_settings.download_policy IS '下载权限: all, admins, off';
+COMMENT ON COLUMN group_settings.cleanup_after IS 'Bot 消息自动清理时间（秒），0 表示不清理';
+
+DROP TRIGGER IF EXISTS update_group_settings_updated_at ON group_settings;
+CREATE TRIGGER update_group_settings_updated_at
+    BEFORE UPDATE ON group_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,45 @@
+-- Fish Music Database Migration
+-- 白名单和邀请码
+-- 版本: v2.5
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 用户是否获准使用（bot.access 为 whitelist 或 invite 时生效）
+-- ============================================
+-- 现有用户视为已获准，切换访问模式后不受影响
+-- 只在第一次添加列时设置，重复执行不会把之后被 /disallow 的用户重新放行
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'users' AND column_name = 'member'
+    ) THEN
+        ALTER TABLE users ADD COLUMN member BOOLEAN NOT NULL DEFAULT TRUE;
+        ALTER TABLE users ALTER COLUMN member SET DEFAULT FALSE;
+    END IF;
+END $$;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+-- ============================================
+-- 邀请码表
+-- ============================================
+CREATE TABLE IF NOT EXISTS invite_codes (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) UNIQUE NOT NULL,
+    created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_invite_codes_created_by ON invite_codes(created_by);
+
+-- 添加注释
+COMMENT ON COLUMN users.member IS '白名单或邀请制下是否获准使用，开放模式下新用户自动获准';
+COMMENT ON COLUMN users.invited_by IS '邀请人用户 ID';
+COMMENT ON COLUMN invite_codes.max_uses IS '最多使用次数';
+COMMENT ON COLUMN invite_codes.expires_at IS '过期时间，为空表示不过期';
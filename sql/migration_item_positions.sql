@@ -0,0 +1,46 @@
+-- Fish Music Database Migration
+-- 歌单和播放队列条目位置唯一
+-- 版本: v2.9
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 之前多人同时添加歌曲时可能产生重复位置，先按原顺序重新编号
+-- 只处理存在重复位置的歌单和队列
+-- ============================================
+UPDATE playlist_items p SET position = r.rn
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY playlist_id ORDER BY position, id) AS rn
+    FROM playlist_items
+    WHERE playlist_id IN (
+        SELECT playlist_id FROM playlist_items GROUP BY playlist_id, position HAVING COUNT(*) > 1
+    )
+) r
+WHERE p.id = r.id AND p.position <> r.rn;
+
+UPDATE play_queue_items q SET position = r.rn
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY position, id) AS rn
+    FROM play_queue_items
+    WHERE user_id IN (
+        SELECT user_id FROM play_queue_items GROUP BY user_id, position HAVING COUNT(*) > 1
+    )
+) r
+WHERE q.id = r.id AND q.position <> r.rn;
+
+-- ============================================
+-- 位置索引改为唯一约束，提交时检查：交换和前移位置时中间状态允许重复
+-- ============================================
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'idx_playlist_position') THEN
+        DROP INDEX IF EXISTS idx_playlist_position;
+        ALTER TABLE playlist_items ADD CONSTRAINT idx_playlist_position
+            UNIQUE (playlist_id, position) DEFERRABLE INITIALLY DEFERRED;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'idx_queue_position') THEN
+        DROP INDEX IF EXISTS idx_queue_position;
+        ALTER TABLE play_queue_items ADD CONSTRAINT idx_queue_position
+            UNIQUE (user_id, position) DEFERRABLE INITIALLY DEFERRED;
+    END IF;
+END $$;
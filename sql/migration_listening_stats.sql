@@ -0,0 +1,15 @@
+-- Fish Music Database Migration
+-- 个人收听统计与年度回顾
+-- 版本: v2.1
+-- 创建日期: 2026-10-19
+
+-- 覆盖索引：按用户和时间范围聚合到歌曲时只需扫描索引，不必回表
+-- 历史记录很多时建议在低峰期执行，或改用 CREATE INDEX CONCURRENTLY
+CREATE INDEX IF NOT EXISTS idx_history_user_created_song
+    ON history(user_id, created_at) INCLUDE (song_id);
+
+-- 年度回顾需要完整的历史记录，不再建议定期执行 clean_old_history()
+COMMENT ON FUNCTION clean_old_history() IS '清理 90 天前的历史记录（会影响个人统计和年度回顾）';
+
+-- 更新统计信息，让查询规划器使用新索引
+ANALYZE history;
@@ -0,0 +1,27 @@
+-- Fish Music Database Migration
+-- 歌词
+-- 版本: v2.0
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 歌词表（每首歌一条，按行存储带时间轴的歌词）
+-- ============================================
+CREATE TABLE IF NOT EXISTS song_lyrics (
+    id SERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL UNIQUE REFERENCES songs(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    synced BOOLEAN DEFAULT FALSE,
+    lines JSONB DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 添加注释
+COMMENT ON COLUMN song_lyrics.source IS '歌词来源: netease, upload, none（已查询但没有找到）';
+COMMENT ON COLUMN song_lyrics.lines IS '歌词行 [{"t": 毫秒, "text": 歌词}]，t 为 -1 表示没有时间轴';
+
+DROP TRIGGER IF EXISTS update_song_lyrics_updated_at ON song_lyrics;
+CREATE TRIGGER update_song_lyrics_updated_at
+    BEFORE UPDATE ON song_lyrics
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
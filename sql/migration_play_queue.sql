@@ -0,0 +1,44 @@
+-- Fish Music Database Migration
+-- 播放队列
+-- 版本: v1.8
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 播放队列表（每个用户一个）
+-- ============================================
+CREATE TABLE IF NOT EXISTS play_queues (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    current INTEGER DEFAULT 0,
+    shuffle BOOLEAN DEFAULT FALSE,
+    repeat_mode VARCHAR(10) DEFAULT 'off',
+    source VARCHAR(20) DEFAULT 'library',
+    source_ref VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================
+-- 播放队列条目表
+-- ============================================
+CREATE TABLE IF NOT EXISTS play_queue_items (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_queue_position ON play_queue_items(user_id, position);
+
+-- 添加注释
+COMMENT ON COLUMN play_queues.current IS '当前播放位置，0 表示尚未开始';
+COMMENT ON COLUMN play_queues.repeat_mode IS '循环模式: off, one, all';
+COMMENT ON COLUMN play_queues.source IS '自动填充来源: library, favorites, playlist, artist';
+
+DROP TRIGGER IF EXISTS update_play_queues_updated_at ON play_queues;
+CREATE TRIGGER update_play_queues_updated_at
+    BEFORE UPDATE ON play_queues
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,30 @@
+-- Fish Music Database Migration
+-- 歌单分享与协作
+-- 版本: v1.6
+-- 创建日期: 2026-10-19
+
+-- 添加分享字段到 playlists 表
+ALTER TABLE playlists ADD COLUMN IF NOT EXISTS share_token VARCHAR(32) DEFAULT '';
+ALTER TABLE playlists ADD COLUMN IF NOT EXISTS is_collaborative BOOLEAN DEFAULT FALSE;
+
+-- 分享令牌唯一（空字符串表示未分享，不参与唯一约束）
+CREATE UNIQUE INDEX IF NOT EXISTS idx_playlists_share_token ON playlists(share_token) WHERE share_token <> '';
+
+-- 添加注释
+COMMENT ON COLUMN playlists.share_token IS '分享令牌，用于 t.me/<bot>?start=pl_<token> 链接，空表示未分享';
+COMMENT ON COLUMN playlists.is_collaborative IS '是否允许协作者添加歌曲';
+
+-- ============================================
+-- 歌单协作者表
+-- ============================================
+CREATE TABLE IF NOT EXISTS playlist_collaborators (
+    id SERIAL PRIMARY KEY,
+    playlist_id INTEGER NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(playlist_id, user_id)
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_playlist_collaborators_user_id ON playlist_collaborators(user_id);
@@ -0,0 +1,19 @@
+-- Fish Music Database Migration
+-- 播放队列已播放记录
+-- 版本: v2.8
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 已从队列中清理的已播放歌曲
+-- 队列只保留最近的播放条目，自动填充时同时排除这里的歌曲，
+-- 电台等来源中的歌曲全部播完前不会重复；切换来源或清空队列时重置
+-- ============================================
+CREATE TABLE IF NOT EXISTS play_queue_played (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+
+    PRIMARY KEY (user_id, song_id)
+);
+
+-- 添加注释
+COMMENT ON TABLE play_queue_played IS '播放队列中已播放并被清理的歌曲，自动填充时排除';
@@ -0,0 +1,19 @@
+-- Fish Music Database Migration
+-- 拼音 / 罗马字搜索键
+-- 版本: v1.3
+-- 创建日期: 2026-10-19
+
+-- 添加搜索键字段到 songs 表
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS search_romanized VARCHAR(512);
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS search_initials VARCHAR(255);
+
+-- 创建索引（依赖 migration_search_index.sql 启用的 pg_trgm）
+CREATE INDEX IF NOT EXISTS idx_songs_search_romanized_trgm ON songs USING GIN (search_romanized gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_search_initials_trgm ON songs USING GIN (search_initials gin_trgm_ops);
+
+-- 添加注释
+COMMENT ON COLUMN songs.search_romanized IS '完整拼音 / 罗马字搜索键，如 daoxiang zhoujielun';
+COMMENT ON COLUMN songs.search_initials IS '拼音首字母搜索键，如 dx zjl';
+
+-- 现有数据需要运行回填命令生成搜索键：
+--   go run ./cmd/backfill -task search_keys
@@ -0,0 +1,27 @@
+-- Fish Music Database Migration
+-- 全文检索与模糊搜索索引
+-- 版本: v1.2
+-- 创建日期: 2026-10-19
+
+-- 启用 trigram 扩展（模糊匹配、错别字容忍）
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- 全文检索向量：标题/歌手权重最高，其次专辑，最后歌词
+-- 使用 simple 配置，避免对中日韩文本做英文词干处理
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(artist, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(album, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(lyrics, '')), 'C')
+    ) STORED;
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_songs_search_vector ON songs USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_songs_title_trgm ON songs USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_artist_trgm ON songs USING GIN (artist gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_album_trgm ON songs USING GIN (album gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_year ON songs(year);
+
+-- 添加注释
+COMMENT ON COLUMN songs.search_vector IS '全文检索向量（标题、歌手、专辑、歌词），自动生成';
@@ -0,0 +1,19 @@
+-- Fish Music Database Migration
+-- 歌曲分享链接改用随机令牌
+-- 版本: v2.7
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 分享令牌：链接从 s_<自增ID> 改为 s_<随机令牌>，避免遍历链接获取他人的分享
+-- ============================================
+ALTER TABLE song_shares ADD COLUMN IF NOT EXISTS token VARCHAR(32);
+
+-- 为现有记录生成令牌（只处理还没有令牌的记录，可重复执行）
+UPDATE song_shares SET token = substr(md5(random()::text || id::text || clock_timestamp()::text), 1, 12)
+WHERE token IS NULL OR token = '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_shares_token ON song_shares(token);
+
+-- 添加注释
+COMMENT ON COLUMN song_shares.token IS '随机分享令牌，分享链接为 t.me/<bot>?start=s_<token>';
+COMMENT ON TABLE song_shares IS '歌曲分享记录，分享链接为 t.me/<bot>?start=s_<token>';
@@ -0,0 +1,25 @@
+-- Fish Music Database Migration
+-- 歌曲分享链接
+-- 版本: v1.7
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 歌曲分享表
+-- ============================================
+CREATE TABLE IF NOT EXISTS song_shares (
+    id SERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    open_count BIGINT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(song_id, user_id)
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_song_shares_user_id ON song_shares(user_id);
+
+-- 添加注释
+COMMENT ON TABLE song_shares IS '歌曲分享记录，分享链接为 t.me/<bot>?start=s_<id>';
+COMMENT ON COLUMN song_shares.open_count IS '分享链接被打开次数';
@@ -0,0 +1,32 @@
+-- Fish Music Database Migration
+-- 用户角色
+-- 版本: v2.4
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 用户角色：owner, admin, uploader, listener, banned
+-- ============================================
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'listener';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS web_token VARCHAR(64) NOT NULL DEFAULT '';
+
+-- 现有用户保留原有权限：管理员为 admin，其他用户可以继续发送链接下载
+-- 所有者（bot.admin_id）在下次使用 Bot 时自动设置
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'users' AND column_name = 'is_admin'
+    ) THEN
+        UPDATE users SET role = CASE WHEN is_admin THEN 'admin' ELSE 'uploader' END
+        WHERE role = 'listener';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_users_is_admin;
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+
+-- 添加注释
+COMMENT ON COLUMN users.role IS '角色: owner, admin, uploader, listener, banned';
+COMMENT ON COLUMN users.web_token IS 'Web 后台登录密码的 SHA-256，由 /webtoken 生成';
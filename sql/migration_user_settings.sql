@@ -0,0 +1,36 @@
+-- Fish Music Database Migration
+-- 用户偏好设置
+-- 版本: v2.3
+-- 创建日期: 2026-10-19
+
+-- ============================================
+-- 用户设置表（没有记录的用户使用默认设置）
+-- ============================================
+CREATE TABLE IF NOT EXISTS user_settings (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    search_limit INTEGER NOT NULL DEFAULT 10,
+    show_lyrics BOOLEAN NOT NULL DEFAULT TRUE,
+    random_filter VARCHAR(255) NOT NULL DEFAULT '',
+    record_history BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_new_songs BOOLEAN NOT NULL DEFAULT FALSE,
+    notify_announcements BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 新歌通知按订阅用户筛选
+CREATE INDEX IF NOT EXISTS idx_user_settings_notify_new_songs ON user_settings(user_id) WHERE notify_new_songs;
+
+-- 添加注释
+COMMENT ON COLUMN user_settings.search_limit IS '搜索结果每页数量: 5, 10, 20';
+COMMENT ON COLUMN user_settings.random_filter IS '/random 不带参数时的默认筛选条件';
+COMMENT ON COLUMN user_settings.record_history IS '关闭后不再记录播放历史';
+COMMENT ON COLUMN user_settings.notify_new_songs IS '收藏过的歌手有新歌入库时通知';
+COMMENT ON COLUMN user_settings.notify_announcements IS '接收管理员公告';
+
+DROP TRIGGER IF EXISTS update_user_settings_updated_at ON user_settings;
+CREATE TRIGGER update_user_settings_updated_at
+    BEFORE UPDATE ON user_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();